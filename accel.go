@@ -0,0 +1,117 @@
+package servo
+
+import "math"
+
+// SetAcceleration limits how fast the servo speeds up and slows down, in
+// degrees per second squared, so moves ramp up and down in a trapezoid
+// instead of starting and stopping at full speed, e.g. for heavy pan-tilt
+// heads. A limit of 0 disables it (default), and negative limits are set to 0.
+//
+// A move replaced while the servo is moving keeps the velocity of the servo,
+// slowing it down first if the new target is behind it. Servo.Stop() still
+// stops the servo instantly, use Servo.SoftStop() to slow it down.
+func (s *Servo) SetAcceleration(accel, decel float64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.accel = math.Max(accel, 0)
+	s.decel = math.Max(decel, 0)
+}
+
+// Acceleration returns the acceleration and deceleration limits of the servo
+// in degrees per second squared, 0 if disabled.
+func (s *Servo) Acceleration() (accel, decel float64) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.accel, s.decel
+}
+
+// next returns the raw position and the velocity of the servo dt seconds
+// after its last update. It must be called with the servo locked.
+func (s *Servo) next(dt float64) (p, v float64) {
+	if m := s.span; s.planned(m) {
+		return s.follow(m, dt)
+	}
+	if s.accel == 0 && s.decel == 0 {
+		return linear(s.position, s.target, s.speed(), dt)
+	}
+	return accelerate(s.position, s.velocity, s.target, s.speed(), s.accel, s.decel, dt)
+}
+
+// linear moves from p toward target at speed for dt seconds. It returns the
+// new position and velocity.
+func linear(p, target, speed, dt float64) (float64, float64) {
+	delta := speed * dt
+	switch {
+	case target < p-delta:
+		return p - delta, -speed
+	case target > p+delta:
+		return p + delta, speed
+	}
+	return target, 0
+}
+
+// accelerate moves from p at velocity v toward target for dt seconds, at up to
+// speed, speeding up by at most accel and slowing down by at most decel (0 for
+// no limit). The velocity is lowered near the target so the move stops on it.
+// It returns the new position and velocity.
+func accelerate(p, v, target, speed, accel, decel, dt float64) (float64, float64) {
+	d := target - p
+	dir := 1.0
+	if d < 0 {
+		dir = -1
+	}
+
+	// The fastest velocity that can still stop on the target, after moving
+	// at it until the next update.
+	max := speed
+	if decel > 0 {
+		step := decel * dt
+		max = math.Min(max, -step+math.Sqrt(step*step+2*decel*math.Abs(d)))
+	}
+	want := dir * max
+
+	nv := want
+	if v*want >= 0 && math.Abs(want) > math.Abs(v) {
+		if accel > 0 {
+			nv = v + clamp(want-v, -accel*dt, accel*dt)
+		}
+	} else if decel > 0 {
+		nv = v + clamp(want-v, -decel*dt, decel*dt)
+	}
+
+	np := p + (v+nv)/2*dt
+	if (target-np)*dir <= 0 && (decel == 0 || math.Abs(v) <= decel*dt) {
+		// Arrived slow enough to stop on the target.
+		return target, 0
+	}
+	return np, nv
+}
+
+// travelTime returns the seconds a move of distance degrees takes from rest to
+// rest at speed, with the accel and decel limits (0 for no limit).
+func travelTime(distance, speed, accel, decel float64) float64 {
+	ramp := func(limit float64) (t, d float64) {
+		if limit == 0 {
+			return 0, 0
+		}
+		t = speed / limit
+		return t, speed * t / 2
+	}
+	ta, da := ramp(accel)
+	td, dd := ramp(decel)
+	if da+dd <= distance {
+		return ta + td + (distance-da-dd)/speed
+	}
+
+	// The servo never reaches its speed.
+	inverse := func(limit float64) float64 {
+		if limit == 0 {
+			return 0
+		}
+		return 1 / limit
+	}
+	peak := math.Sqrt(2 * distance / (inverse(accel) + inverse(decel)))
+	return peak*inverse(accel) + peak*inverse(decel)
+}
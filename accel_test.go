@@ -0,0 +1,136 @@
+// +build !live
+
+package servo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// simulate runs accelerate from rest at p until it arrives at target, with
+// updates every dt seconds. It returns the time it took and the largest change
+// of velocity between updates.
+func simulate(t *testing.T, p, target, speed, accel, decel, dt float64) (elapsed, maxDelta float64) {
+	v := 0.0
+	for i := 0; i < 100000; i++ {
+		np, nv := accelerate(p, v, target, speed, accel, decel, dt)
+		if math.Abs(nv) > speed+1e-9 {
+			t.Fatalf("speed exceeded, got: %.2f, max: %.2f", nv, speed)
+		}
+		maxDelta = math.Max(maxDelta, math.Abs(nv-v))
+		p, v = np, nv
+		elapsed += dt
+		if p == target && v == 0 {
+			return elapsed, maxDelta
+		}
+	}
+	t.Fatal("never arrived")
+	return 0, 0
+}
+
+func TestAccelerate(t *testing.T) {
+	tests := []struct {
+		name                string
+		distance            float64
+		speed, accel, decel float64
+	}{
+		{"trapezoid", 180, 300, 1000, 1000},
+		{"triangle", 20, 300, 1000, 1000},
+		{"accel only", 90, 300, 500, 0},
+		{"decel only", 90, 300, 0, 500},
+		{"asymmetric", 120, 200, 2000, 400},
+	}
+	const dt = 0.003
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elapsed, maxDelta := simulate(t, 0, tt.distance, tt.speed, tt.accel, tt.decel, dt)
+
+			limit := math.Max(tt.accel, tt.decel) * dt
+			if tt.accel == 0 || tt.decel == 0 {
+				// One of the ramps is instant.
+				limit = tt.speed
+			}
+			if maxDelta > limit+1e-9 {
+				t.Errorf("velocity changed by %.2f in one update, max: %.2f", maxDelta, limit)
+			}
+
+			want := travelTime(tt.distance, tt.speed, tt.accel, tt.decel)
+			if math.Abs(elapsed-want) > 0.02 {
+				t.Errorf("wrong travel time, got: %.3fs, want: %.3fs", elapsed, want)
+			}
+		})
+	}
+}
+
+func TestAccelerate_reverse(t *testing.T) {
+	// Moving at full speed away from the target, the servo slows down first.
+	p, v := 90.0, 300.0
+	const dt = 0.003
+	passed := false
+	for i := 0; i < 10000 && !(p == 0 && v == 0); i++ {
+		np, nv := accelerate(p, v, 0, 300, 1000, 1000, dt)
+		if d := math.Abs(nv - v); d > 1000*dt+1e-9 {
+			t.Fatalf("velocity changed by %.2f in one update", d)
+		}
+		if np > 90 {
+			passed = true
+		}
+		p, v = np, nv
+	}
+	if !passed {
+		t.Error("servo reversed instantly")
+	}
+	if p != 0 || v != 0 {
+		t.Errorf("servo did not arrive, got: %.2f at %.2f°/s", p, v)
+	}
+}
+
+func TestTravelTime(t *testing.T) {
+	tests := []struct {
+		distance, speed, accel, decel float64
+		want                          float64
+	}{
+		{180, 180, 0, 0, 1},
+		// 0.5s to speed up and down over 45 degrees each.
+		{180, 180, 360, 360, 1.5},
+		// Never reaches the speed: peak of 60°/s after 1s.
+		{60, 180, 60, 60, 2},
+		{90, 180, 360, 0, 0.75},
+	}
+	for _, tt := range tests {
+		if got := travelTime(tt.distance, tt.speed, tt.accel, tt.decel); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("travelTime(%v, %v, %v, %v), got: %.3f, want: %.3f", tt.distance, tt.speed, tt.accel, tt.decel, got, tt.want)
+		}
+	}
+}
+
+func TestServo_SetAcceleration(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetMaxSpeed(180)
+	s.SetAcceleration(360, 360)
+
+	start := time.Now()
+	s.MoveTo(180).Wait()
+	elapsed := time.Since(start)
+	if want := 1500 * time.Millisecond; elapsed < want-50*time.Millisecond || elapsed > want+60*time.Millisecond {
+		t.Errorf("wrong travel time, got: %v, want: %v", elapsed, want)
+	}
+	if s.Position() != 180 {
+		t.Errorf("servo did not arrive, got: %.2f", s.Position())
+	}
+	if d := s.travel(0); d != 1500*time.Millisecond {
+		t.Errorf("wrong estimate, got: %v, want: %v", d, 1500*time.Millisecond)
+	}
+
+	s.SetAcceleration(-1, 10)
+	if accel, decel := s.Acceleration(); accel != 0 || decel != 10 {
+		t.Errorf("wrong limits, got: %.2f, %.2f, want: 0.00, 10.00", accel, decel)
+	}
+}
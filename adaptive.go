@@ -0,0 +1,64 @@
+package servo
+
+import "time"
+
+// adaptiveRate holds the bounds of the flush rate set by AdaptiveRate(). The
+// zero value is a fixed rate.
+type adaptiveRate struct {
+	fast, slow time.Duration
+}
+
+// AdaptiveRate makes the manager choose the flush rate by the motion of the
+// servos: it raises the rate toward fast while a servo is moving at its max
+// speed, and lowers it toward slow when all the servos are idle. Fast moves
+// stay smooth while idle servos cost little CPU and pipe writes. The rate is
+// never faster than the update rate of the servos. The bounds are swapped if
+// fast is slower than slow. Call servo.Rate() to go back to a fixed rate.
+func AdaptiveRate(fast, slow time.Duration) {
+	if fast > slow {
+		fast, slow = slow, fast
+	}
+	_blaster.run()
+	select {
+	case _blaster.adaptive <- adaptiveRate{fast, slow}:
+	case <-_blaster.done:
+	}
+}
+
+// enabled checks if the flush rate is adaptive.
+func (a adaptiveRate) enabled() bool {
+	return a.slow > 0
+}
+
+// rate returns the flush rate for the activity, from 0 (all idle) to 1 (a
+// servo moving at its max speed), no faster than the update interval. It is
+// rounded to the millisecond, so small changes of the activity keep the same
+// rate.
+func (a adaptiveRate) rate(activity float64, interval time.Duration) time.Duration {
+	fast := a.fast
+	if fast < interval {
+		fast = interval
+	}
+	slow := a.slow
+	if slow < fast {
+		slow = fast
+	}
+
+	r := slow - time.Duration(clamp(activity, 0, 1)*float64(slow-fast))
+	if r = r.Round(time.Millisecond); r < fast {
+		r = fast
+	}
+	return r
+}
+
+// activity returns the speed of the servo as a fraction of its max speed, or 0
+// if it is idle.
+func (s *Servo) activity() float64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.idle || s.maxStep == 0 {
+		return 0
+	}
+	return s.speed() / s.maxStep
+}
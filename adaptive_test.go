@@ -0,0 +1,79 @@
+// +build !live
+
+package servo
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// countBackend counts the writes to it.
+type countBackend struct {
+	writes int
+	lock   sync.Mutex
+}
+
+func (b *countBackend) Write(pulses []Pulse) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.writes++
+	return nil
+}
+
+func (b *countBackend) Close() error { return nil }
+
+func (b *countBackend) count() int {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.writes
+}
+
+func TestAdaptiveRate_rate(t *testing.T) {
+	a := adaptiveRate{fast: 5 * time.Millisecond, slow: 105 * time.Millisecond}
+	interval := 3 * time.Millisecond
+
+	tests := []struct {
+		activity float64
+		want     time.Duration
+	}{
+		{0, 105 * time.Millisecond},
+		{0.5, 55 * time.Millisecond},
+		{1, 5 * time.Millisecond},
+		{2, 5 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		if got := a.rate(tt.activity, interval); got != tt.want {
+			t.Errorf("activity %.2f: got: %v, want: %v", tt.activity, got, tt.want)
+		}
+	}
+
+	a.fast = time.Millisecond
+	if got, want := a.rate(1, interval), interval; got != want {
+		t.Errorf("rate faster than the updates, got: %v, want: %v", got, want)
+	}
+}
+
+func TestAdaptiveRate(t *testing.T) {
+	useBlaster(t)
+	cb := new(countBackend)
+	SetBackend(cb)
+	defer SetBackend(nil)
+	AdaptiveRate(time.Second, time.Millisecond)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetSpeed(1)
+
+	start := time.Now()
+	s.MoveTo(90).Wait()
+	elapsed := time.Since(start)
+
+	// At the slow rate, the move would be written at most once.
+	if got, min := cb.count(), int(elapsed/(50*time.Millisecond)); got < min {
+		t.Errorf("the rate was not raised while moving, got: %d writes in %v, want: at least %d", got, elapsed, min)
+	}
+}
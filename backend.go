@@ -0,0 +1,125 @@
+package servo
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+// Pulse is the pulse of a single channel of a Backend.
+type Pulse struct {
+	// Pin is the GPIO pin or channel of the servo.
+	Pin int
+	// Width is the width of the pulse. A width of 0 releases the channel.
+	Width time.Duration
+}
+
+// String implements the Stringer interface.
+func (p Pulse) String() string {
+	return fmt.Sprintf("%d=%v", p.Pin, p.Width)
+}
+
+// Backend drives the pulses of the servos. The default backend is the
+// pi-blaster daemon. Use servo.SetBackend() to drive the servos with other
+// hardware, e.g. a PCA9685 board (see the pca9685 package).
+type Backend interface {
+	// Write sets the pulses of the channels, sorted by pin. It is called by
+	// the manager every time the servos change.
+	Write(pulses []Pulse) error
+	// Close releases all the channels. It is called by servo.Close().
+	Close() error
+}
+
+// backendBox allows storing different Backend types in an atomic.Value.
+type backendBox struct {
+	Backend
+}
+
+// SetBackend sets the backend that drives the servos. If b is nil, the
+// pi-blaster daemon is used (default). Set the backend before connecting any
+// servo: the pulses already written to the previous backend are not released.
+func SetBackend(b Backend) {
+	if b == nil {
+		b = _blaster.pi
+	}
+	_blaster.backend.Store(backendBox{b})
+}
+
+// driver returns the current backend.
+func (b *blaster) driver() Backend {
+	return b.backend.Load().(backendBox).Backend
+}
+
+const (
+	// DefaultFrequency is the pwm frequency in Hz of analog servos, and the
+	// default frequency of the backends that can change it.
+	DefaultFrequency = 50
+	// MaxFrequency is the highest pwm frequency in Hz accepted by
+	// servo.SetFrequency(), the limit of most digital servos.
+	MaxFrequency = 330
+)
+
+// FrequencyBackend is a Backend that can change its pwm frequency, e.g. to
+// drive digital servos at up to 330Hz with lower latency. The pulse widths
+// written to it do not depend on the frequency: the backend derives the duty
+// cycles from them.
+type FrequencyBackend interface {
+	Backend
+	// Frequency returns the pwm frequency of the channels in Hz.
+	Frequency() float64
+	// SetFrequency sets the pwm frequency of the channels in Hz, and
+	// rewrites the pulses of the enabled channels for the new period.
+	SetFrequency(hz float64) error
+}
+
+// FrameBackend is a Backend that bounds the size of a single write, e.g. a
+// serial controller with a small packet buffer. The manager splits the pulses
+// of a flush into several calls to Write, and never splits a pulse.
+type FrameBackend interface {
+	Backend
+	// MaxFrame returns the maximum number of pulses of a call to Write, or 0
+	// if it is not bounded.
+	MaxFrame() int
+}
+
+// Frequency returns the pwm frequency in Hz of the current backend, or 0 if
+// the backend does not report it. For pi-blaster, it is the frequency set by
+// servo.SetPiBlasterFrequency().
+func Frequency() float64 {
+	switch b := _blaster.driver().(type) {
+	case FrequencyBackend:
+		return b.Frequency()
+	case *piBlaster:
+		return float64(time.Second) / float64(piBlasterPeriod())
+	}
+	return 0
+}
+
+// SetFrequency sets the pwm frequency in Hz of the current backend, up to
+// servo.MaxFrequency (default: servo.DefaultFrequency for most backends). Set
+// the backend first with servo.SetBackend().
+//
+// It returns an error wrapping ErrOutOfRange if hz is out of range or if the
+// longest pulse of a connected servo does not fit in the period, and
+// ErrUnsupported if the backend cannot change its frequency, e.g. pi-blaster,
+// whose frequency is fixed when the daemon starts (see
+// servo.SetPiBlasterFrequency()).
+func SetFrequency(hz float64) error {
+	if hz <= 0 || hz > MaxFrequency {
+		return fmt.Errorf("%w: frequency %.1fHz is not within 0 to %dHz", ErrOutOfRange, hz, MaxFrequency)
+	}
+	b, ok := _blaster.driver().(FrequencyBackend)
+	if !ok {
+		return fmt.Errorf("%w: cannot set the frequency to %.1fHz", ErrUnsupported, hz)
+	}
+
+	period := time.Duration(float64(time.Second) / hz)
+	for _, s := range _blaster.connected() {
+		c := s.Calibration()
+		if w := pulseWidth(math.Max(c.MinPulse, c.MaxPulse)); w > period {
+			return s.wrap(fmt.Errorf("%w: pulse %v is longer than the period %v of %.1fHz", ErrOutOfRange, w, period, hz))
+		}
+	}
+
+	return b.SetFrequency(hz)
+}
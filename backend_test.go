@@ -0,0 +1,114 @@
+// +build !live
+
+package servo
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordBackend records the pulses written to it.
+type recordBackend struct {
+	pulses map[int]time.Duration
+	closed bool
+	lock   sync.Mutex
+}
+
+func (b *recordBackend) Write(pulses []Pulse) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	for _, p := range pulses {
+		b.pulses[p.Pin] = p.Width
+	}
+	return nil
+}
+
+func (b *recordBackend) Close() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.closed = true
+	return nil
+}
+
+func TestSetBackend(t *testing.T) {
+	b := useBlaster(t)
+	rb := &recordBackend{pulses: make(map[int]time.Duration)}
+	SetBackend(rb)
+	Rate(time.Millisecond)
+
+	s := New(3)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	s.SetPosition(90)
+	s.Wait()
+	time.Sleep(50 * time.Millisecond)
+
+	rb.lock.Lock()
+	if got, want := rb.pulses[3], 1500*time.Microsecond; got != want {
+		t.Errorf("wrong pulse, got: %v, want: %v", got, want)
+	}
+	rb.lock.Unlock()
+
+	b.close()
+	if !rb.closed {
+		t.Error("backend was not closed")
+	}
+
+	SetBackend(nil)
+	if b.driver() != b.pi {
+		t.Error("default backend was not restored")
+	}
+}
+
+// frequencyBackend is a recordBackend that can change its frequency.
+type frequencyBackend struct {
+	recordBackend
+	hz float64
+}
+
+func (b *frequencyBackend) Frequency() float64 { return b.hz }
+
+func (b *frequencyBackend) SetFrequency(hz float64) error {
+	b.hz = hz
+	return nil
+}
+
+func TestSetFrequency(t *testing.T) {
+	useBlaster(t)
+
+	if f := Frequency(); f != 100 {
+		t.Errorf("wrong frequency of pi-blaster, got: %.1fHz, want: 100.0Hz", f)
+	}
+	if err := SetFrequency(200); !errors.Is(err, ErrUnsupported) {
+		t.Errorf("pi-blaster frequency was changed, got: %v, want: %v", err, ErrUnsupported)
+	}
+
+	fb := &frequencyBackend{recordBackend: recordBackend{pulses: make(map[int]time.Duration)}, hz: DefaultFrequency}
+	SetBackend(fb)
+	if err := SetFrequency(330); err != nil {
+		t.Fatal(err)
+	}
+	if f := Frequency(); f != 330 {
+		t.Errorf("wrong frequency, got: %.1fHz, want: 330.0Hz", f)
+	}
+	if err := SetFrequency(400); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("frequency above the max, got: %v, want: %v", err, ErrOutOfRange)
+	}
+
+	// A pulse of 3.5ms does not fit in the period of 3.33ms of 300Hz.
+	s := New(3)
+	s.AllowUnsafePulse = true
+	s.MaxPulse = 0.35
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetFrequency(300); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("pulse longer than the period, got: %v, want: %v", err, ErrOutOfRange)
+	}
+	if err := SetFrequency(250); err != nil || fb.hz != 250 {
+		t.Errorf("frequency was not set, got: %.1fHz, %v", fb.hz, err)
+	}
+}
@@ -0,0 +1,124 @@
+package servo
+
+import "fmt"
+
+// Tx stages the commands of a batch. See servo.Batch().
+type Tx struct {
+	moves []txMove
+	// positions are the SetPosition staged in the Tx, which are flushed
+	// together by the manager.
+	positions []txMove
+	// err is the error of the flush of the positions.
+	err error
+	// done is closed by the manager once the commands were applied.
+	done chan struct{}
+}
+
+// txMove is a MoveTo or SetPosition staged in a Tx.
+type txMove struct {
+	servo  *Servo
+	target float64
+	opts   []MoveOption
+}
+
+// MoveTo stages a move of the servo to target with the options, like
+// Servo.MoveTo().
+func (tx *Tx) MoveTo(s *Servo, target float64, opts ...MoveOption) {
+	tx.moves = append(tx.moves, txMove{s, target, opts})
+}
+
+// SetPosition stages an immediate jump of the servo to position, like
+// Servo.SetPosition(). All the positions of a Tx are written to the backend in
+// the same flush.
+func (tx *Tx) SetPosition(s *Servo, position float64) {
+	tx.positions = append(tx.positions, txMove{servo: s, target: position})
+}
+
+// apply applies the staged commands and adds the pwm of the positions of the
+// connected servos to data. It returns true if data must be flushed now. It
+// must be called by the manager.
+func (tx *Tx) apply(b *blaster, data map[gpio]pwm) bool {
+	for _, m := range tx.moves {
+		m.servo.moveToWith(m.target, nil, m.opts...)
+	}
+	for _, m := range tx.positions {
+		// A servo in the fault state or in maintenance ignores the
+		// position and stays idle.
+		m.servo.SetPosition(m.target)
+		if b._servos[m.servo.gpio()] == m.servo && !m.servo.isIdle() {
+			pin, pwm := m.servo.pwm()
+			data[pin] = pwm
+		}
+	}
+	return len(tx.positions) > 0
+}
+
+// Batch stages the commands given to tx by fn and releases them to the manager
+// at once, so all the moves begin on the same update, even without a Group.
+// The returned Waiter waits for all the moved servos. Batch does nothing after
+// servo.Close().
+//
+// The positions set with Tx.SetPosition() are written in a single flush, right
+// after fn returns, instead of on the next tick of servo.Rate(). Use it for
+// mechanisms where visible staggering is a problem, e.g. the two fingers of a
+// gripper. See servo.Apply().
+//
+//	servo.Batch(func(tx *servo.Tx) {
+//		tx.MoveTo(s1, 10)
+//		tx.MoveTo(s2, 170)
+//	}).Wait()
+func Batch(fn func(tx *Tx)) Waiter {
+	tx := &Tx{done: make(chan struct{})}
+	fn(tx)
+
+	w := new(waitAll)
+	for _, m := range tx.moves {
+		w.add(m.servo)
+	}
+	for _, m := range tx.positions {
+		w.add(m.servo)
+	}
+
+	tx.run()
+
+	return w
+}
+
+// run releases the commands to the manager, and waits for the manager to
+// apply them. It does nothing if the servo package was closed.
+func (tx *Tx) run() {
+	_blaster.run()
+	select {
+	case _blaster.batch <- tx:
+		<-tx.done
+	case <-_blaster.done:
+		tx.err = ErrClosed
+	}
+}
+
+// Apply sets the positions of the servos immediately, like
+// Servo.SetPosition(), and writes all of them to the backend in a single
+// frame, e.g. the same line of pi-blaster. It returns the error of the write,
+// ErrClosed after servo.Close(), or an error wrapping ErrOutOfRange without
+// moving any servo if there are more positions than fit in a frame of the
+// backend (see FrameBackend, 9 servos with pi-blaster).
+//
+//	err := servo.Apply(map[*servo.Servo]float64{
+//		pan:  30,
+//		tilt: 120,
+//	})
+func Apply(positions map[*Servo]float64) error {
+	if f, ok := _blaster.driver().(FrameBackend); ok {
+		if max := f.MaxFrame(); max > 0 && len(positions) > max {
+			return fmt.Errorf("%w: %d positions do not fit in a frame of %d pulses", ErrOutOfRange, len(positions), max)
+		}
+	}
+
+	tx := &Tx{done: make(chan struct{})}
+	for s, position := range positions {
+		tx.SetPosition(s, position)
+	}
+	tx.run()
+
+	return tx.err
+}
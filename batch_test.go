@@ -0,0 +1,120 @@
+// +build !live
+
+package servo
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestBatch(t *testing.T) {
+	useBlaster(t)
+
+	a, b := New(98), New(99)
+	for _, s := range []*Servo{a, b} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+	}
+
+	sub := Subscribe(4, EventMove)
+	defer sub.Close()
+
+	Batch(func(tx *Tx) {
+		tx.MoveTo(a, 10)
+		tx.MoveTo(b, 170)
+	}).Wait()
+
+	if a.Position() != 10 || b.Position() != 170 {
+		t.Errorf("wrong positions, got: %.2f, %.2f, want: 10.00, 170.00", a.Position(), b.Position())
+	}
+
+	first, second := <-sub.C, <-sub.C
+	if d := second.Time.Sub(first.Time); d > 3*time.Millisecond {
+		t.Errorf("moves did not start on the same update, %v apart", d)
+	}
+}
+
+// frameBackend records the frames written to it.
+type frameBackend struct {
+	frames [][]Pulse
+	lock   sync.Mutex
+}
+
+func (b *frameBackend) Write(pulses []Pulse) error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.frames = append(b.frames, append([]Pulse(nil), pulses...))
+	return nil
+}
+
+func (b *frameBackend) Close() error { return nil }
+
+func TestApply(t *testing.T) {
+	useBlaster(t)
+	fb := new(frameBackend)
+	SetBackend(fb)
+	defer SetBackend(nil)
+
+	a, b := New(98), New(99)
+	for _, s := range []*Servo{a, b} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+	}
+
+	if err := Apply(map[*Servo]float64{a: 0, b: 180}); err != nil {
+		t.Fatal(err)
+	}
+	if a.Position() != 0 || b.Position() != 180 {
+		t.Errorf("wrong positions, got: %.2f, %.2f, want: 0.00, 180.00", a.Position(), b.Position())
+	}
+
+	fb.lock.Lock()
+	defer fb.lock.Unlock()
+	want := []Pulse{{98, 500 * time.Microsecond}, {99, 2500 * time.Microsecond}}
+	if len(fb.frames) != 1 || !reflect.DeepEqual(fb.frames[0], want) {
+		t.Errorf("positions were not written in one frame, got: %v, want: [%v]", fb.frames, want)
+	}
+}
+
+func TestApply_maxFrame(t *testing.T) {
+	useBlaster(t)
+	bb := &boundedBackend{max: 2}
+	SetBackend(bb)
+	defer SetBackend(nil)
+
+	positions := make(map[*Servo]float64)
+	for pin := 97; pin <= 99; pin++ {
+		s := New(pin)
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+		positions[s] = 180
+	}
+
+	if err := Apply(positions); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("wrong error, got: %v, want: %v", err, ErrOutOfRange)
+	}
+	for s := range positions {
+		if s.Position() != 0 {
+			t.Errorf("%v moved, got: %.2f, want: 0.00", s, s.Position())
+		}
+	}
+}
+
+func TestApply_closed(t *testing.T) {
+	b := useBlaster(t)
+	s := New(99)
+	b.close()
+
+	if err := Apply(map[*Servo]float64{s: 90}); !errors.Is(err, ErrClosed) {
+		t.Errorf("wrong error, got: %v, want: %v", err, ErrClosed)
+	}
+}
@@ -2,26 +2,51 @@ package servo
 
 import (
 	"fmt"
-	"io/ioutil"
 	"log"
 	"math"
-	"os"
 	"os/exec"
-	"strings"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 type blaster struct {
-	disabled bool
-	buffer   chan string
-	done     chan struct{}
-	servos   chan servoPkg
-	_servos  map[gpio]*Servo
+	// dropped counts the frames that could not be written. It is accessed
+	// atomically and must be 64-bit aligned.
+	dropped uint64
 
-	rate chan time.Duration
+	// pi is the default backend.
+	pi *piBlaster
+	// backend holds the backendBox of the Backend that drives the servos.
+	backend atomic.Value
+	// canary is set to 1 when no data should be written at all. It is
+	// accessed atomically.
+	canary  int32
+	buffer  chan string
+	done    chan struct{}
+	servos  chan servoPkg
+	_servos map[gpio]*Servo
+	// lock guards _servos. The manager is the only writer, so it can read
+	// _servos without locking.
+	lock *sync.RWMutex
 
-	ws *sync.WaitGroup
+	rate     chan time.Duration
+	adaptive chan adaptiveRate
+	batch    chan *Tx
+	// retick makes the manager recompute its update interval. See
+	// Servo.SetUpdateInterval().
+	retick chan struct{}
+	// wake resumes the tickers of the manager. See wakeUp.
+	wake chan struct{}
+
+	bus     *bus
+	hooks   *hooks
+	degrade *degradation
+
+	ws        *sync.WaitGroup
+	startOnce *sync.Once
+	closeOnce *sync.Once
 }
 
 var _blaster *blaster
@@ -32,20 +57,20 @@ type pwm float64
 type servoPkg struct {
 	servo *Servo
 	add   bool
+	// remap moves the servo to the pin to, instead of adding or removing it.
+	remap bool
+	to    gpio
+	// ack receives the result once the manager added, removed or remapped
+	// the servo.
+	ack chan error
 }
 
 func init() {
-	_blaster = &blaster{
-		buffer:  make(chan string),
-		done:    make(chan struct{}),
-		servos:  make(chan servoPkg),
-		rate:    make(chan time.Duration),
-		_servos: make(map[gpio]*Servo),
-	}
+	_blaster = newBlaster()
 
 	if err := _blaster.start(); err != nil {
 		if err == errPiBlasterNotFound {
-			log.Println("WARNING:", err, "\n\t(servo will continue with pi-blaster disabled)")
+			log.Println("WARNING:", err, "\n\t(servo will continue with pi-blaster disabled, use servo.SetBackend() to drive the servos without pi-blaster)")
 			noPiBlaster()
 			if err := _blaster.start(); err != nil {
 				panic(err)
@@ -56,10 +81,37 @@ func init() {
 	}
 }
 
+// newBlaster creates a blaster with all its channels initialized. Call start
+// to run the manager.
+func newBlaster() *blaster {
+	b := &blaster{
+		pi:        newPiBlaster(),
+		buffer:    make(chan string),
+		done:      make(chan struct{}),
+		servos:    make(chan servoPkg),
+		rate:      make(chan time.Duration),
+		adaptive:  make(chan adaptiveRate),
+		batch:     make(chan *Tx),
+		retick:    make(chan struct{}),
+		wake:      make(chan struct{}, 1),
+		_servos:   make(map[gpio]*Servo),
+		lock:      new(sync.RWMutex),
+		bus:       newBus(),
+		hooks:     newHooks(),
+		degrade:   newDegradation(),
+		ws:        new(sync.WaitGroup),
+		startOnce: new(sync.Once),
+		closeOnce: new(sync.Once),
+	}
+	b.backend.Store(backendBox{b.pi})
+
+	return b
+}
+
 // noPiBlaster stops this package from sending text to /dev/pi-blaster. Useful
 // for debugging in devices without pi-blaster installed.
 func noPiBlaster() {
-	_blaster.disabled = true
+	_blaster.pi.disabled = true
 }
 
 // hasBlaster checks if pi-blaster is running in the system. It depends on
@@ -75,32 +127,81 @@ func hasBlaster() bool {
 var (
 	// errPiBlasterNotFound is thrown when an instance of pi-blaster could not
 	// be found on the system.
-	errPiBlasterNotFound = fmt.Errorf("pi-blaster was not found running: start pi-blaster to avoid this error")
+	errPiBlasterNotFound = fmt.Errorf("%w: pi-blaster was not found running: start pi-blaster to avoid this error", ErrBackendUnavailable)
+	// errCanary is returned by write when nothing was written because of the
+	// canary mode.
+	errCanary = fmt.Errorf("canary mode is on")
 )
 
-// start runs a goroutine to send data to pi-blaster. If NoPiBlaster was
-// called, the data is sent to ioutil.Discard.
+// start checks that blaster can send data to pi-blaster. If NoPiBlaster was
+// called, the data is sent to ioutil.Discard. The manager is started on demand
+// by run.
 func (b *blaster) start() error {
-	if !b.disabled && !hasBlaster() {
+	if !b.pi.disabled && !b.pi.available() {
 		return errPiBlasterNotFound
 	}
 
-	b.manager(b.done)
-
 	return nil
 }
 
+// available checks if the backend can be written to. Only pi-blaster is
+// checked, even if it was disabled because it was not running at startup.
+// Other backends connect to their device when they are created, so they are
+// always available.
+func (b *blaster) available() bool {
+	if b.driver() != Backend(b.pi) {
+		return true
+	}
+	return b.pi.available()
+}
+
+// run starts the manager the first time it is called and then calls the start
+// hooks. It does nothing if blaster was closed.
+func (b *blaster) run() {
+	started := false
+	b.startOnce.Do(func() {
+		if b.isClosed() {
+			return
+		}
+		b.manager(b.done)
+		started = true
+	})
+
+	if started {
+		b.hooks.started()
+	}
+}
+
 // manager keeps track of changes to servos and flushes the data to pi-blaster.
 // The flush will happen only if there was a change in the servos data.
 // Everytime the data is flushed, the variable is emptied.
+// While all the servos are idle, the tickers slow down to idleTick, until a
+// change of a servo wakes the manager up.
 func (b *blaster) manager(done <-chan struct{}) {
 	data := make(map[gpio]pwm)
 
-	updateCh := time.NewTicker(3 * time.Millisecond)
-	flushCh := time.NewTicker(40 * time.Millisecond)
+	interval := 3 * time.Millisecond
+	updateCh := time.NewTicker(interval)
+	flushRate := 40 * time.Millisecond
+	flushCh := time.NewTicker(flushRate)
+	// adaptive is set by AdaptiveRate().
+	var adaptive adaptiveRate
+	// sleeping is set while all the servos are idle, and both tickers run
+	// at idleTick.
+	sleeping := false
+	resume := func() {
+		if !sleeping {
+			return
+		}
+		debugf("manager resumed")
+		sleeping = false
+		updateCh.Stop()
+		updateCh = time.NewTicker(interval)
+		flushCh.Stop()
+		flushCh = time.NewTicker(flushRate)
+		b.degrade.reset()
+	}
 
-	var ws sync.WaitGroup
-	b.ws = &ws
 	b.ws.Add(1)
 
 	go func() {
@@ -111,25 +212,161 @@ func (b *blaster) manager(done <-chan struct{}) {
 				return
 			case pkg := <-b.servos:
 				servo := pkg.servo
-				if pkg.add {
-					b._servos[servo.pin] = servo
-				} else {
-					delete(b._servos, servo.pin)
-					data[servo.pin] = 0.0
+				var err error
+				b.lock.Lock()
+				switch {
+				case pkg.remap:
+					err = b.remapped(servo, pkg.to, data)
+				case pkg.add:
+					if other, ok := b._servos[servo.gpio()]; ok && other != servo {
+						err = servo.wrap(fmt.Errorf("%w: gpio(%d) is used by %q", ErrPinClaimed, servo.gpio(), other.Name))
+						break
+					}
+					b._servos[servo.gpio()] = servo
+					debugf("subscribed %v", servo)
+				default:
+					delete(b._servos, servo.gpio())
+					data[servo.gpio()] = 0.0
+					debugf("unsubscribed %v", servo)
+				}
+				b.lock.Unlock()
+				if !pkg.add && !pkg.remap {
+					// Release the pin now, so Servo.Close() returns
+					// the error of the write.
+					err = b.flush(data)
+					data = make(map[gpio]pwm)
 				}
+				pkg.ack <- err
 				updateCh.Stop()
-				factor := math.Log10(float64(len(b._servos)+1))*3 + 1
-				updateCh = time.NewTicker(time.Duration(factor) * 3 * time.Millisecond)
-			case <-updateCh.C:
+				interval = b.tickInterval()
+				updateCh = time.NewTicker(interval)
+				b.degrade.reset()
+				resume()
+			case <-b.retick:
+				if i := b.tickInterval(); i != interval {
+					debugf("update interval set to %v", i)
+					interval = i
+					updateCh.Stop()
+					updateCh = time.NewTicker(interval)
+					b.degrade.reset()
+				}
+				resume()
+			case <-b.wake:
+				resume()
+			case now := <-updateCh.C:
+				tick := interval
+				if sleeping {
+					tick = idleTick
+				}
+				if t := b.degrade.tick(now, tick); t != 0 {
+					debugf("manager %v", t)
+					b.bus.publish(Event{Type: t, Time: now})
+				}
+				skipLow := b.degrade.skipLow()
+				maintenance := InMaintenance()
+				activity := 0.0
+				moving := 0
+				var fastest time.Duration
+				var followers []*Servo
 				for _, servo := range b._servos {
-					if !servo.isIdle() {
+					active := servo.isMoving()
+					if active {
+						moving++
+						fastest = faster(fastest, servo.UpdateInterval())
+					}
+					if servo.takeRelease() {
+						data[servo.gpio()] = 0.0
+						continue
+					}
+					if servo.limp() {
+						continue
+					}
+					if maintenance {
+						continue
+					}
+					if skipLow && servo.LowPriority {
+						continue
+					}
+					if servo.leader() != nil {
+						followers = append(followers, servo)
+						continue
+					}
+					if active && servo.due(now, interval) {
 						pin, pwm := servo.pwm()
 						data[pin] = pwm
+						servo.driven()
+					}
+					if adaptive.enabled() {
+						activity = math.Max(activity, servo.activity())
+					}
+				}
+				// The followers are updated after their masters, in the
+				// same tick, when their master changed or they just
+				// started following.
+				sortFollowers(followers)
+				for _, servo := range followers {
+					master := servo.leader()
+					if master == nil {
+						continue
+					}
+					if _, ok := data[master.gpio()]; !ok && servo.isIdle() {
+						continue
+					}
+					if pin, pwm, ok := servo.mirror(now); ok {
+						data[pin] = pwm
+						servo.driven()
+					}
+				}
+				if i := tickInterval(moving, fastest); i != interval && !sleeping {
+					debugf("update interval set to %v for %d moving servos", i, moving)
+					interval = i
+					updateCh.Stop()
+					updateCh = time.NewTicker(interval)
+					b.degrade.reset()
+				}
+				if adaptive.enabled() {
+					if rate := adaptive.rate(activity, interval); rate != flushRate {
+						flushRate = rate
+						flushCh.Stop()
+						flushCh = time.NewTicker(rate)
 					}
 				}
+				switch idle := b.asleep(data); {
+				case idle && !sleeping:
+					// Nothing changes until a servo moves again, which
+					// wakes the manager up.
+					debugf("manager sleeping")
+					sleeping = true
+					updateCh.Stop()
+					updateCh = time.NewTicker(idleTick)
+					flushCh.Stop()
+					flushCh = time.NewTicker(idleTick)
+				case !idle:
+					resume()
+				}
+			case tx := <-b.batch:
+				if len(tx.positions) > 0 && len(data) != 0 {
+					// Flush the pending data first, so the
+					// positions of the batch are not split
+					// across frames with other servos.
+					b.flush(data)
+					data = make(map[gpio]pwm)
+				}
+				if tx.apply(b, data) {
+					tx.err = b.flush(data)
+					data = make(map[gpio]pwm)
+				}
+				close(tx.done)
 			case rate := <-b.rate:
+				debugf("flush rate set to %v", rate)
+				adaptive = adaptiveRate{}
+				flushRate = rate
 				flushCh.Stop()
 				flushCh = time.NewTicker(rate)
+				resume()
+			case a := <-b.adaptive:
+				debugf("adaptive flush rate set from %v to %v", a.fast, a.slow)
+				adaptive = a
 			case <-flushCh.C:
 				if len(data) != 0 {
 					b.flush(data)
@@ -140,68 +377,301 @@ func (b *blaster) manager(done <-chan struct{}) {
 	}()
 }
 
-// subscribe adds a Servo reference to the manager.
-func (b *blaster) subscribe(servo *Servo) {
-	b.servos <- servoPkg{servo, true}
+// connected returns the servos connected to the manager sorted by pin.
+func (b *blaster) connected() []*Servo {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	servos := make([]*Servo, 0, len(b._servos))
+	for _, s := range b._servos {
+		servos = append(servos, s)
+	}
+	sort.Slice(servos, func(i, j int) bool {
+		return servos[i].gpio() < servos[j].gpio()
+	})
+
+	return servos
+}
+
+// isConnected checks if the servo is connected to the manager.
+func (b *blaster) isConnected(servo *Servo) bool {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	return b._servos[servo.gpio()] == servo
 }
 
-// unsubscribe removes a Servo reference from the manager.
-func (b *blaster) unsubscribe(servo *Servo) {
-	b.servos <- servoPkg{servo, false}
+// subscribe adds a Servo reference to the manager. It returns an error if the
+// blaster was closed.
+func (b *blaster) subscribe(servo *Servo) error {
+	b.run()
+	pkg := servoPkg{servo: servo, add: true, ack: make(chan error, 1)}
+	select {
+	case b.servos <- pkg:
+	case <-b.done:
+		return servo.wrap(ErrClosed)
+	}
+	if err := <-pkg.ack; err != nil {
+		return err
+	}
+	b.bus.publish(servo.eventNow(EventConnect))
+
+	return nil
+}
+
+// unsubscribe removes a Servo reference from the manager and releases its
+// pin. It returns the error of the release, and does nothing if the blaster
+// was closed.
+func (b *blaster) unsubscribe(servo *Servo) error {
+	b.run()
+	pkg := servoPkg{servo: servo, ack: make(chan error, 1)}
+	select {
+	case b.servos <- pkg:
+	case <-b.done:
+		return nil
+	}
+	err := <-pkg.ack
+	b.bus.publish(servo.eventNow(EventDisconnect))
+	return err
+}
+
+// remap moves a Servo to the pin to. It returns an error if the pin is used
+// by another servo or if the blaster was closed.
+func (b *blaster) remap(servo *Servo, to gpio) error {
+	b.run()
+	pkg := servoPkg{servo: servo, remap: true, to: to, ack: make(chan error, 1)}
+	select {
+	case b.servos <- pkg:
+	case <-b.done:
+		return servo.wrap(ErrClosed)
+	}
+	return <-pkg.ack
+}
+
+// remapped moves the servo to the pin to in the manager. If the servo is
+// connected, the old pin is released in data. It must be called by the
+// manager with the lock held.
+func (b *blaster) remapped(servo *Servo, to gpio, data map[gpio]pwm) error {
+	from := servo.gpio()
+	if other, ok := b._servos[to]; ok && other != servo {
+		return servo.wrap(fmt.Errorf("%w: gpio(%d) is used by %q", ErrPinClaimed, to, other.Name))
+	}
+
+	atomic.StoreInt32(&servo.pin, int32(to))
+	if b._servos[from] != servo {
+		// The servo is not connected.
+		return nil
+	}
+	delete(b._servos, from)
+	data[from] = 0.0
+	b._servos[to] = servo
+	debugf("remapped %v from gpio(%d)", servo, from)
+
+	// Write the position on the new pin in the same flush as the release,
+	// unless the servo must not be driven: it is in the fault state,
+	// detached, or its pin is being released.
+	servo.lock.Lock()
+	if servo.fault != nil || servo.Detached() || atomic.LoadInt32(&servo.release) != 0 {
+		servo.lock.Unlock()
+		return nil
+	}
+	servo.idle = false
+	servo.publish()
+	servo.lock.Unlock()
+	pin, pwm := servo.pwm()
+	data[pin] = pwm
+
+	return nil
+}
+
+// isClosed checks if the blaster was closed.
+func (b *blaster) isClosed() bool {
+	select {
+	case <-b.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// Servos returns a snapshot of the connected servos sorted by pin. Changing
+// the returned slice does not affect the connected servos.
+func Servos() []*Servo {
+	return _blaster.connected()
 }
 
 // Rate changes the rate that data is flushed to pi-blaster (default: 40ms).
-// This can be changed on-the-fly.
+// This can be changed on-the-fly, and it turns off servo.AdaptiveRate(). It
+// does nothing after servo.Close().
 func Rate(r time.Duration) {
-	_blaster.rate <- r
+	_blaster.run()
+	select {
+	case _blaster.rate <- r:
+	case <-_blaster.done:
+	}
 }
 
 // Close cleans up the servo package. Make sure to call this in your main
-// goroutine.
-func Close() {
+// goroutine. It returns the error of the release of the pins, which is also
+// logged.
+//
+// It is safe to call Close more than once and to call Servo.Close() before or
+// after Close. Once the package is closed, Servo.Connect() returns an error,
+// Servo.MoveTo() is ignored, and any goroutine blocked on Servo.Wait() is
+// released.
+func Close() error {
 	if _blaster == nil {
-		return
+		return nil
 	}
-	_blaster.close()
+	return _blaster.close()
 }
 
-// close stops blaster if it was started.
-func (b *blaster) close() {
-	b.write("*=0.0")
-	close(b.done)
-	b.ws.Wait()
+// close stops blaster if it was started. Only the first call has any effect,
+// and returns the error of the release of the pins.
+func (b *blaster) close() error {
+	var err error
+	b.closeOnce.Do(func() {
+		b.hooks.shutdown()
+
+		if err = b.release(); err == errCanary {
+			err = nil
+		} else if err != nil {
+			log.Println("WARNING: could not release the pins:", err)
+		}
+		close(b.done)
+		b.ws.Wait()
+
+		// The manager is not running anymore, so nobody will update the
+		// servos. Release their waiters.
+		for _, servo := range b._servos {
+			servo.Stop()
+		}
+
+		b.bus.publish(Event{Type: EventClose, Time: time.Now()})
+		b.bus.close()
+
+		b.hooks.closed()
+	})
+	return err
 }
 
-// flush parses the data into "PIN=PWM PIN=PWM" format.
-func (b *blaster) flush(data map[gpio]pwm) {
-	s := new(strings.Builder)
+// flush writes the data to the backend, sorted by pin. If the backend is a
+// FrameBackend, the data is split into frames of at most MaxFrame() pulses. It
+// returns the error of the first frame that failed, but still tries to write
+// the rest. The errors are also reported to the OnWriteError hooks.
+func (b *blaster) flush(data map[gpio]pwm) error {
+	pins := make([]gpio, 0, len(data))
+	for pin := range data {
+		pins = append(pins, pin)
+	}
+	sort.Slice(pins, func(i, j int) bool {
+		return pins[i] < pins[j]
+	})
 
-	for pin, pwm := range data {
-		fmt.Fprintf(s, " %d=%.6f", pin, pwm)
+	max := 0
+	if f, ok := b.driver().(FrameBackend); ok {
+		max = f.MaxFrame()
 	}
 
-	if s.Len() == 0 {
-		return
+	var first error
+	for len(pins) > 0 {
+		n := len(pins)
+		if max > 0 && n > max {
+			n = max
+		}
+		if err := b.flushFrame(pins[:n], data); err != nil && first == nil {
+			first = err
+		}
+		pins = pins[n:]
+	}
+	return first
+}
+
+// flushFrame writes the data of pins to the backend in a single write.
+func (b *blaster) flushFrame(pins []gpio, data map[gpio]pwm) error {
+	pulses := make([]Pulse, len(pins))
+	for i, pin := range pins {
+		pulses[i] = Pulse{
+			Pin:   int(pin),
+			Width: pulseWidth(float64(data[pin])),
+		}
+	}
+
+	switch err := b.write(pulses); err {
+	case nil:
+		b.written(pins, data)
+	case errCanary:
+	default:
+		atomic.AddUint64(&b.dropped, 1)
+		if !b.hooks.writeFailed(err) {
+			log.Println("WARNING: could not write to the backend:", err)
+		}
+		b.failed(pins, err)
+		return err
 	}
+	return nil
+}
 
-	b.write(s.String())
+// DroppedFrames returns the number of frames that the manager could not write
+// to the backend, e.g. because pi-blaster stopped reading its pipe. It only
+// increases, so alerting rules can watch its rate.
+func DroppedFrames() uint64 {
+	return atomic.LoadUint64(&_blaster.dropped)
 }
 
-// write sends a string s to the designated io.Writer.
-func (b *blaster) write(s string) {
-	w := ioutil.Discard
+// written records the pwm that was written to the servos connected to pins.
+func (b *blaster) written(pins []gpio, data map[gpio]pwm) {
+	now := time.Now()
 
-	if !b.disabled {
-		const pipepath = "/dev/pi-blaster"
-		f, err := os.OpenFile(pipepath,
-			os.O_WRONLY, os.ModeNamedPipe)
-		if err != nil {
-			panic(err)
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	for _, pin := range pins {
+		if servo, ok := b._servos[pin]; ok {
+			servo.lock.Lock()
+			servo.writtenPWM = data[pin]
+			servo.writtenAt = now
+			servo.errors = 0
+			span := servo.span
+			servo.lock.Unlock()
+
+			if span != nil {
+				span.flushed(float64(data[pin]))
+			}
 		}
-		defer f.Close()
-		w = f
+	}
+}
+
+// failed records a write error for the servos connected to pins.
+func (b *blaster) failed(pins []gpio, err error) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	for _, pin := range pins {
+		if servo, ok := b._servos[pin]; ok {
+			servo.writeFailed(err)
+		}
+	}
+}
+
+// write sends the pulses to the backend. It returns errCanary if nothing was
+// written because of the canary mode.
+func (b *blaster) write(pulses []Pulse) error {
+	if atomic.LoadInt32(&b.canary) != 0 {
+		debugf("canary, not written: %v", pulses)
+		return errCanary
+	}
+
+	return b.driver().Write(pulses)
+}
+
+// release releases all the channels of the backend. It returns errCanary if
+// nothing was written because of the canary mode.
+func (b *blaster) release() error {
+	if atomic.LoadInt32(&b.canary) != 0 {
+		debugf("canary, not released")
+		return errCanary
 	}
 
-	fmt.Fprintf(w, "%s\n", s)
-	//fmt.Fprintf(os.Stdout, "%s\n", s)
+	return b.driver().Close()
 }
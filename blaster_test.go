@@ -3,7 +3,12 @@
 package servo
 
 import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 )
 
 func TestInit(t *testing.T) {
@@ -20,7 +25,228 @@ func TestHasBlaster(t *testing.T) {
 
 func TestNoPiBlaster(t *testing.T) {
 	noPiBlaster()
-	if !_blaster.disabled {
+	if !_blaster.pi.disabled {
 		t.Error("NoPiBlaster() could not disable _blaster")
 	}
 }
+
+// useBlaster replaces the package blaster with a new one for the duration of
+// the test.
+func useBlaster(t *testing.T) *blaster {
+	b := newBlaster()
+	b.pi.disabled = true
+	if err := b.start(); err != nil {
+		t.Fatal(err)
+	}
+
+	old := _blaster
+	_blaster = b
+	t.Cleanup(func() {
+		b.close()
+		_blaster = old
+	})
+
+	return b
+}
+
+func TestClose(t *testing.T) {
+	timeout := func(t *testing.T, fn func()) {
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			fn()
+		}()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("deadlock after 1 second")
+		}
+	}
+
+	t.Run("Package then Servo", func(t *testing.T) {
+		useBlaster(t)
+		s := New(99)
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+
+		timeout(t, func() {
+			Close()
+			s.Close()
+			Close()
+		})
+	})
+
+	t.Run("Servo then Package", func(t *testing.T) {
+		useBlaster(t)
+		s := New(99)
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+
+		timeout(t, func() {
+			s.Close()
+			Close()
+			s.Close()
+		})
+	})
+
+	t.Run("Concurrent", func(t *testing.T) {
+		useBlaster(t)
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(2)
+			go func(i int) {
+				defer wg.Done()
+				s := New(i)
+				s.Connect()
+				s.Close()
+			}(i)
+			go func() {
+				defer wg.Done()
+				Close()
+			}()
+		}
+		timeout(t, wg.Wait)
+	})
+
+	t.Run("Use after Close", func(t *testing.T) {
+		useBlaster(t)
+		s := New(99)
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		s.moveTo(180)
+		sub := Subscribe(1, AllEvents)
+
+		timeout(t, func() {
+			Close()
+			s.Wait()
+			Rate(10 * time.Millisecond)
+			s.MoveTo(0).Wait()
+		})
+
+		if err := New(98).Connect(); !errors.Is(err, ErrClosed) {
+			t.Errorf("Connect after Close, got: %v, want: %v", err, ErrClosed)
+		}
+		for range sub.C {
+		}
+		if _, ok := <-Subscribe(1, AllEvents).C; ok {
+			t.Error("Subscribe after Close returned an open subscription")
+		}
+	})
+}
+
+// syncBuffer is a concurrent-safe buffer to capture the data written by the
+// manager.
+type syncBuffer struct {
+	b    strings.Builder
+	lock sync.Mutex
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.b.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.b.String()
+}
+
+func TestBlaster_Flush(t *testing.T) {
+	b := newBlaster()
+	b.pi.disabled = true
+	out := new(syncBuffer)
+	b.pi.sink = out
+
+	data := make(map[gpio]pwm)
+	for pin := gpio(0); pin < 30; pin++ {
+		data[pin] = 0.123456
+	}
+	b.flush(data)
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) < 2 {
+		t.Fatalf("data was not split, got: %q", out.String())
+	}
+	joined := ""
+	for _, line := range lines {
+		if len(line)+1 > piBlasterMaxLine {
+			t.Errorf("line is too long (%d bytes): %q", len(line)+1, line)
+		}
+		joined += line
+	}
+
+	want := new(strings.Builder)
+	for pin := 0; pin < 30; pin++ {
+		fmt.Fprintf(want, " %d=0.123456", pin)
+	}
+	if joined != want.String() {
+		t.Errorf("flushed data does not match\ngot:\n%v\nwant:\n%v", joined, want)
+	}
+}
+
+func TestServos(t *testing.T) {
+	useBlaster(t)
+
+	if got := Servos(); len(got) != 0 {
+		t.Errorf("servos connected before Connect: %v", got)
+	}
+
+	a, b, c := New(20), New(4), New(12)
+	for _, s := range []*Servo{a, b, c} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+	}
+	c.Close()
+
+	got := Servos()
+	want := []*Servo{b, a}
+	if len(got) != len(want) {
+		t.Fatalf("wrong servos, got: %v, want: %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("wrong servo %d, got: %v, want: %v", i, got[i], want[i])
+		}
+	}
+}
+
+// boundedBackend is a frameBackend that bounds the pulses of a write.
+type boundedBackend struct {
+	frameBackend
+	max int
+}
+
+func (b *boundedBackend) MaxFrame() int { return b.max }
+
+func TestBlaster_Flush_frameBackend(t *testing.T) {
+	b := newBlaster()
+	bb := &boundedBackend{max: 2}
+	b.backend.Store(backendBox{bb})
+
+	data := make(map[gpio]pwm)
+	for pin := gpio(0); pin < 5; pin++ {
+		data[pin] = 0.15
+	}
+	if err := b.flush(data); err != nil {
+		t.Fatal(err)
+	}
+
+	got := make([]int, len(bb.frames))
+	for i, frame := range bb.frames {
+		got[i] = len(frame)
+	}
+	if fmt.Sprint(got) != "[2 2 1]" {
+		t.Fatalf("pulses per frame, got: %v, want: [2 2 1]", got)
+	}
+	if pin := bb.frames[2][0].Pin; pin != 4 {
+		t.Errorf("last frame, got pin: %d, want: %d", pin, 4)
+	}
+}
@@ -0,0 +1,135 @@
+package servo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+)
+
+// Board maps the names of the pins of a board to the pin numbers of the
+// backend, so the same program can run on boards with different numbering.
+// Names are not case-sensitive.
+type Board struct {
+	// Name is the name of the board.
+	Name string
+	// Pins maps the pin names to the pin numbers.
+	Pins map[string]int
+}
+
+// Pin returns the pin number of the pin name. A plain number is returned as
+// is.
+func (b *Board) Pin(name string) (int, error) {
+	if pin, ok := b.Pins[strings.ToUpper(name)]; ok {
+		return pin, nil
+	}
+	if pin, err := strconv.Atoi(name); err == nil && pin >= 0 {
+		return pin, nil
+	}
+	return 0, fmt.Errorf("unknown pin %q on %s", name, b.Name)
+}
+
+// RaspberryPi maps the BCM names ("GPIO17") and the physical pins of the
+// 40-pin header ("PIN11") of a Raspberry Pi to the BCM numbering used by
+// pi-blaster. It is the default board.
+var RaspberryPi = &Board{
+	Name: "Raspberry Pi",
+	Pins: withNames(map[string]int{
+		"PIN3": 2, "PIN5": 3, "PIN7": 4, "PIN8": 14, "PIN10": 15,
+		"PIN11": 17, "PIN12": 18, "PIN13": 27, "PIN15": 22, "PIN16": 23,
+		"PIN18": 24, "PIN19": 10, "PIN21": 9, "PIN22": 25, "PIN23": 11,
+		"PIN24": 8, "PIN26": 7, "PIN27": 0, "PIN28": 1, "PIN29": 5,
+		"PIN31": 6, "PIN32": 12, "PIN33": 13, "PIN35": 19, "PIN36": 16,
+		"PIN37": 26, "PIN38": 20, "PIN40": 21,
+	}, "GPIO", 28),
+}
+
+// BeagleBone maps the header pins of a BeagleBone Black ("P9_14") to the
+// numbers of the Linux GPIO subsystem, e.g. for a softpwm.GPIO. The pwm pins
+// (P9_14, P9_16, P8_13, P8_19, P9_21, P9_22 and P9_42) can also be driven in
+// hardware with the sysfspwm package, using the channels of their pwm chip.
+var BeagleBone = &Board{
+	Name: "BeagleBone Black",
+	Pins: map[string]int{
+		"P8_3": 38, "P8_4": 39, "P8_5": 34, "P8_6": 35, "P8_7": 66,
+		"P8_8": 67, "P8_9": 69, "P8_10": 68, "P8_11": 45, "P8_12": 44,
+		"P8_13": 23, "P8_14": 26, "P8_15": 47, "P8_16": 46, "P8_17": 27,
+		"P8_18": 65, "P8_19": 22, "P8_20": 63, "P8_21": 62, "P8_22": 37,
+		"P8_23": 36, "P8_24": 33, "P8_25": 32, "P8_26": 61, "P8_27": 86,
+		"P8_28": 88, "P8_29": 87, "P8_30": 89, "P8_31": 10, "P8_32": 11,
+		"P8_33": 9, "P8_34": 81, "P8_35": 8, "P8_36": 80, "P8_37": 78,
+		"P8_38": 79, "P8_39": 76, "P8_40": 77, "P8_41": 74, "P8_42": 75,
+		"P8_43": 72, "P8_44": 73, "P8_45": 70, "P8_46": 71,
+		"P9_11": 30, "P9_12": 60, "P9_13": 31, "P9_14": 50, "P9_15": 48,
+		"P9_16": 51, "P9_17": 5, "P9_18": 4, "P9_19": 13, "P9_20": 12,
+		"P9_21": 3, "P9_22": 2, "P9_23": 49, "P9_24": 15, "P9_25": 117,
+		"P9_26": 14, "P9_27": 115, "P9_28": 113, "P9_29": 111, "P9_30": 112,
+		"P9_31": 110, "P9_41": 20, "P9_42": 7,
+	},
+}
+
+// Allwinner maps the port names of Allwinner SoCs ("PA12", "PD14"), used by
+// the Orange Pi and Banana Pi boards, to the numbers of the Linux GPIO
+// subsystem: 32 per port, from port A.
+var Allwinner = &Board{
+	Name: "Allwinner",
+	Pins: allwinnerPins(),
+}
+
+// withNames adds the names prefix0 to prefix<n-1> for the pins 0 to n-1 to
+// pins.
+func withNames(pins map[string]int, prefix string, n int) map[string]int {
+	for i := 0; i < n; i++ {
+		pins[prefix+strconv.Itoa(i)] = i
+	}
+	return pins
+}
+
+// allwinnerPins returns the pins of the ports A to L.
+func allwinnerPins() map[string]int {
+	pins := make(map[string]int)
+	for port := 'A'; port <= 'L'; port++ {
+		for i := 0; i < 32; i++ {
+			pins[fmt.Sprintf("P%c%d", port, i)] = int(port-'A')*32 + i
+		}
+	}
+	return pins
+}
+
+// board holds the current *Board. It is accessed atomically.
+var board atomic.Value
+
+func init() {
+	board.Store(RaspberryPi)
+}
+
+// SetBoard sets the board used to look up the pin names, e.g. by
+// servo.NewPin() and by the "header" of a ServoConfig. If b is nil, the
+// default servo.RaspberryPi is restored.
+func SetBoard(b *Board) {
+	if b == nil {
+		b = RaspberryPi
+	}
+	board.Store(b)
+}
+
+// CurrentBoard returns the board set by servo.SetBoard().
+func CurrentBoard() *Board {
+	return board.Load().(*Board)
+}
+
+// LookupPin returns the pin number of the pin name on the current board.
+func LookupPin(name string) (int, error) {
+	return CurrentBoard().Pin(name)
+}
+
+// NewPin creates a new Servo as servo.New() does, connected at the pin name
+// of the current board, e.g. "P9_14" on a BeagleBone. It returns an error if
+// the board has no such pin.
+func NewPin(name string) (*Servo, error) {
+	pin, err := LookupPin(name)
+	if err != nil {
+		return nil, err
+	}
+	return New(pin), nil
+}
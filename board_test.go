@@ -0,0 +1,72 @@
+// +build !live
+
+package servo
+
+import "testing"
+
+func TestBoard_Pin(t *testing.T) {
+	tests := []struct {
+		board *Board
+		name  string
+		want  int
+	}{
+		{RaspberryPi, "GPIO17", 17},
+		{RaspberryPi, "pin12", 18},
+		{RaspberryPi, "27", 27},
+		{BeagleBone, "P9_14", 50},
+		{BeagleBone, "p8_19", 22},
+		{Allwinner, "PA12", 12},
+		{Allwinner, "PD14", 110},
+	}
+	for _, tt := range tests {
+		got, err := tt.board.Pin(tt.name)
+		if err != nil {
+			t.Errorf("%s %q: %v", tt.board.Name, tt.name, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s %q: got: %d, want: %d", tt.board.Name, tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := BeagleBone.Pin("GPIO17"); err == nil {
+		t.Error("found a Raspberry Pi pin on a BeagleBone")
+	}
+	if _, err := RaspberryPi.Pin("-1"); err == nil {
+		t.Error("found a negative pin")
+	}
+}
+
+func TestSetBoard(t *testing.T) {
+	SetBoard(BeagleBone)
+	defer SetBoard(nil)
+
+	s, err := NewPin("P9_16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Pin(), 51; got != want {
+		t.Errorf("wrong pin, got: %d, want: %d", got, want)
+	}
+
+	sc := defaultServoConfig()
+	sc.Name = "jaw"
+	sc.Header = "P9_14"
+	s, err = sc.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := s.Pin(), 50; got != want {
+		t.Errorf("wrong pin from the config, got: %d, want: %d", got, want)
+	}
+
+	sc.Header = "P10_1"
+	if ps := validateConfig(&Config{Servos: []ServoConfig{sc}}, func() bool { return true }); len(ps) != 1 || ps[0].Field != "pin" {
+		t.Errorf("unknown header was not reported, got: %v", ps)
+	}
+
+	SetBoard(nil)
+	if CurrentBoard() != RaspberryPi {
+		t.Error("default board was not restored")
+	}
+}
@@ -0,0 +1,296 @@
+package servo
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	// pulsePeriod is the default pwm period of pi-blaster (cycle time of
+	// 10ms). MinPulse and MaxPulse are fractions of this period, whatever
+	// the backend or the frequency of pi-blaster.
+	pulsePeriod = 10 * time.Millisecond
+
+	// minSafePulse and maxSafePulse are the limits of a plausible servo
+	// pulse. Pulses outside this range can burn out servos or confuse ESCs.
+	minSafePulse = 200 * time.Microsecond
+	maxSafePulse = 3000 * time.Microsecond
+)
+
+// pulseWidth converts a pwm pulse to its duration.
+func pulseWidth(p float64) time.Duration {
+	return time.Duration(p * float64(pulsePeriod))
+}
+
+// pulseFraction converts a duration to a pwm pulse.
+func pulseFraction(d time.Duration) float64 {
+	return float64(d) / float64(pulsePeriod)
+}
+
+// checkPulses returns an error if the calibration is outside the safe pulse
+// range, unless AllowUnsafePulse is set.
+func (s *Servo) checkPulses(c Calibration) error {
+	if s.AllowUnsafePulse {
+		return nil
+	}
+
+	pulses := []struct {
+		name  string
+		value float64
+	}{
+		{"MinPulse", c.MinPulse},
+		{"MaxPulse", c.MaxPulse},
+	}
+	for _, p := range pulses {
+		w := pulseWidth(p.value)
+		if w < minSafePulse || w > maxSafePulse {
+			return s.wrap(fmt.Errorf("%w: %s %.4f (%v) is outside the safe range %v-%v, set AllowUnsafePulse to override",
+				ErrOutOfRange, p.name, p.value, w, minSafePulse, maxSafePulse))
+		}
+	}
+
+	return nil
+}
+
+// clampPulse clamps a pwm pulse to the safe range, unless AllowUnsafePulse is
+// set.
+func (s *Servo) clampPulse(p float64) float64 {
+	if s.AllowUnsafePulse {
+		return clamp(p, 0, 1)
+	}
+	return clamp(p, pulseFraction(minSafePulse), pulseFraction(maxSafePulse))
+}
+
+// Calibration holds the pulse end points of a servo.
+type Calibration struct {
+	// MinPulse is the pwm pulse of the servo at 0 degrees.
+	MinPulse float64 `json:"min_pulse"`
+	// MaxPulse is the pwm pulse of the servo at its Range, 180 degrees by
+	// default.
+	MaxPulse float64 `json:"max_pulse"`
+}
+
+// PulseWidths returns the calibration with the pulse end points of the given
+// widths, e.g. from a datasheet:
+//
+//	s.Calibrate(servo.PulseWidths(500*time.Microsecond, 2500*time.Microsecond))
+func PulseWidths(min, max time.Duration) Calibration {
+	return Calibration{
+		MinPulse: pulseFraction(min),
+		MaxPulse: pulseFraction(max),
+	}
+}
+
+// Widths returns the pulse widths of the end points of the calibration.
+func (c Calibration) Widths() (min, max time.Duration) {
+	return pulseWidth(c.MinPulse), pulseWidth(c.MaxPulse)
+}
+
+// Calibration returns the current pulse end points of the servo.
+func (s *Servo) Calibration() Calibration {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return Calibration{
+		MinPulse: s.MinPulse,
+		MaxPulse: s.MaxPulse,
+	}
+}
+
+// CalibrationPoint is a point of the calibration table of a servo.
+type CalibrationPoint struct {
+	// Angle is the raw angle of the servo, strictly between 0 and its Range,
+	// regardless of its Flags.
+	Angle float64 `json:"angle"`
+	// Pulse is the pwm pulse that drives the servo to Angle.
+	Pulse float64 `json:"pulse"`
+}
+
+// sortedPoints returns a copy of the points sorted by angle, or nil if there
+// are no points.
+func sortedPoints(points []CalibrationPoint) []CalibrationPoint {
+	if len(points) == 0 {
+		return nil
+	}
+	sorted := append([]CalibrationPoint(nil), points...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].Angle < sorted[j].Angle
+	})
+	return sorted
+}
+
+// checkTable returns an error if a point of the table is not strictly between
+// 0 and the Range of the servo, if the pulses of the sorted points do not
+// strictly follow the direction from MinPulse to MaxPulse, which would turn
+// the servo back, or if a pulse is outside the safe range, unless
+// AllowUnsafePulse is set.
+func (s *Servo) checkTable(c Calibration, points []CalibrationPoint) error {
+	r := s.fullRange()
+	last := CalibrationPoint{Angle: 0, Pulse: c.MinPulse}
+	for _, p := range sortedPoints(points) {
+		if p.Angle <= 0 || p.Angle >= r {
+			return s.wrap(fmt.Errorf("%w: calibration point at %.2f degrees is not between 0 and %.2f", ErrOutOfRange, p.Angle, r))
+		}
+		if p.Angle == last.Angle || (p.Pulse-last.Pulse)*(c.MaxPulse-c.MinPulse) <= 0 ||
+			(p.Pulse-c.MaxPulse)*(c.MaxPulse-c.MinPulse) >= 0 {
+			return s.wrap(fmt.Errorf("%w: calibration point at %.2f degrees (%.4f) does not follow the direction from %.4f to %.4f",
+				ErrOutOfRange, p.Angle, p.Pulse, c.MinPulse, c.MaxPulse))
+		}
+		if w := pulseWidth(p.Pulse); !s.AllowUnsafePulse && (w < minSafePulse || w > maxSafePulse) {
+			return s.wrap(fmt.Errorf("%w: calibration point at %.2f degrees %.4f (%v) is outside the safe range %v-%v, set AllowUnsafePulse to override",
+				ErrOutOfRange, p.Angle, p.Pulse, w, minSafePulse, maxSafePulse))
+		}
+		last = p
+	}
+
+	return nil
+}
+
+// interpolate returns the pwm pulse of the raw angle p, linearly between the
+// end points at 0 and end degrees and the sorted points of the table.
+func interpolate(p, end, minPulse, maxPulse float64, points []CalibrationPoint) float64 {
+	last := CalibrationPoint{Angle: 0, Pulse: minPulse}
+	for _, pt := range points {
+		if p <= pt.Angle {
+			return remap(p, last.Angle, pt.Angle, last.Pulse, pt.Pulse)
+		}
+		last = pt
+	}
+	return remap(p, last.Angle, end, last.Pulse, maxPulse)
+}
+
+// invert returns the raw angle of the pwm pulse, the inverse of interpolate.
+// The pulses beyond the end points are extrapolated from the end segments.
+func invert(pulse, end, minPulse, maxPulse float64, points []CalibrationPoint) float64 {
+	dir := maxPulse - minPulse
+	last := CalibrationPoint{Angle: 0, Pulse: minPulse}
+	for _, pt := range points {
+		if (pulse-pt.Pulse)*dir <= 0 {
+			return remap(pulse, last.Pulse, pt.Pulse, last.Angle, pt.Angle)
+		}
+		last = pt
+	}
+	return remap(pulse, last.Pulse, maxPulse, last.Angle, end)
+}
+
+// CalibrationTable returns the points of the calibration table of the servo,
+// sorted by angle, or nil if the calibration is linear.
+func (s *Servo) CalibrationTable() []CalibrationPoint {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return sortedPoints(s.points)
+}
+
+// SetCalibrationTable corrects the calibration of non-linear servos, e.g. for
+// camera gimbals or laser pointers. The pulse of an angle is interpolated
+// linearly between the points, sorted by angle, and the pulse end points,
+// instead of between MinPulse and MaxPulse only. Calling it without points
+// makes the calibration linear again.
+//
+// It returns an error if a point is not strictly between 0 and the Range,
+// if the pulses do not follow the direction from MinPulse to MaxPulse, or if
+// a pulse is outside the safe range (see Servo.AllowUnsafePulse).
+func (s *Servo) SetCalibrationTable(points ...CalibrationPoint) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.calibrate(Calibration{MinPulse: s.MinPulse, MaxPulse: s.MaxPulse}, points)
+}
+
+// PulseAngle returns the angle of the servo, adjusted for its Flags, that is
+// driven by a pulse of the given width. It is the inverse of the calibration,
+// e.g. to convert the position read back from a bus servo.
+func (s *Servo) PulseAngle(width time.Duration) float64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.MinPulse == s.MaxPulse {
+		return s.adjust(0)
+	}
+	r := s.fullRange()
+	p := invert(pulseFraction(width), r, s.MinPulse, s.MaxPulse, s.points)
+	if s.Flags.is(Reversed) {
+		p = r - p
+	}
+	return s.adjust(p)
+}
+
+// Calibrate sets the pulse end points of the servo. Unlike setting MinPulse
+// and MaxPulse directly, Calibrate is concurrent-safe and can be used while
+// the servo is connected. It returns an error if the pulses are outside the
+// safe range (see Servo.AllowUnsafePulse), or if the calibration table of the
+// servo does not fit the new end points (see Servo.SetCalibrationTable()).
+func (s *Servo) Calibrate(c Calibration) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.calibrate(c, s.points)
+}
+
+// calibrate sets the pulse end points and the calibration table of the servo.
+// The lock must be held.
+func (s *Servo) calibrate(c Calibration, points []CalibrationPoint) error {
+	if err := s.checkPulses(c); err != nil {
+		return err
+	}
+	if err := s.checkTable(c, points); err != nil {
+		return err
+	}
+
+	s.MinPulse = c.MinPulse
+	s.MaxPulse = c.MaxPulse
+	s.points = sortedPoints(points)
+	// Force the manager to write the new pulse.
+	s.idle = false
+	s.publish()
+	_blaster.wakeUp()
+
+	return nil
+}
+
+// calibrationKey is the Store key of the calibrations.
+const calibrationKey = "calibration"
+
+// calibrationFile is the format of the calibrations saved in a Store.
+type calibrationFile struct {
+	Version      int                    `json:"version"`
+	Calibrations map[string]Calibration `json:"calibrations"`
+}
+
+// LoadCalibrations reads the calibrations saved in the store, indexed by servo
+// name. Calibrations saved by an older servo.SchemaVersion are migrated. If
+// nothing was saved, an empty map is returned.
+func LoadCalibrations(st Store) (map[string]Calibration, error) {
+	calibrations := make(map[string]Calibration)
+
+	data, err := st.Load(calibrationKey)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return calibrations, nil
+	}
+
+	if data, err = migrate("calibrations", data, calibrationMigrations); err != nil {
+		return nil, err
+	}
+	f := calibrationFile{Calibrations: calibrations}
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, err
+	}
+
+	return f.Calibrations, nil
+}
+
+// SaveCalibrations saves the calibrations, indexed by servo name, in the store.
+func SaveCalibrations(st Store, calibrations map[string]Calibration) error {
+	data, err := json.MarshalIndent(calibrationFile{SchemaVersion, calibrations}, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return st.Save(calibrationKey, data)
+}
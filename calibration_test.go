@@ -0,0 +1,157 @@
+// +build !live
+
+package servo
+
+import (
+	"math"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCalibrations(t *testing.T) {
+	st := NewMemoryStore()
+
+	got, err := LoadCalibrations(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 {
+		t.Errorf("empty store should be empty, got: %v", got)
+	}
+
+	want := map[string]Calibration{
+		"pan":  {MinPulse: 0.06, MaxPulse: 0.24},
+		"tilt": {MinPulse: 0.05, MaxPulse: 0.23},
+	}
+	if err := SaveCalibrations(st, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err = LoadCalibrations(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("calibrations do not match\ngot:\n%v\nwant:\n%v", got, want)
+	}
+}
+
+func TestServo_CheckPulses(t *testing.T) {
+	// map[input]ok
+	tests := map[Calibration]bool{
+		{MinPulse: 0.05, MaxPulse: 0.25}:  true,
+		{MinPulse: 0.02, MaxPulse: 0.3}:   true,
+		{MinPulse: 0.25, MaxPulse: 0.05}:  true,
+		{MinPulse: 0.005, MaxPulse: 0.25}: false,
+		{MinPulse: 0.05, MaxPulse: 2.5}:   false,
+	}
+
+	s := New(99)
+	for input, ok := range tests {
+		err := s.Calibrate(input)
+		if (err == nil) != ok {
+			t.Errorf("Servo.Calibrate(%+v) -> got: %v, want ok: %t", input, err, ok)
+		}
+	}
+
+	s.MaxPulse = 25
+	if err := s.Connect(); err == nil {
+		s.Close()
+		t.Error("Servo.Connect() accepted an unsafe pulse")
+	}
+
+	s.AllowUnsafePulse = true
+	if err := s.Calibrate(Calibration{MinPulse: 0.001, MaxPulse: 0.9}); err != nil {
+		t.Errorf("AllowUnsafePulse was not respected, got: %v", err)
+	}
+}
+
+func TestServo_PulseAngle(t *testing.T) {
+	s := New(99)
+	s.Flags = Centered
+
+	// map[width]want
+	tests := map[time.Duration]float64{
+		500 * time.Microsecond:  -90,
+		1500 * time.Microsecond: 0,
+		2500 * time.Microsecond: 90,
+	}
+	for width, want := range tests {
+		if got := s.PulseAngle(width); math.Abs(got-want) > 1e-9 {
+			t.Errorf("PulseAngle(%v) -> got: %.2f, want: %.2f", width, got, want)
+		}
+	}
+}
+
+func TestServo_SetCalibrationTable(t *testing.T) {
+	s := New(99)
+	points := []CalibrationPoint{
+		{Angle: 135, Pulse: 0.2},
+		{Angle: 45, Pulse: 0.08},
+	}
+	if err := s.SetCalibrationTable(points...); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.CalibrationTable(); len(got) != 2 || got[0].Angle != 45 {
+		t.Errorf("table was not sorted, got: %+v", got)
+	}
+
+	// map[angle]pulse
+	tests := map[float64]float64{
+		0:     0.05,
+		22.5:  0.065,
+		45:    0.08,
+		90:    0.14,
+		157.5: 0.225,
+		180:   0.25,
+	}
+	for angle, want := range tests {
+		if got := float64(s.pulse(angle)); math.Abs(got-want) > 1e-9 {
+			t.Errorf("pulse(%.2f) -> got: %.4f, want: %.4f", angle, got, want)
+		}
+		if got := s.PulseAngle(pulseWidth(want)); math.Abs(got-angle) > 1e-6 {
+			t.Errorf("PulseAngle(%v) -> got: %.2f, want: %.2f", pulseWidth(want), got, angle)
+		}
+	}
+
+	bad := map[string][]CalibrationPoint{
+		"end point":  {{Angle: 180, Pulse: 0.2}},
+		"duplicated": {{Angle: 90, Pulse: 0.1}, {Angle: 90, Pulse: 0.2}},
+		"turns back": {{Angle: 45, Pulse: 0.1}, {Angle: 90, Pulse: 0.09}},
+		"beyond end": {{Angle: 90, Pulse: 0.26}},
+	}
+	for name, points := range bad {
+		if err := s.SetCalibrationTable(points...); err == nil {
+			t.Errorf("%s: table was accepted: %+v", name, points)
+		}
+	}
+	if got := s.CalibrationTable(); len(got) != 2 {
+		t.Errorf("invalid table was applied, got: %+v", got)
+	}
+
+	if err := s.Calibrate(Calibration{MinPulse: 0.1, MaxPulse: 0.25}); err == nil {
+		t.Error("Calibrate() accepted end points that do not fit the table")
+	}
+
+	if err := s.SetCalibrationTable(); err != nil || s.CalibrationTable() != nil {
+		t.Errorf("table was not cleared, got: %+v, %v", s.CalibrationTable(), err)
+	}
+	if got := float64(s.pulse(90)); math.Abs(got-0.15) > 1e-9 {
+		t.Errorf("calibration is not linear, got: %.4f, want: 0.1500", got)
+	}
+}
+
+func TestPulseWidths(t *testing.T) {
+	c := PulseWidths(500*time.Microsecond, 2500*time.Microsecond)
+	if !approx(c.MinPulse, 0.05) || !approx(c.MaxPulse, 0.25) {
+		t.Errorf("wrong calibration, got: %+v, want: {MinPulse:0.05 MaxPulse:0.25}", c)
+	}
+	if min, max := c.Widths(); min != 500*time.Microsecond || max != 2500*time.Microsecond {
+		t.Errorf("wrong widths, got: %v, %v, want: 500µs, 2.5ms", min, max)
+	}
+
+	sc := ServoConfig{MinPulse: 0.05, MaxPulse: 0.25, MinPulseWidth: 1000, MaxPulseWidth: 2000}
+	if got := sc.calibration(); !approx(got.MinPulse, 0.1) || !approx(got.MaxPulse, 0.2) {
+		t.Errorf("widths were not used, got: %+v, want: {MinPulse:0.1 MaxPulse:0.2}", got)
+	}
+}
@@ -0,0 +1,43 @@
+package servo
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// SetCanary turns the canary mode on or off. It can be changed on-the-fly.
+//
+// In canary mode, all the functions of the package behave as usual: servos
+// connect, move at their speed, Wait() returns when the servos reach their
+// targets, and events are sent. However, nothing is written to pi-blaster, so
+// the physical servos do not move. This is useful to validate a new
+// choreography on a live system.
+//
+// CAUTION: When the canary mode is turned off, every connected servo jumps to
+// its simulated position, since its current pwm is written again right away,
+// even if it is idle.
+//
+// servo.Close() does not release the pins in canary mode either, so the
+// physical servos keep the pulses written before the canary mode, as nothing
+// at all is written while it is on. Turn the canary mode off before
+// servo.Close() to release them.
+func SetCanary(on bool) {
+	var v int32
+	if on {
+		v = 1
+	}
+	if atomic.SwapInt32(&_blaster.canary, v) == v {
+		return
+	}
+	if !on {
+		_blaster.rewrite()
+	}
+
+	debugf("canary mode set to %t", on)
+	_blaster.bus.publish(Event{Type: EventCanary, Time: time.Now()})
+}
+
+// Canary checks if the canary mode is on.
+func Canary() bool {
+	return atomic.LoadInt32(&_blaster.canary) != 0
+}
@@ -0,0 +1,83 @@
+// +build !live
+
+package servo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCanary(t *testing.T) {
+	b := useBlaster(t)
+	out := new(syncBuffer)
+	b.pi.sink = out
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	sub := Subscribe(10, EventCanary|EventFinish)
+	defer sub.Close()
+
+	SetCanary(true)
+	SetCanary(true)
+	if !Canary() {
+		t.Fatal("canary mode was not set")
+	}
+
+	s.moveTo(10)
+	s.Wait()
+	Rate(time.Millisecond)
+	time.Sleep(50 * time.Millisecond)
+
+	if s.Position() != 10 {
+		t.Errorf("servo was not simulated in canary mode, got: %.2f, want: %.2f", s.Position(), 10.0)
+	}
+	if out.String() != "" {
+		t.Errorf("data was written in canary mode, got: %q", out.String())
+	}
+
+	want := []EventType{EventCanary, EventFinish}
+	for _, w := range want {
+		if e := <-sub.C; e.Type != w {
+			t.Errorf("wrong event, got: %v, want: %v", e.Type, w)
+		}
+	}
+
+	SetCanary(false)
+	s.moveTo(20)
+	s.Wait()
+	time.Sleep(50 * time.Millisecond)
+	if out.String() == "" {
+		t.Error("data was not written after canary mode")
+	}
+}
+
+func TestCanary_off(t *testing.T) {
+	b := useBlaster(t)
+	out := new(syncBuffer)
+	b.pi.sink = out
+	Rate(time.Millisecond)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	SetCanary(true)
+	defer SetCanary(false)
+	s.SetPosition(90)
+	s.Wait()
+	time.Sleep(20 * time.Millisecond)
+
+	// The idle servo is written right away, without a new move.
+	SetCanary(false)
+	time.Sleep(20 * time.Millisecond)
+	if got := out.String(); !strings.Contains(got, "99=0.150000") {
+		t.Errorf("simulated position was not written, got: %q", got)
+	}
+}
@@ -0,0 +1,80 @@
+package servo
+
+import (
+	"sync/atomic"
+	"time"
+)
+
+// clockOffset is the offset of the playback clock from the system clock in
+// nanoseconds. It is accessed atomically.
+var clockOffset int64
+
+// Now returns the current time of the playback clock of the servo package,
+// which is the system clock plus the offset set by SetOffset() or SyncTo().
+// The playback clock is used by MoveAt(), so external systems (lighting,
+// audio) can align their cues to the same clock as the servos.
+func Now() time.Time {
+	return time.Now().Add(Offset())
+}
+
+// Offset returns the offset of the playback clock from the system clock.
+func Offset() time.Duration {
+	return time.Duration(atomic.LoadInt64(&clockOffset))
+}
+
+// SetOffset sets the offset of the playback clock from the system clock.
+func SetOffset(d time.Duration) {
+	atomic.StoreInt64(&clockOffset, int64(d))
+}
+
+// SyncTo sets the playback clock to the reference time t, e.g. the time
+// received from an NTP server or from the master of a multi-Pi rig, so
+// Now() returns t at this instant.
+func SyncTo(t time.Time) {
+	SetOffset(t.Sub(time.Now()))
+}
+
+// scheduled is the Waiter of a move scheduled by MoveAt.
+type scheduled struct {
+	servo   *Servo
+	started chan struct{}
+	done    doneCache
+}
+
+// Wait waits for the scheduled move to start and then for the servo to finish
+// moving.
+func (w *scheduled) Wait() {
+	<-w.started
+	w.servo.Wait()
+}
+
+// Done implements the Waiter interface. Once the move started, it is the
+// channel of Servo.Done().
+func (w *scheduled) Done() <-chan struct{} {
+	select {
+	case <-w.started:
+		return w.servo.Done()
+	default:
+	}
+	return w.done.get(w.Wait)
+}
+
+// MoveAt schedules the servo to move to target at the time t of the playback
+// clock (see servo.Now()). If t is in the past, the servo moves immediately.
+// The delay is computed when MoveAt is called, so changing the offset of the
+// playback clock does not affect moves that are already scheduled.
+//
+// The returned Waiter waits for the scheduled move to start and finish.
+func (s *Servo) MoveAt(t time.Time, target float64) (wait Waiter) {
+	w := &scheduled{
+		servo:   s,
+		started: make(chan struct{}),
+	}
+
+	time.AfterFunc(t.Sub(Now()), func() {
+		s.moveTo(target)
+		close(w.started)
+	})
+
+	return w
+}
@@ -0,0 +1,50 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestClock(t *testing.T) {
+	defer SetOffset(0)
+
+	ref := time.Date(2020, 8, 13, 12, 0, 0, 0, time.UTC)
+	SyncTo(ref)
+	if d := Now().Sub(ref); d < 0 || d > 10*time.Millisecond {
+		t.Errorf("clock was not synced, got: %v, want: %v", Now(), ref)
+	}
+
+	SetOffset(time.Hour)
+	if d := Now().Sub(time.Now()); d < time.Hour-time.Millisecond || d > time.Hour+time.Millisecond {
+		t.Errorf("offset was not set, got: %v, want: %v", d, time.Hour)
+	}
+}
+
+func TestServo_MoveAt(t *testing.T) {
+	useBlaster(t)
+	defer SetOffset(0)
+	SetOffset(-time.Hour)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	start := time.Now()
+	w := s.MoveAt(Now().Add(100*time.Millisecond), 10)
+	if s.Position() != 0 || !s.isIdle() {
+		t.Error("servo moved before the scheduled time")
+	}
+	w.Wait()
+	elapsed := time.Since(start)
+
+	if s.Position() != 10 {
+		t.Errorf("servo did not move, got: %.2f, want: %.2f", s.Position(), 10.0)
+	}
+	if elapsed < 100*time.Millisecond || elapsed > 200*time.Millisecond {
+		t.Errorf("scheduled move took %v, want: about %v", elapsed, 130*time.Millisecond)
+	}
+}
@@ -0,0 +1,14 @@
+// Package examples holds small programs that show the main features of the
+// servo package:
+//
+//	sweep     sweeps a servo back and forth
+//	pantilt   makes a pan-tilt head follow a moving target
+//	show      plays a timeline on two servos
+//
+// By default, the programs run in canary mode, so nothing is written to
+// pi-blaster and the simulated positions are printed instead. Use the
+// -hardware flag to move the real servos:
+//
+//	go run ./cmd/examples/sweep
+//	go run ./cmd/examples/sweep -hardware -pin 17
+package examples
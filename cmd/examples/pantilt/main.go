@@ -0,0 +1,52 @@
+// Command pantilt makes a pan-tilt head follow a target moving in a circle.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"math"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+func main() {
+	hardware := flag.Bool("hardware", false, "move the real servos instead of simulating them")
+	panPin := flag.Int("pan", 17, "GPIO `pin` of the pan servo")
+	tiltPin := flag.Int("tilt", 18, "GPIO `pin` of the tilt servo")
+	duration := flag.Duration("duration", 10*time.Second, "how long to follow the target")
+	flag.Parse()
+
+	servo.SetCanary(!*hardware)
+	defer servo.Close()
+
+	pan, tilt := servo.New(*panPin), servo.New(*tiltPin)
+	pan.Name, tilt.Name = "pan", "tilt"
+	for _, s := range []*servo.Servo{pan, tilt} {
+		s.Flags = servo.Centered
+		if err := s.Connect(); err != nil {
+			log.Fatal(err)
+		}
+		defer s.Close()
+	}
+
+	// The target goes around a circle of 30 degrees every 4 seconds. The
+	// head follows it by retargeting the servos on every frame; each servo
+	// moves at its own speed towards the latest target.
+	tick := time.NewTicker(50 * time.Millisecond)
+	defer tick.Stop()
+	start := time.Now()
+	for now := range tick.C {
+		t := now.Sub(start)
+		if t > *duration {
+			break
+		}
+		angle := 2 * math.Pi * t.Seconds() / 4
+		pan.MoveTo(30 * math.Cos(angle))
+		tilt.MoveTo(30 * math.Sin(angle))
+
+		fmt.Printf("target=(%6.2f, %6.2f) head=(%6.2f, %6.2f)\n",
+			30*math.Cos(angle), 30*math.Sin(angle), pan.Position(), tilt.Position())
+	}
+}
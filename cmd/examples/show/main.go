@@ -0,0 +1,57 @@
+// Command show plays a timeline on the jaw and neck of a character.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+func main() {
+	hardware := flag.Bool("hardware", false, "move the real servos instead of simulating them")
+	jawPin := flag.Int("jaw", 17, "GPIO `pin` of the jaw servo")
+	neckPin := flag.Int("neck", 18, "GPIO `pin` of the neck servo")
+	flag.Parse()
+
+	servo.SetCanary(!*hardware)
+	defer servo.Close()
+
+	jaw, neck := servo.New(*jawPin), servo.New(*neckPin)
+	jaw.Name, neck.Name = "jaw", "neck"
+	for _, s := range []*servo.Servo{jaw, neck} {
+		if err := s.Connect(); err != nil {
+			log.Fatal(err)
+		}
+		defer s.Close()
+	}
+	neck.SetSpeed(0.3)
+
+	// The neck turns while the jaw "talks".
+	tl := servo.Timeline{
+		{At: 0, Servo: neck, Target: 120},
+	}
+	for i := 0; i < 8; i++ {
+		at := time.Duration(i) * 400 * time.Millisecond
+		tl = append(tl,
+			servo.Cue{At: at, Servo: jaw, Target: 30},
+			servo.Cue{At: at + 200*time.Millisecond, Servo: jaw, Target: 0},
+		)
+	}
+	tl = append(tl, servo.Cue{At: 3500 * time.Millisecond, Servo: neck, Target: 60})
+
+	sub := servo.Subscribe(32, servo.EventMove|servo.EventFinish)
+	defer sub.Close()
+	go func() {
+		for e := range sub.C {
+			fmt.Println(e)
+		}
+	}()
+
+	fmt.Printf("playing %v of cues\n", tl.Duration())
+	if err := tl.Play(nil); err != nil {
+		log.Fatal(err)
+	}
+}
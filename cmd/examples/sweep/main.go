@@ -0,0 +1,42 @@
+// Command sweep sweeps a servo back and forth.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+func main() {
+	hardware := flag.Bool("hardware", false, "move the real servo instead of simulating it")
+	pin := flag.Int("pin", 17, "GPIO `pin` of the servo")
+	passes := flag.Int("passes", 3, "number of sweeps")
+	speed := flag.Float64("speed", 0.5, "`speed` of the servo, from 0.0 to 1.0")
+	flag.Parse()
+
+	servo.SetCanary(!*hardware)
+	defer servo.Close()
+
+	s := servo.New(*pin)
+	if err := s.Connect(); err != nil {
+		log.Fatal(err)
+	}
+	defer s.Close()
+	s.SetSpeed(*speed)
+
+	sub := servo.Subscribe(16, servo.EventPosition|servo.EventFinish, s).Sample(100 * time.Millisecond)
+	defer sub.Close()
+	go func() {
+		for e := range sub.C {
+			fmt.Println(e)
+		}
+	}()
+
+	for i := 0; i < *passes; i++ {
+		s.MoveTo(180).Wait()
+		s.MoveTo(0).Wait()
+	}
+}
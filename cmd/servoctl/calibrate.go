@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/cgxeiji/servo"
+)
+
+// calibrate jogs a servo and writes the chosen end points to the calibration
+// file in the store directory.
+func calibrate(args []string) error {
+	fs := newFlagSet("calibrate")
+	pin := fs.Int("pin", -1, "GPIO `pin` of the servo")
+	name := fs.String("name", "", "`name` of the servo in the calibration file (default: Servo<pin>)")
+	dir := fs.String("dir", ".", "`directory` of the calibration file")
+	fs.Parse(args)
+
+	if *pin < 0 {
+		fs.Usage()
+		return fmt.Errorf("missing -pin")
+	}
+
+	store := servo.FileStore{Dir: *dir}
+	calibrations, err := servo.LoadCalibrations(store)
+	if err != nil {
+		return err
+	}
+
+	s := servo.New(*pin)
+	if *name != "" {
+		s.Name = *name
+	}
+	if c, ok := calibrations[s.Name]; ok {
+		if err := s.Calibrate(c); err != nil {
+			return err
+		}
+	}
+	if err := s.Connect(); err != nil {
+		return err
+	}
+	defer s.Close()
+
+	fmt.Printf("calibrating %v\n", s)
+	fmt.Println("left/right: jog, up/down: step size, enter: accept, q: quit")
+
+	restore, err := rawTerminal()
+	if err != nil {
+		return err
+	}
+	c, err := servo.Jog(s, os.Stdin, os.Stdout)
+	restore()
+	if err != nil {
+		return err
+	}
+
+	calibrations[s.Name] = c
+	if err := servo.SaveCalibrations(store, calibrations); err != nil {
+		return err
+	}
+	fmt.Printf("saved %q to %s: min_pulse=%.4f max_pulse=%.4f\n", s.Name, *dir, c.MinPulse, c.MaxPulse)
+
+	return nil
+}
+
+// rawTerminal puts the terminal in raw mode and returns a function to restore
+// it. It depends on stty.
+func rawTerminal() (restore func(), err error) {
+	stty := func(args ...string) (string, error) {
+		cmd := exec.Command("stty", args...)
+		cmd.Stdin = os.Stdin
+		out, err := cmd.Output()
+		return strings.TrimSpace(string(out)), err
+	}
+
+	state, err := stty("-g")
+	if err != nil {
+		return nil, fmt.Errorf("could not read terminal state: %v", err)
+	}
+	if _, err := stty("raw", "-echo"); err != nil {
+		return nil, fmt.Errorf("could not set terminal to raw mode: %v", err)
+	}
+
+	return func() { stty(state) }, nil
+}
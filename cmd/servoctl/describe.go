@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/cgxeiji/servo"
+)
+
+// describe prints the summary of a servo of a configuration file without
+// connecting it.
+func describe(args []string) error {
+	fs := newFlagSet("describe")
+	file := fs.String("config", "servo.json", "configuration `file`")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: servoctl describe [flags] <name>\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("missing servo name")
+	}
+	name := fs.Arg(0)
+
+	c, err := servo.LoadConfig(*file)
+	if err != nil {
+		return err
+	}
+	sc, ok := c.Servo(name)
+	if !ok {
+		return fmt.Errorf("%s: no servo named %q", *file, name)
+	}
+
+	s, err := sc.New()
+	if err != nil {
+		return err
+	}
+	fmt.Print(s.Describe())
+
+	return nil
+}
@@ -0,0 +1,71 @@
+// Command servoctl is a small command line tool to operate servos connected
+// to pi-blaster.
+//
+// Usage:
+//
+//	servoctl <command> [flags]
+//
+// The commands are:
+//
+//	calibrate   jog a servo with the arrow keys and store its end points
+//	describe    print the summary of a servo of a configuration file
+//	scan        list the PCA9685 boards found on an I2C bus
+//	validate    check a configuration file without connecting anything
+//	verify      check a timeline against the motion envelope offline
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/cgxeiji/servo"
+)
+
+// command is a servoctl subcommand.
+type command struct {
+	name, usage string
+	run         func(args []string) error
+}
+
+var commands = []command{
+	{"calibrate", "jog a servo with the arrow keys and store its end points", calibrate},
+	{"describe", "print the summary of a servo of a configuration file", describe},
+	{"scan", "list the PCA9685 boards found on an I2C bus", scan},
+	{"validate", "check a configuration file without connecting anything", validate},
+	{"verify", "check a timeline against the motion envelope offline", verify},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "usage: servoctl <command> [flags]\n\ncommands:\n")
+	for _, c := range commands {
+		fmt.Fprintf(os.Stderr, "  %-12s%s\n", c.name, c.usage)
+	}
+	os.Exit(2)
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	for _, c := range commands {
+		if c.name != os.Args[1] {
+			continue
+		}
+		err := c.run(os.Args[2:])
+		servo.Close()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "servoctl:", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	usage()
+}
+
+// newFlagSet creates a flag set for the command name.
+func newFlagSet(name string) *flag.FlagSet {
+	return flag.NewFlagSet("servoctl "+name, flag.ExitOnError)
+}
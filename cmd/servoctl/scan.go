@@ -0,0 +1,29 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/cgxeiji/servo/pca9685"
+)
+
+// scan lists the PCA9685 boards found on an I2C bus.
+func scan(args []string) error {
+	fs := newFlagSet("scan")
+	bus := fs.String("bus", "/dev/i2c-1", "I2C bus `device`")
+	fs.Parse(args)
+
+	boards, err := pca9685.Scan(*bus)
+	if err != nil {
+		return err
+	}
+	if len(boards) == 0 {
+		fmt.Printf("%s: no PCA9685 boards found\n", *bus)
+		return nil
+	}
+
+	for _, addr := range boards {
+		fmt.Printf("%s: PCA9685 at 0x%02x, channels 0-%d\n", *bus, addr, pca9685.Channels-1)
+	}
+
+	return nil
+}
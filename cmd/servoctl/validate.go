@@ -0,0 +1,26 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/cgxeiji/servo"
+)
+
+// validate checks a configuration file without connecting anything.
+func validate(args []string) error {
+	fs := newFlagSet("validate")
+	file := fs.String("config", "servo.json", "configuration `file`")
+	fs.Parse(args)
+
+	c, err := servo.LoadConfig(*file)
+	if err != nil {
+		return err
+	}
+
+	if ps := servo.ValidateConfig(c); ps != nil {
+		return fmt.Errorf("%s has %d problems:\n%v", *file, len(ps), ps)
+	}
+	fmt.Printf("%s: %d servos OK\n", *file, len(c.Servos))
+
+	return nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+// cueFile is a cue of a timeline file.
+type cueFile struct {
+	// At is the time of the cue, e.g. "1.5s".
+	At     string  `json:"at"`
+	Servo  string  `json:"servo"`
+	Target float64 `json:"target"`
+}
+
+// verify simulates a timeline file with the servos of a configuration file
+// and reports the violations of the motion envelope.
+func verify(args []string) error {
+	fs := newFlagSet("verify")
+	file := fs.String("config", "servo.json", "configuration `file`")
+	maxMoving := fs.Int("max-moving", 0, "maximum number of servos moving at the same time (0 to disable)")
+	sync := fs.Duration("sync", 0, "maximum time between the arrivals of the servos cued together (0 to disable)")
+	asJSON := fs.Bool("json", false, "print the report as JSON")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "usage: servoctl verify [flags] <timeline.json>\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return fmt.Errorf("missing timeline file")
+	}
+
+	c, err := servo.LoadConfig(*file)
+	if err != nil {
+		return err
+	}
+	tl, err := loadTimeline(fs.Arg(0), c)
+	if err != nil {
+		return err
+	}
+
+	r := tl.Verify(servo.Envelope{
+		MaxMoving:     *maxMoving,
+		SyncTolerance: *sync,
+	})
+	if *asJSON {
+		e := json.NewEncoder(os.Stdout)
+		e.SetIndent("", "  ")
+		if r == nil {
+			r = servo.Report{}
+		}
+		if err := e.Encode(r); err != nil {
+			return err
+		}
+	}
+	if r != nil {
+		return fmt.Errorf("%s has %d violations:\n%v", fs.Arg(0), len(r), r)
+	}
+	if !*asJSON {
+		fmt.Printf("%s: %d cues OK\n", fs.Arg(0), len(tl))
+	}
+
+	return nil
+}
+
+// loadTimeline reads the timeline file at path, with the servos of the
+// configuration.
+func loadTimeline(path string, c *servo.Config) (servo.Timeline, error) {
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cues []cueFile
+	if err := json.Unmarshal(raw, &cues); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	servos := make(map[string]*servo.Servo)
+	tl := make(servo.Timeline, 0, len(cues))
+	for i, cf := range cues {
+		at, err := time.ParseDuration(cf.At)
+		if err != nil {
+			return nil, fmt.Errorf("%s: cue %d: %v", path, i, err)
+		}
+
+		s, ok := servos[cf.Servo]
+		if !ok {
+			sc, ok := c.Servo(cf.Servo)
+			if !ok {
+				return nil, fmt.Errorf("%s: cue %d: no servo named %q", path, i, cf.Servo)
+			}
+			if s, err = sc.New(); err != nil {
+				return nil, err
+			}
+			servos[cf.Servo] = s
+		}
+
+		tl = append(tl, servo.Cue{At: at, Servo: s, Target: cf.Target})
+	}
+
+	return tl, nil
+}
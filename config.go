@@ -0,0 +1,351 @@
+package servo
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// ServoConfig holds the configuration of a single servo.
+type ServoConfig struct {
+	// Name identifies the servo. Overrides and includes are merged by name.
+	Name string `json:"name"`
+	// Pin is the GPIO pin of the servo.
+	Pin int `json:"pin"`
+	// Header is the name of the pin on the board set by servo.SetBoard(),
+	// e.g. "P9_14". If set, it overrides Pin.
+	Header string `json:"header,omitempty"`
+	// Flags lists the flags of the servo by name: "centered", "normalized",
+	// "reversed" or "radians".
+	Flags []string `json:"flags,omitempty"`
+	// Tags are the tags of the servo. See Servo.AddTags().
+	Tags []Tag `json:"tags,omitempty"`
+	// MinPulse and MaxPulse are the calibration of the servo.
+	MinPulse float64 `json:"min_pulse"`
+	MaxPulse float64 `json:"max_pulse"`
+	// MinPulseWidth and MaxPulseWidth are the calibration of the servo as
+	// pulse widths in microseconds, e.g. 500 and 2500 from a datasheet. If
+	// set, they override MinPulse and MaxPulse.
+	MinPulseWidth float64 `json:"min_pulse_us,omitempty"`
+	MaxPulseWidth float64 `json:"max_pulse_us,omitempty"`
+	// Range is the travel of the servo in degrees from MinPulse to
+	// MaxPulse. If 0, it is 180. See Servo.Range.
+	Range float64 `json:"range,omitempty"`
+	// CalibrationPoints is the calibration table of non-linear servos. See
+	// Servo.SetCalibrationTable().
+	CalibrationPoints []CalibrationPoint `json:"calibration_points,omitempty"`
+	// AllowUnsafePulse allows pulses outside the safe range.
+	AllowUnsafePulse bool `json:"allow_unsafe_pulse,omitempty"`
+	// MinAngle and MaxAngle are the travel limits of the servo, as raw
+	// angles from 0 to Range. If both are 0, the servo is not limited. See
+	// Servo.MinAngle.
+	MinAngle float64 `json:"min_angle,omitempty"`
+	MaxAngle float64 `json:"max_angle,omitempty"`
+	// MaxErrors is the number of consecutive write errors after which the
+	// servo enters the fault state. If 0, the servo never faults by itself.
+	MaxErrors int `json:"max_errors,omitempty"`
+	// Speed is the initial speed of the servo, from 0.0 to 1.0.
+	Speed float64 `json:"speed"`
+	// MaxSpeed is the max speed of the servo in degrees per second. If 0,
+	// it is servo.DefaultMaxSpeed.
+	MaxSpeed float64 `json:"max_speed,omitempty"`
+	// Acceleration and Deceleration limit how fast the servo speeds up and
+	// slows down in degrees per second squared. If 0, they are not
+	// limited. See Servo.SetAcceleration().
+	Acceleration float64 `json:"acceleration,omitempty"`
+	Deceleration float64 `json:"deceleration,omitempty"`
+	// Jerk makes the moves follow S-curves, limiting how fast the
+	// acceleration changes in degrees per second cubed. If 0, the moves do
+	// not follow S-curves. See Servo.SetJerk().
+	Jerk float64 `json:"jerk,omitempty"`
+	// Expo is the exponential response of the targets, from 0.0 (linear)
+	// to 1.0. See Servo.SetExpo().
+	Expo float64 `json:"expo,omitempty"`
+	// Position is the initial position of the servo, adjusted for its Flags.
+	// If nil, the position is not set.
+	Position *float64 `json:"position,omitempty"`
+}
+
+// Config holds the configuration of a set of servos. Use servo.LoadConfig()
+// to read it from a file.
+type Config struct {
+	// Version is the version of the format. See servo.SchemaVersion.
+	Version int           `json:"version"`
+	Servos  []ServoConfig `json:"servos"`
+	// Routes map the inputs of the control surfaces to the servos. See
+	// servo.NewRouter().
+	Routes []RouteConfig `json:"routes,omitempty"`
+}
+
+// Servo returns the configuration of the servo with the given name.
+func (c *Config) Servo(name string) (ServoConfig, bool) {
+	for _, sc := range c.Servos {
+		if sc.Name == name {
+			return sc, true
+		}
+	}
+	return ServoConfig{}, false
+}
+
+// flagNames maps the names used in config files to flags.
+var flagNames = map[string]flag{
+	"centered":   Centered,
+	"normalized": Normalized,
+	"reversed":   Reversed,
+	"radians":    Radians,
+}
+
+// flags parses the flag names of the configuration.
+func (sc ServoConfig) flags() (flag, error) {
+	var f flag
+	for _, name := range sc.Flags {
+		bits, ok := flagNames[strings.ToLower(name)]
+		if !ok {
+			return 0, fmt.Errorf("servo %q: unknown flag %q", sc.Name, name)
+		}
+		f |= bits
+	}
+	return f, nil
+}
+
+// pin returns the pin of the servo, looking up the Header on the current
+// board if set.
+func (sc ServoConfig) pin() (int, error) {
+	if sc.Header == "" {
+		return sc.Pin, nil
+	}
+	pin, err := LookupPin(sc.Header)
+	if err != nil {
+		return 0, fmt.Errorf("servo %q: %v", sc.Name, err)
+	}
+	return pin, nil
+}
+
+// New creates a new Servo with the configuration. The servo still needs to be
+// connected with Servo.Connect().
+func (sc ServoConfig) New() (*Servo, error) {
+	f, err := sc.flags()
+	if err != nil {
+		return nil, err
+	}
+	pin, err := sc.pin()
+	if err != nil {
+		return nil, err
+	}
+
+	s := New(pin)
+	if sc.Name != "" {
+		s.Name = sc.Name
+	}
+	s.Flags = f
+	s.AddTags(sc.Tags...)
+	c := sc.calibration()
+	s.MinPulse = c.MinPulse
+	s.MaxPulse = c.MaxPulse
+	s.Range = sc.Range
+	s.points = sortedPoints(sc.CalibrationPoints)
+	s.AllowUnsafePulse = sc.AllowUnsafePulse
+	s.MinAngle, s.MaxAngle = sc.MinAngle, sc.MaxAngle
+	s.MaxErrors = sc.MaxErrors
+	if sc.MaxSpeed != 0 {
+		s.SetMaxSpeed(sc.MaxSpeed)
+	}
+	s.SetSpeed(sc.Speed)
+	s.SetAcceleration(sc.Acceleration, sc.Deceleration)
+	s.SetJerk(sc.Jerk)
+	s.SetExpo(sc.Expo)
+	if sc.Position != nil {
+		s.SetPosition(*sc.Position)
+	}
+
+	return s, nil
+}
+
+// calibration returns the pulse end points of the configuration, from the
+// pulse widths if they are set.
+func (sc ServoConfig) calibration() Calibration {
+	if sc.MinPulseWidth != 0 || sc.MaxPulseWidth != 0 {
+		return PulseWidths(
+			time.Duration(sc.MinPulseWidth*float64(time.Microsecond)),
+			time.Duration(sc.MaxPulseWidth*float64(time.Microsecond)),
+		)
+	}
+	return Calibration{MinPulse: sc.MinPulse, MaxPulse: sc.MaxPulse}
+}
+
+// defaultServoConfig returns the configuration of a servo created with
+// servo.New().
+func defaultServoConfig() ServoConfig {
+	return ServoConfig{
+		MinPulse: 0.05,
+		MaxPulse: 0.25,
+		Speed:    1.0,
+	}
+}
+
+// configFile is the format of a configuration file before it is merged.
+type configFile struct {
+	// Include lists the files merged before this one. Relative paths are
+	// resolved from the directory of this file.
+	Include []string `json:"include"`
+	// Servos are merged by name into the included servos.
+	Servos []json.RawMessage `json:"servos"`
+	// Routes are appended to the included routes.
+	Routes []RouteConfig `json:"routes"`
+	// Hosts holds the overrides applied only on the matching hostname.
+	Hosts map[string]struct {
+		Servos []json.RawMessage `json:"servos"`
+	} `json:"hosts"`
+}
+
+// templateData is the data available to the templates of a configuration
+// file.
+type templateData struct {
+	Hostname string
+	Env      map[string]string
+}
+
+// LoadConfig reads the JSON configuration file at path for the current
+// hostname.
+//
+// Before parsing, each file is executed as a text/template with the fields
+// .Hostname and .Env (a map of the environment variables), for example:
+//
+//	{"name": "jaw", "pin": {{if eq .Hostname "robot-b"}}18{{else}}17{{end}}}
+//
+// A file can include other files, which are merged first in order:
+//
+//	{
+//	  "include": ["base.json"],
+//	  "servos": [{"name": "jaw", "max_pulse": 0.21}],
+//	  "hosts": {
+//	    "robot-b": {"servos": [{"name": "jaw", "min_pulse": 0.06}]}
+//	  }
+//	}
+//
+// Servos are merged by name: only the fields present in a later entry replace
+// the fields of an earlier one. The overrides under "hosts" are applied last,
+// only if the key matches the hostname.
+//
+// Files written for an older servo.SchemaVersion are migrated when loaded.
+func LoadConfig(path string) (*Config, error) {
+	hostname, err := os.Hostname()
+	if err != nil {
+		return nil, err
+	}
+	return loadConfig(path, hostname)
+}
+
+// loadConfig reads the configuration file at path for the given hostname.
+func loadConfig(path, hostname string) (*Config, error) {
+	env := make(map[string]string)
+	for _, kv := range os.Environ() {
+		if i := strings.IndexByte(kv, '='); i >= 0 {
+			env[kv[:i]] = kv[i+1:]
+		}
+	}
+	data := templateData{
+		Hostname: hostname,
+		Env:      env,
+	}
+
+	c := &Config{Version: SchemaVersion}
+	if err := c.merge(path, data, nil); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// merge reads the file at path and merges it into the configuration. The
+// parents are the files that included it, used to detect include cycles.
+func (c *Config) merge(path string, data templateData, parents []string) error {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	for _, p := range parents {
+		if p == path {
+			return fmt.Errorf("config %s: include cycle", path)
+		}
+	}
+
+	raw, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Option("missingkey=zero").Parse(string(raw))
+	if err != nil {
+		return fmt.Errorf("config %s: %w", path, err)
+	}
+	buf := new(bytes.Buffer)
+	if err := tmpl.Execute(buf, data); err != nil {
+		return fmt.Errorf("config %s: %w", path, err)
+	}
+
+	migrated, err := migrate("config "+path, buf.Bytes(), configMigrations)
+	if err != nil {
+		return err
+	}
+	var f configFile
+	if err := json.Unmarshal(migrated, &f); err != nil {
+		return fmt.Errorf("config %s: %w", path, err)
+	}
+
+	for _, include := range f.Include {
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(path), include)
+		}
+		if err := c.merge(include, data, append(parents, path)); err != nil {
+			return err
+		}
+	}
+
+	servos := f.Servos
+	if host, ok := f.Hosts[data.Hostname]; ok {
+		servos = append(servos, host.Servos...)
+	}
+	for _, s := range servos {
+		if err := c.mergeServo(s); err != nil {
+			return fmt.Errorf("config %s: %w", path, err)
+		}
+	}
+	c.Routes = append(c.Routes, f.Routes...)
+
+	return nil
+}
+
+// mergeServo applies the fields present in raw to the servo with the same
+// name, or adds a new servo with default values.
+func (c *Config) mergeServo(raw json.RawMessage) error {
+	var id struct {
+		Name string `json:"name"`
+	}
+	if err := json.Unmarshal(raw, &id); err != nil {
+		return err
+	}
+	if id.Name == "" {
+		return fmt.Errorf("servo without name")
+	}
+
+	for i := range c.Servos {
+		if c.Servos[i].Name == id.Name {
+			return json.Unmarshal(raw, &c.Servos[i])
+		}
+	}
+
+	sc := defaultServoConfig()
+	if err := json.Unmarshal(raw, &sc); err != nil {
+		return err
+	}
+	c.Servos = append(c.Servos, sc)
+
+	return nil
+}
@@ -0,0 +1,144 @@
+// +build !live
+
+package servo
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// writeConfigs writes the files into a temporary directory and returns its
+// path.
+func writeConfigs(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "servo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestLoadConfig(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		"base.json": `{
+			"servos": [
+				{"name": "jaw", "pin": 17, "flags": ["centered"]},
+				{"name": "neck", "pin": 18, "speed": 0.5}
+			]
+		}`,
+		"robot.json": `{
+			"include": ["base.json"],
+			"servos": [
+				{"name": "jaw", "max_pulse": 0.21},
+				{"name": "eye", "pin": {{if eq .Hostname "robot-b"}}23{{else}}22{{end}}}
+			],
+			"hosts": {
+				"robot-b": {"servos": [{"name": "neck", "pin": 27}]}
+			}
+		}`,
+	})
+
+	tests := map[string]map[string]ServoConfig{
+		"robot-a": {
+			"jaw":  {Name: "jaw", Pin: 17, Flags: []string{"centered"}, MinPulse: 0.05, MaxPulse: 0.21, Speed: 1},
+			"neck": {Name: "neck", Pin: 18, MinPulse: 0.05, MaxPulse: 0.25, Speed: 0.5},
+			"eye":  {Name: "eye", Pin: 22, MinPulse: 0.05, MaxPulse: 0.25, Speed: 1},
+		},
+		"robot-b": {
+			"jaw":  {Name: "jaw", Pin: 17, Flags: []string{"centered"}, MinPulse: 0.05, MaxPulse: 0.21, Speed: 1},
+			"neck": {Name: "neck", Pin: 27, MinPulse: 0.05, MaxPulse: 0.25, Speed: 0.5},
+			"eye":  {Name: "eye", Pin: 23, MinPulse: 0.05, MaxPulse: 0.25, Speed: 1},
+		},
+	}
+
+	for hostname, want := range tests {
+		t.Run(hostname, func(t *testing.T) {
+			c, err := loadConfig(filepath.Join(dir, "robot.json"), hostname)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(c.Servos) != len(want) {
+				t.Fatalf("number of servos, got: %d, want: %d", len(c.Servos), len(want))
+			}
+			for name, w := range want {
+				got, ok := c.Servo(name)
+				if !ok {
+					t.Errorf("servo %q is missing", name)
+					continue
+				}
+				if got.Name != w.Name || got.Pin != w.Pin || got.MinPulse != w.MinPulse ||
+					got.MaxPulse != w.MaxPulse || got.Speed != w.Speed ||
+					strings.Join(got.Flags, ",") != strings.Join(w.Flags, ",") {
+					t.Errorf("servo %q does not match\ngot:\n%+v\nwant:\n%+v", name, got, w)
+				}
+			}
+		})
+	}
+}
+
+func TestLoadConfig_Errors(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		"a.json":        `{"include": ["b.json"]}`,
+		"b.json":        `{"include": ["a.json"]}`,
+		"noname.json":   `{"servos": [{"pin": 1}]}`,
+		"template.json": `{"servos": [{{.Nope}}]}`,
+		"syntax.json":   `{"servos": [}`,
+	})
+
+	for _, name := range []string{"a.json", "noname.json", "template.json", "missing.json"} {
+		if _, err := loadConfig(filepath.Join(dir, name), "host"); err == nil {
+			t.Errorf("loadConfig(%q) should fail", name)
+		}
+	}
+
+	var syntax *json.SyntaxError
+	if _, err := loadConfig(filepath.Join(dir, "syntax.json"), "host"); !errors.As(err, &syntax) {
+		t.Errorf("syntax error was not wrapped, got: %v", err)
+	}
+}
+
+func TestServoConfig_New(t *testing.T) {
+	position := 0.5
+	sc := ServoConfig{
+		Name:     "jaw",
+		Pin:      17,
+		Flags:    []string{"Centered", "normalized"},
+		MinPulse: 0.06,
+		MaxPulse: 0.24,
+		Speed:    0.5,
+		MaxSpeed: 100,
+		Position: &position,
+	}
+
+	s, err := sc.New()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `servo "jaw" connected to gpio(17) [flags: ( Centered Normalized )]`
+	if s.String() != want {
+		t.Errorf("servo does not match\ngot:\n%v\nwant:\n%v", s, want)
+	}
+	if s.Position() != position {
+		t.Errorf("position, got: %.2f, want: %.2f", s.Position(), position)
+	}
+	if s.MaxSpeed() != 100 || s.step != 50 {
+		t.Errorf("speed, got: %.2f of %.2f°/s, want: 50.00 of 100.00°/s", s.step, s.MaxSpeed())
+	}
+
+	sc.Flags = []string{"upside-down"}
+	if _, err := sc.New(); err == nil {
+		t.Error("unknown flags should fail")
+	}
+}
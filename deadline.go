@@ -0,0 +1,141 @@
+package servo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"time"
+)
+
+// travel returns how long the servo takes to move from its current position
+// to target at its current speed, acceleration and jerk. It returns
+// math.MaxInt64 if the servo cannot reach the target.
+func (s *Servo) travel(target float64) time.Duration {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	raw := s.limit(s.applyExpo(s.raw(target)))
+	if raw == s.position {
+		return 0
+	}
+	speed := s.speedOf(nil)
+	if speed == 0 || s.fault != nil {
+		return math.MaxInt64
+	}
+
+	t := s.natural(s.position, raw, speed).time
+	return time.Duration(t * float64(time.Second))
+}
+
+// reachBy returns an error if the servo cannot reach target within left, with
+// its travel time slowed down by scale, e.g. the speed of a group.
+func (s *Servo) reachBy(target float64, left time.Duration, scale float64) error {
+	d := s.travel(target)
+	if d == 0 {
+		return nil
+	}
+	if d != math.MaxInt64 && scale < 1 {
+		if scale <= 0 {
+			d = math.MaxInt64
+		} else {
+			d = time.Duration(float64(d) / scale)
+		}
+	}
+	switch {
+	case d <= left:
+		return nil
+	case d == math.MaxInt64:
+		return s.wrap(fmt.Errorf("%w: cannot reach %.2f", ErrTimeout, target))
+	}
+	return s.wrap(fmt.Errorf("%w: cannot reach %.2f by the deadline, needs %v but %v left", ErrTimeout, target, d, left))
+}
+
+// waitAll waits for all the waiters.
+type waitAll struct {
+	waiters []Waiter
+	// done is the channel of Done(), shared until the waiters are done.
+	done doneCache
+}
+
+// add adds the waiter to the waiters.
+func (w *waitAll) add(waiter Waiter) {
+	w.waiters = append(w.waiters, waiter)
+}
+
+// Wait implements the Waiter interface.
+func (w *waitAll) Wait() {
+	for _, waiter := range w.waiters {
+		waiter.Wait()
+	}
+}
+
+// Done implements the Waiter interface.
+func (w *waitAll) Done() <-chan struct{} {
+	return w.done.get(w.Wait)
+}
+
+// MoveToBy moves the servo to target only if it can reach it by the deadline
+// of the playback clock (see servo.Now()) at its current speed. Otherwise, it
+// returns an error without moving.
+func (s *Servo) MoveToBy(target float64, deadline time.Time) (Waiter, error) {
+	return MoveToBy(map[*Servo]float64{s: target}, deadline)
+}
+
+// MoveToBy moves each servo to its target only if all of them can reach their
+// targets by the deadline of the playback clock (see servo.Now()) at their
+// current speeds. Otherwise, it returns an error without moving any of them,
+// so planners learn about infeasible plans before they start. Servos already
+// at their targets are always on time. The servos are checked in the order of
+// their pins, and the error of the first late servo is returned. The moves
+// begin on the same update, and the returned Waiter waits for all the servos.
+func MoveToBy(targets map[*Servo]float64, deadline time.Time) (Waiter, error) {
+	if err := maintenanceErr(); err != nil {
+		return nil, err
+	}
+
+	servos := make([]*Servo, 0, len(targets))
+	for s := range targets {
+		servos = append(servos, s)
+	}
+	sort.Slice(servos, func(i, j int) bool {
+		return servos[i].Pin() < servos[j].Pin()
+	})
+
+	left := deadline.Sub(Now())
+	for _, s := range servos {
+		if err := s.reachBy(targets[s], left, 1); err != nil {
+			return nil, err
+		}
+	}
+
+	return Batch(func(tx *Tx) {
+		for _, s := range servos {
+			tx.MoveTo(s, targets[s])
+		}
+	}), nil
+}
+
+// MoveToBy moves each servo of the group to its target, like MoveTo, only if
+// all of them can reach their targets by the deadline of the playback clock
+// (see servo.Now()) at their current speeds, slowed down by the speed of the
+// group. Otherwise, it returns the error of the first late servo in the order
+// of the group without moving any of them. Servos already at their targets
+// are always on time.
+func (g *Group) MoveToBy(targets []float64, deadline time.Time) (Waiter, error) {
+	if err := maintenanceErr(); err != nil {
+		return nil, err
+	}
+	if len(targets) != len(g.servos) {
+		return nil, fmt.Errorf("%w: %d targets for %d servos", ErrOutOfRange, len(targets), len(g.servos))
+	}
+
+	left := deadline.Sub(Now())
+	speed := g.Speed()
+	for i, s := range g.servos {
+		if err := s.reachBy(targets[i], left, speed); err != nil {
+			return nil, err
+		}
+	}
+
+	return g.MoveTo(targets...)
+}
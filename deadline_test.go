@@ -0,0 +1,94 @@
+// +build !live
+
+package servo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMoveToBy(t *testing.T) {
+	useBlaster(t)
+
+	a, b := New(98), New(99)
+	for _, s := range []*Servo{a, b} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+	}
+	b.SetSpeed(0.1)
+
+	// b needs about 2.8s to travel 90 degrees.
+	targets := map[*Servo]float64{a: 90, b: 90}
+	if _, err := MoveToBy(targets, Now().Add(time.Second)); err == nil {
+		t.Error("infeasible move did not fail")
+	}
+	if !a.isIdle() || !b.isIdle() {
+		t.Error("infeasible move moved the servos")
+	}
+
+	b.SetSpeed(1)
+	w, err := MoveToBy(targets, Now().Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Wait()
+	if a.Position() != 90 || b.Position() != 90 {
+		t.Errorf("servos did not move, got: %.2f, %.2f, want: 90.00, 90.00", a.Position(), b.Position())
+	}
+
+	b.SetSpeed(0)
+	if _, err := b.MoveToBy(0, Now().Add(time.Hour)); err == nil {
+		t.Error("move of a still servo did not fail")
+	}
+	if _, err := b.MoveToBy(90, Now()); err != nil {
+		t.Errorf("move to the current position failed: %v", err)
+	}
+}
+
+func TestGroup_MoveToBy(t *testing.T) {
+	useBlaster(t)
+
+	a, b := New(98), New(99)
+	for _, s := range []*Servo{a, b} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+		s.SetPosition(0)
+		s.Wait()
+	}
+	a.SetSpeed(0.1)
+	b.SetSpeed(0.1)
+
+	// Both are late, the error is of the first servo of the group.
+	g := NewGroup(b, a)
+	for i := 0; i < 10; i++ {
+		_, err := g.MoveToBy([]float64{90, 90}, Now().Add(time.Second))
+		if !errors.Is(err, ErrTimeout) || !strings.Contains(err.Error(), b.Name) {
+			t.Fatalf("wrong error, got: %v, want: %v of %q", err, ErrTimeout, b.Name)
+		}
+	}
+	if !a.isIdle() || !b.isIdle() {
+		t.Error("infeasible move moved the servos")
+	}
+
+	// 90 degrees take about 0.3s, or 0.6s at half the speed of the group.
+	a.SetSpeed(1)
+	b.SetSpeed(1)
+	g.SetSpeed(0.5)
+	if _, err := g.MoveToBy([]float64{90, 45}, Now().Add(400*time.Millisecond)); !errors.Is(err, ErrTimeout) {
+		t.Errorf("speed of the group was ignored, got: %v, want: %v", err, ErrTimeout)
+	}
+	w, err := g.MoveToBy([]float64{90, 45}, Now().Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	w.Wait()
+	if b.Position() != 90 || a.Position() != 45 {
+		t.Errorf("servos did not move, got: %.2f, %.2f, want: 90.00, 45.00", b.Position(), a.Position())
+	}
+}
@@ -0,0 +1,102 @@
+package servo
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	// maxTickLag is the number of update intervals between two ticks after
+	// which the manager is considered overloaded.
+	maxTickLag = 2
+	// degradeSkip is how often low-priority servos are updated while the
+	// manager is degraded, in ticks.
+	degradeSkip = 4
+	// recoverAfter is the time without lag after which the manager recovers
+	// from degradation.
+	recoverAfter = time.Second
+)
+
+// degradation tracks if the manager keeps up with its update ticks.
+type degradation struct {
+	active bool
+	since  time.Time
+	total  time.Duration
+
+	// last, calm and n are only accessed by the manager.
+	last, calm time.Time
+	n          int
+
+	lock *sync.Mutex
+}
+
+func newDegradation() *degradation {
+	return &degradation{
+		lock: new(sync.Mutex),
+	}
+}
+
+// reset forgets the last tick, e.g. after the update interval changed.
+func (d *degradation) reset() {
+	d.last = time.Time{}
+}
+
+// tick records an update tick at now for the expected interval. It returns
+// EventDegrade or EventRecover if the state of the manager changed, or 0.
+func (d *degradation) tick(now time.Time, interval time.Duration) EventType {
+	last := d.last
+	d.last = now
+	if last.IsZero() {
+		d.calm = now
+		return 0
+	}
+	lag := now.Sub(last) > maxTickLag*interval
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	switch {
+	case lag:
+		d.calm = now
+		if !d.active {
+			d.active = true
+			d.since = now
+			return EventDegrade
+		}
+	case d.active && now.Sub(d.calm) >= recoverAfter:
+		d.active = false
+		d.total += now.Sub(d.since)
+		return EventRecover
+	}
+
+	return 0
+}
+
+// skipLow checks if the low-priority servos should skip the current tick.
+func (d *degradation) skipLow() bool {
+	d.n++
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	return d.active && d.n%degradeSkip != 0
+}
+
+// Degradation reports if the manager is currently degraded because it cannot
+// keep up with its update ticks, and the total time it spent degraded.
+//
+// While degraded, the servos with LowPriority set are updated less often, so
+// the rest of the servos keep moving smoothly.
+func Degradation() (active bool, total time.Duration) {
+	d := _blaster.degrade
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	total = d.total
+	if d.active {
+		total += time.Since(d.since)
+	}
+
+	return d.active, total
+}
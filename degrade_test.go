@@ -0,0 +1,64 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDegradation(t *testing.T) {
+	b := useBlaster(t)
+	d := b.degrade
+	interval := 3 * time.Millisecond
+
+	now := time.Now()
+	tick := func(gap time.Duration) EventType {
+		now = now.Add(gap)
+		return d.tick(now, interval)
+	}
+
+	if e := tick(0); e != 0 {
+		t.Errorf("first tick changed the state: %v", e)
+	}
+	if e := tick(interval); e != 0 {
+		t.Errorf("tick on time changed the state: %v", e)
+	}
+	if e := tick(10 * interval); e != EventDegrade {
+		t.Errorf("late tick did not degrade, got: %v", e)
+	}
+	if active, _ := Degradation(); !active {
+		t.Error("manager is not degraded")
+	}
+
+	skipped := 0
+	for i := 0; i < degradeSkip; i++ {
+		if d.skipLow() {
+			skipped++
+		}
+	}
+	if skipped != degradeSkip-1 {
+		t.Errorf("wrong skipped ticks, got: %d, want: %d", skipped, degradeSkip-1)
+	}
+
+	ticks := int((recoverAfter + interval - 1) / interval)
+	for i := 0; i < ticks-1; i++ {
+		if e := tick(interval); e != 0 {
+			t.Fatalf("recovered too early at tick %d: %v", i, e)
+		}
+	}
+	if e := tick(interval); e != EventRecover {
+		t.Errorf("manager did not recover, got: %v", e)
+	}
+
+	active, total := Degradation()
+	if active {
+		t.Error("manager is still degraded")
+	}
+	if want := time.Duration(ticks) * interval; total != want {
+		t.Errorf("wrong degraded time, got: %v, want: %v", total, want)
+	}
+	if d.skipLow() {
+		t.Error("recovered manager skipped a tick")
+	}
+}
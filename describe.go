@@ -0,0 +1,68 @@
+package servo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Describe returns a multi-line human-readable summary of the servo, with its
+// calibration, range, current state and last error. Use it for command line
+// tools and debug dumps; String() stays compact for logs.
+func (s *Servo) Describe() string {
+	connected := _blaster.isConnected(s)
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	state := "idle"
+	if !s.idle {
+		state = "moving"
+	}
+	if s.fault != nil {
+		state = "fault"
+	}
+
+	last := "never"
+	if !s.writtenAt.IsZero() {
+		last = fmt.Sprintf("%.6f at %s", s.writtenPWM, s.writtenAt.Format("15:04:05.000"))
+	}
+
+	maxErrors := "disabled"
+	if s.MaxErrors > 0 {
+		maxErrors = fmt.Sprintf("%d", s.MaxErrors)
+	}
+
+	fault := "none"
+	if s.fault != nil {
+		fault = s.fault.Error()
+	}
+
+	c := s.counters
+
+	b := new(strings.Builder)
+	fmt.Fprintf(b, "servo %q on gpio(%d)\n", s.Name, s.gpio())
+	fmt.Fprintf(b, "  connected:    %t\n", connected)
+	fmt.Fprintf(b, "  flags:        %v\n", s.Flags)
+	points := ""
+	if len(s.points) > 0 {
+		points = fmt.Sprintf(", %d points", len(s.points))
+	}
+	fmt.Fprintf(b, "  calibration:  min %.4f (%v), max %.4f (%v)%s\n",
+		s.MinPulse, pulseWidth(s.MinPulse), s.MaxPulse, pulseWidth(s.MaxPulse), points)
+	fmt.Fprintf(b, "  unsafe pulse: %t\n", s.AllowUnsafePulse)
+	lo, hi := s.limits()
+	fmt.Fprintf(b, "  range:        %.2f to %.2f\n", s.adjust(lo), s.adjust(hi))
+	fmt.Fprintf(b, "  state:        %s\n", state)
+	fmt.Fprintf(b, "  position:     %.2f\n", s.adjust(s.position))
+	fmt.Fprintf(b, "  target:       %.2f\n", s.adjust(s.target))
+	fmt.Fprintf(b, "  speed:        %.2f\n", s.speedFraction())
+	fmt.Fprintf(b, "  layers:       %d\n", len(s.layers))
+	fmt.Fprintf(b, "  low priority: %t\n", s.LowPriority)
+	fmt.Fprintf(b, "  last pwm:     %s\n", last)
+	fmt.Fprintf(b, "  errors:       %d (max: %s)\n", s.errors, maxErrors)
+	fmt.Fprintf(b, "  fault:        %s\n", fault)
+	fmt.Fprintf(b, "  counters:     connects=%d clamps=%d stops=%d faults=%d write_errors=%d watchdog_trips=%d\n",
+		c.Connects, c.Clamps, c.Stops, c.Faults, c.WriteErrors, c.WatchdogTrips)
+
+	return b.String()
+}
@@ -0,0 +1,36 @@
+// +build !live
+
+package servo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestServo_Describe(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	s.Name = "jaw"
+	s.Flags = Centered
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetFault(errors.New("stalled"))
+
+	d := s.Describe()
+	for _, line := range []string{
+		"servo \"jaw\" on gpio(99)\n",
+		"  connected:    true\n",
+		"  calibration:  min 0.0500 (500µs), max 0.2500 (2.5ms)\n",
+		"  range:        -90.00 to 90.00\n",
+		"  state:        fault\n",
+		"  fault:        stalled\n",
+	} {
+		if !strings.Contains(d, line) {
+			t.Errorf("missing %q in:\n%s", line, d)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package servo
+
+import "sync/atomic"
+
+// Detach stops the servo and releases its pin (0 duty), so it goes limp and
+// can be posed by hand or left unpowered, while it stays connected. Its
+// layers do not drive it while it is detached. The servo is driven again by
+// Attach() or by its next move.
+func (s *Servo) Detach() {
+	s.detach(true)
+}
+
+// detach stops the servo and releases its pin (0 duty). With limp set, as by
+// Detach(), the servo is also marked as detached, so its layers do not drive
+// it while it is idle, until Attach() or its next move. Otherwise, as by the
+// watchdog and the maintenance mode, the pin is only released until the
+// manager drives the servo again, e.g. by its next move or its layers.
+func (s *Servo) detach(limp bool) {
+	// The servo is stopped first, so the manager does not take the move
+	// being stopped for a new one.
+	s.Stop()
+	if limp {
+		atomic.StoreInt32(&s.detached, 1)
+	}
+	atomic.StoreInt32(&s.release, 1)
+	_blaster.wakeUp()
+}
+
+// Attach drives the servo again at its current position after Detach(). It
+// does nothing else while the servo is in the fault state, which keeps its
+// pin released until ClearFault().
+func (s *Servo) Attach() {
+	atomic.StoreInt32(&s.detached, 0)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.fault != nil {
+		return
+	}
+	atomic.StoreInt32(&s.release, 0)
+	// Force the manager to write the current position.
+	s.idle = false
+	s.publish()
+	_blaster.wakeUp()
+}
+
+// Detached checks if the servo was detached by Detach() and has not been
+// driven since.
+func (s *Servo) Detached() bool {
+	return atomic.LoadInt32(&s.detached) == 1
+}
+
+// limp checks if the manager should skip the servo because it is detached. A
+// new move attaches the servo again. It must be called by the manager.
+func (s *Servo) limp() bool {
+	if atomic.LoadInt32(&s.detached) == 0 {
+		return false
+	}
+	if !s.isIdle() {
+		atomic.StoreInt32(&s.detached, 0)
+		return false
+	}
+	return true
+}
@@ -0,0 +1,83 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServo_Detach(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.SetPosition(90)
+	s.Wait()
+	s.Detach()
+	time.Sleep(20 * time.Millisecond)
+	if got, _ := s.LastPWM(); got != 0 {
+		t.Errorf("pin was not released, got: %.4f", got)
+	}
+	if !s.Detached() {
+		t.Error("servo is not detached")
+	}
+
+	// Layers do not drive a detached servo.
+	l := s.AddLayer(constMotion(10))
+	time.Sleep(20 * time.Millisecond)
+	if got, _ := s.LastPWM(); got != 0 {
+		t.Errorf("layer drove a detached servo, got: %.4f", got)
+	}
+	l.Remove()
+
+	s.Attach()
+	time.Sleep(20 * time.Millisecond)
+	if got, _ := s.LastPWM(); !approx(got, float64(s.pulse(90))) {
+		t.Errorf("servo was not attached, got: %.4f, want: %.4f", got, s.pulse(90))
+	}
+	if s.Detached() || s.Position() != 90 {
+		t.Errorf("wrong state after Attach(), detached: %t, position: %.2f", s.Detached(), s.Position())
+	}
+
+	// A new move attaches the servo.
+	s.Detach()
+	time.Sleep(20 * time.Millisecond)
+	s.MoveTo(100).Wait()
+	time.Sleep(20 * time.Millisecond)
+	if got, _ := s.LastPWM(); !approx(got, float64(s.pulse(100))) || s.Detached() {
+		t.Errorf("move did not attach the servo, got: %.4f, want: %.4f", got, s.pulse(100))
+	}
+}
+
+func TestServo_detach_release(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.SetPosition(90)
+	s.Wait()
+	// The watchdog and the maintenance mode only release the pin.
+	s.detach(false)
+	time.Sleep(20 * time.Millisecond)
+	if got, _ := s.LastPWM(); got != 0 || s.Detached() {
+		t.Errorf("pin was not only released, got: %.4f, detached: %t", got, s.Detached())
+	}
+
+	l := s.AddLayer(constMotion(10))
+	defer l.Remove()
+	time.Sleep(20 * time.Millisecond)
+	if got, _ := s.LastPWM(); got == 0 {
+		t.Error("layer did not drive the released servo")
+	}
+}
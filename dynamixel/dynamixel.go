@@ -0,0 +1,212 @@
+// Package dynamixel drives Dynamixel serial bus servos (protocol 1.0, e.g.
+// AX-12 or MX series in protocol 1.0 mode) through a half-duplex UART
+// adapter such as the U2D2 or USB2AX.
+//
+// Set a Controller as the backend of the servo package, and use the ID of
+// each Dynamixel as the pin of its servo:
+//
+//	dx, err := dynamixel.Open("/dev/ttyUSB0", 1000000)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	servo.SetBackend(dx)
+//	defer servo.Close()
+//
+//	s := servo.New(1) // Dynamixel with ID 1
+//
+// The servo package interpolates the motion and streams the goal positions,
+// so MoveTo(), SetSpeed() and Wait() behave as with pwm servos. The moving
+// speed register of the servos is left at its default (maximum).
+package dynamixel
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+const (
+	// MinPulse and MaxPulse are the default pulses of the servo package at
+	// 0 and 180 degrees.
+	MinPulse = 500 * time.Microsecond
+	MaxPulse = 2500 * time.Microsecond
+	// MinPosition and MaxPosition are the default goal positions at
+	// MinPulse and MaxPulse: 180 degrees centered in the 300 degrees of an
+	// AX-12.
+	MinPosition = 205
+	MaxPosition = 819
+)
+
+// Protocol 1.0 instructions, registers and IDs.
+const (
+	instSyncWrite = 0x83
+
+	regTorqueEnable = 0x18
+	regGoalPosition = 0x1E
+
+	broadcastID = 0xFE
+	maxID       = 0xFD
+)
+
+// Controller is a bus of Dynamixel servos. It implements the servo.Backend
+// interface. Use dynamixel.Open() or dynamixel.New() for correct
+// initialization.
+type Controller struct {
+	// MinPulse and MaxPulse are mapped to MinPosition and MaxPosition. The
+	// pulses in between are mapped linearly, so the calibration of the
+	// servo package still applies.
+	MinPulse, MaxPulse time.Duration
+	// MinPosition and MaxPosition are the goal positions at MinPulse and
+	// MaxPulse, from 0 to 1023.
+	MinPosition, MaxPosition int
+
+	w io.Writer
+	// torque are the IDs with the torque enabled by the controller.
+	torque map[int]bool
+	lock   *sync.Mutex
+}
+
+// New creates a Controller that writes the instruction packets to w.
+func New(w io.Writer) *Controller {
+	return &Controller{
+		MinPulse:    MinPulse,
+		MaxPulse:    MaxPulse,
+		MinPosition: MinPosition,
+		MaxPosition: MaxPosition,
+		w:           w,
+		torque:      make(map[int]bool),
+		lock:        new(sync.Mutex),
+	}
+}
+
+// Write implements the servo.Backend interface. The goal positions of all the
+// servos are sent in a single sync write packet. A pulse of 0 disables the
+// torque of the servo, which is enabled again on its next pulse.
+func (c *Controller) Write(pulses []servo.Pulse) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, p := range pulses {
+		if p.Pin < 0 || p.Pin > maxID {
+			return fmt.Errorf("dynamixel: ID %d is outside the range 0 to %d", p.Pin, maxID)
+		}
+	}
+
+	var on, off, goals [][]byte
+	for _, p := range pulses {
+		id := byte(p.Pin)
+		if p.Width == 0 {
+			if c.torque[p.Pin] {
+				off = append(off, []byte{id, 0})
+				delete(c.torque, p.Pin)
+			}
+			continue
+		}
+		if !c.torque[p.Pin] {
+			on = append(on, []byte{id, 1})
+			c.torque[p.Pin] = true
+		}
+		pos := c.position(p.Width)
+		goals = append(goals, []byte{id, byte(pos), byte(pos >> 8)})
+	}
+
+	var msg []byte
+	if len(off) > 0 {
+		msg = append(msg, syncWrite(regTorqueEnable, off)...)
+	}
+	if len(on) > 0 {
+		msg = append(msg, syncWrite(regTorqueEnable, on)...)
+	}
+	if len(goals) > 0 {
+		msg = append(msg, syncWrite(regGoalPosition, goals)...)
+	}
+
+	return c.write(msg)
+}
+
+// Close implements the servo.Backend interface. It disables the torque of the
+// servos driven so far. If the writer is an io.Closer, it is closed too.
+func (c *Controller) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	ids := make([]int, 0, len(c.torque))
+	for id := range c.torque {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	off := make([][]byte, len(ids))
+	for i, id := range ids {
+		off[i] = []byte{byte(id), 0}
+	}
+	c.torque = make(map[int]bool)
+
+	var err error
+	if len(off) > 0 {
+		err = c.write(syncWrite(regTorqueEnable, off))
+	}
+	if closer, ok := c.w.(io.Closer); ok {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// position maps the pulse to a goal position, clamped from 0 to 1023.
+func (c *Controller) position(width time.Duration) int {
+	span := float64(c.MaxPulse - c.MinPulse)
+	if span == 0 {
+		return c.MinPosition
+	}
+	f := float64(width-c.MinPulse) / span
+	pos := c.MinPosition + int(f*float64(c.MaxPosition-c.MinPosition)+0.5)
+
+	switch {
+	case pos < 0:
+		return 0
+	case pos > 1023:
+		return 1023
+	}
+	return pos
+}
+
+// syncWrite returns the sync write packet that writes the data of each entry
+// at addr. Each entry is the ID of a servo followed by its data, and all the
+// entries must have the same length.
+func syncWrite(addr byte, entries [][]byte) []byte {
+	params := []byte{addr, byte(len(entries[0]) - 1)}
+	for _, e := range entries {
+		params = append(params, e...)
+	}
+	return packet(broadcastID, instSyncWrite, params)
+}
+
+// packet returns the instruction packet with its checksum.
+func packet(id, inst byte, params []byte) []byte {
+	p := []byte{0xFF, 0xFF, id, byte(len(params) + 2), inst}
+	p = append(p, params...)
+
+	var sum byte
+	for _, b := range p[2:] {
+		sum += b
+	}
+	return append(p, ^sum)
+}
+
+// write sends the packets to the bus.
+func (c *Controller) write(msg []byte) error {
+	if len(msg) == 0 {
+		return nil
+	}
+	if _, err := c.w.Write(msg); err != nil {
+		return fmt.Errorf("dynamixel: %v", err)
+	}
+	return nil
+}
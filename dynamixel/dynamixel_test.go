@@ -0,0 +1,74 @@
+// +build !live
+
+package dynamixel
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+func TestSyncWrite(t *testing.T) {
+	// Example of the sync write instruction of the protocol 1.0 manual.
+	got := syncWrite(0x1E, [][]byte{
+		{0x00, 0x10, 0x00, 0x50, 0x01},
+		{0x01, 0x20, 0x02, 0x60, 0x03},
+		{0x02, 0x30, 0x00, 0x70, 0x01},
+		{0x03, 0x20, 0x02, 0x80, 0x03},
+	})
+	want := []byte{
+		0xFF, 0xFF, 0xFE, 0x18, 0x83, 0x1E, 0x04,
+		0x00, 0x10, 0x00, 0x50, 0x01,
+		0x01, 0x20, 0x02, 0x60, 0x03,
+		0x02, 0x30, 0x00, 0x70, 0x01,
+		0x03, 0x20, 0x02, 0x80, 0x03,
+		0x12,
+	}
+	if !bytes.Equal(got, want) {
+		t.Errorf("wrong packet\ngot:  % X\nwant: % X", got, want)
+	}
+}
+
+func TestController(t *testing.T) {
+	out := new(bytes.Buffer)
+	c := New(out)
+
+	err := c.Write([]servo.Pulse{
+		{Pin: 1, Width: 1500 * time.Microsecond},
+		{Pin: 2, Width: 2500 * time.Microsecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append(
+		syncWrite(regTorqueEnable, [][]byte{{1, 1}, {2, 1}}),
+		syncWrite(regGoalPosition, [][]byte{{1, 0x00, 0x02}, {2, 0x33, 0x03}})...,
+	)
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("wrong packets\ngot:  % X\nwant: % X", got, want)
+	}
+
+	out.Reset()
+	if err := c.Write([]servo.Pulse{{Pin: 1, Width: 0}}); err != nil {
+		t.Fatal(err)
+	}
+	want = syncWrite(regTorqueEnable, [][]byte{{1, 0}})
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("torque was not disabled\ngot:  % X\nwant: % X", got, want)
+	}
+
+	out.Reset()
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want = syncWrite(regTorqueEnable, [][]byte{{2, 0}})
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("servos were not released\ngot:  % X\nwant: % X", got, want)
+	}
+
+	if err := c.Write([]servo.Pulse{{Pin: 254, Width: time.Millisecond}}); err == nil {
+		t.Error("broadcast ID was accepted as a servo")
+	}
+}
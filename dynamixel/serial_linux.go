@@ -0,0 +1,62 @@
+// +build linux
+
+package dynamixel
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// cbaud is the mask of the baud rate in the control flags of Linux.
+const cbaud = 0x100f
+
+// bauds are the supported baud rates.
+var bauds = map[int]uint32{
+	9600:    syscall.B9600,
+	57600:   syscall.B57600,
+	115200:  syscall.B115200,
+	1000000: syscall.B1000000,
+}
+
+// Open opens the bus at the serial port path (e.g. "/dev/ttyUSB0") at the baud
+// rate of the servos, 57600 or 1000000 for most Dynamixels.
+func Open(path string, baud int) (*Controller, error) {
+	rate, ok := bauds[baud]
+	if !ok {
+		return nil, fmt.Errorf("dynamixel: unsupported baud rate %d", baud)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := raw(f, rate); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("dynamixel: could not configure %s: %v", path, err)
+	}
+
+	return New(f), nil
+}
+
+// raw sets the serial port in raw mode, 8N1 at rate.
+func raw(f *os.File, rate uint32) error {
+	var t syscall.Termios
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&t))); errno != 0 {
+		return errno
+	}
+
+	t.Iflag = 0
+	t.Oflag = 0
+	t.Lflag = 0
+	t.Cflag &^= cbaud | syscall.CSIZE | syscall.PARENB | syscall.CSTOPB
+	t.Cflag |= rate | syscall.CS8 | syscall.CREAD | syscall.CLOCAL
+	t.Ispeed = rate
+	t.Ospeed = rate
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TCSETS, uintptr(unsafe.Pointer(&t))); errno != 0 {
+		return errno
+	}
+	return nil
+}
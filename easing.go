@@ -0,0 +1,162 @@
+package servo
+
+import "math"
+
+// Easing maps the progress of a move, from 0.0 at its start to 1.0 at its
+// end, to the fraction of the distance traveled. Linear interpolation is
+// func(t float64) float64 { return t }.
+//
+// Any function can be used as an easing curve, e.g. bounce or elastic curves.
+// It is only called with t from 0.0 to 1.0, and the move always ends at its
+// target, even if the curve does not end at 1.0. The curve can overshoot
+// below 0.0 or above 1.0, but the servo stays within its range.
+type Easing func(t float64) float64
+
+// EaseIn starts the move slowly and speeds up until the end.
+func EaseIn(t float64) float64 {
+	return t * t
+}
+
+// EaseOut starts the move at full speed and slows down until the end.
+func EaseOut(t float64) float64 {
+	return t * (2 - t)
+}
+
+// EaseInOut speeds up during the first half of the move and slows down during
+// the second half.
+func EaseInOut(t float64) float64 {
+	if t < 0.5 {
+		return 2 * t * t
+	}
+	return 1 - 2*(1-t)*(1-t)
+}
+
+// EaseSine speeds up and slows down following a sine, more gently than
+// EaseInOut.
+func EaseSine(t float64) float64 {
+	return (1 - math.Cos(math.Pi*t)) / 2
+}
+
+// EaseCubic speeds up and slows down following a cubic, more sharply than
+// EaseInOut.
+func EaseCubic(t float64) float64 {
+	if t < 0.5 {
+		return 4 * t * t * t
+	}
+	u := 1 - t
+	return 1 - 4*u*u*u
+}
+
+// CubicBezier returns the easing curve of a cubic Bézier from (0, 0) to (1, 1)
+// with the control points (x1, y1) and (x2, y2), as the cubic-bezier() timing
+// function of CSS and most animation tools. x1 and x2 are clamped from 0.0 to
+// 1.0, so the curve never goes back in time. For example, EaseInOut is close to
+// CubicBezier(0.42, 0, 0.58, 1).
+func CubicBezier(x1, y1, x2, y2 float64) Easing {
+	x1, x2 = clamp(x1, 0, 1), clamp(x2, 0, 1)
+	bezier := func(a, b, u float64) float64 {
+		v := 1 - u
+		return 3*v*v*u*a + 3*v*u*u*b + u*u*u
+	}
+	return func(t float64) float64 {
+		// x(u) is monotonic, so find the u of t by bisection.
+		lo, hi := 0.0, 1.0
+		for i := 0; i < 40; i++ {
+			mid := (lo + hi) / 2
+			if bezier(x1, x2, mid) < t {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		return bezier(y1, y2, (lo+hi)/2)
+	}
+}
+
+// EasingTable returns an easing curve that interpolates linearly between
+// values sampled at even intervals of the move, e.g. a curve exported from an
+// animation tool. The first value is at the start of the move and the last
+// one at its end. With less than 2 values, the curve is linear.
+func EasingTable(values ...float64) Easing {
+	values = append([]float64(nil), values...)
+	if len(values) < 2 {
+		return func(t float64) float64 { return t }
+	}
+	return func(t float64) float64 {
+		x := t * float64(len(values)-1)
+		i := int(x)
+		if i >= len(values)-1 {
+			return values[len(values)-1]
+		}
+		return values[i] + (values[i+1]-values[i])*(x-float64(i))
+	}
+}
+
+// WithEasing makes the move follow the easing curve instead of moving at a
+// constant speed, e.g.:
+//
+//	s.MoveTo(90, servo.WithEasing(servo.EaseInOut))
+//
+// The move takes long enough that the servo never exceeds its speed where the
+// curve is steepest. The acceleration and jerk limits of the servo are
+// ignored, since the curve already shapes the speed. A nil curve moves as without
+// the option.
+func WithEasing(ease Easing) MoveOption {
+	return func(m *Move) {
+		m.ease = ease
+	}
+}
+
+// easingSamples is the number of samples used to find the steepest slope of an
+// easing curve.
+const easingSamples = 1000
+
+// eased is a move from the raw position from to to along an easing curve.
+type eased struct {
+	from, to float64
+	ease     Easing
+	time     float64
+}
+
+// planEasing plans a move along the easing curve from the raw position from to
+// to at up to speed.
+func planEasing(from, to, speed float64, ease Easing) eased {
+	e := eased{from: from, to: to, ease: ease}
+	if from == to || speed == 0 {
+		return e
+	}
+	e.time = math.Abs(to-from) / speed * slope(ease)
+	return e
+}
+
+// slope returns the steepest slope of the easing curve, ignoring the samples
+// that are not numbers. It is at least 1, so a move never takes less time than
+// at constant speed.
+func slope(ease Easing) float64 {
+	steepest := 1.0
+	last := ease(0)
+	for i := 1; i <= easingSamples; i++ {
+		y := ease(float64(i) / easingSamples)
+		if d := math.Abs(y-last) * easingSamples; !math.IsNaN(d) && !math.IsInf(d, 0) {
+			steepest = math.Max(steepest, d)
+		}
+		last = y
+	}
+	return steepest
+}
+
+func (e eased) duration() float64 { return e.time }
+
+func (e eased) at(t float64) float64 {
+	if t >= e.time {
+		return e.to
+	}
+	if t <= 0 {
+		return e.from
+	}
+	y := e.ease(t / e.time)
+	if math.IsNaN(y) {
+		return e.from
+	}
+	return e.from + (e.to-e.from)*y
+}
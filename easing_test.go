@@ -0,0 +1,140 @@
+// +build !live
+
+package servo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestEasing(t *testing.T) {
+	tests := []struct {
+		name  string
+		ease  Easing
+		slope float64
+	}{
+		{"EaseIn", EaseIn, 2},
+		{"EaseOut", EaseOut, 2},
+		{"EaseInOut", EaseInOut, 2},
+		{"EaseSine", EaseSine, math.Pi / 2},
+		{"EaseCubic", EaseCubic, 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.ease(0); got != 0 {
+				t.Errorf("wrong start, got: %.4f, want: 0.00", got)
+			}
+			if got := tt.ease(1); math.Abs(got-1) > 1e-12 {
+				t.Errorf("wrong end, got: %.4f, want: 1.00", got)
+			}
+			for x := 0.01; x <= 1; x += 0.01 {
+				if tt.ease(x) < tt.ease(x-0.01) {
+					t.Fatalf("curve goes back at %.2f", x)
+				}
+			}
+			if got := slope(tt.ease); math.Abs(got-tt.slope) > 0.01 {
+				t.Errorf("wrong slope, got: %.3f, want: %.3f", got, tt.slope)
+			}
+		})
+	}
+}
+
+func TestPlanEasing(t *testing.T) {
+	e := planEasing(180, 0, 180, EaseInOut)
+	if math.Abs(e.duration()-2) > 0.01 {
+		t.Errorf("wrong travel time, got: %.3fs, want: 2.000s", e.duration())
+	}
+	if got := e.at(0.5); math.Abs(got-157.5) > 0.5 {
+		t.Errorf("wrong position after 0.5s, got: %.2f, want: 157.50", got)
+	}
+	if got := e.at(1); math.Abs(got-90) > 0.5 {
+		t.Errorf("wrong position after 1s, got: %.2f, want: 90.00", got)
+	}
+	if got := e.at(3); got != 0 {
+		t.Errorf("wrong end, got: %.2f, want: 0.00", got)
+	}
+}
+
+func TestWithEasing(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetMaxSpeed(180)
+
+	start := time.Now()
+	m := s.MoveTo(90, WithEasing(EaseIn))
+	time.Sleep(500 * time.Millisecond)
+	// Half way through, the servo traveled a quarter of the distance.
+	if p := s.Position(); p < 17 || p > 28 {
+		t.Errorf("wrong position half way, got: %.2f, want: about 22.50", p)
+	}
+	m.Wait()
+	if elapsed, want := time.Since(start), 1000*time.Millisecond; elapsed < want-50*time.Millisecond || elapsed > want+60*time.Millisecond {
+		t.Errorf("wrong travel time, got: %v, want: %v", elapsed, want)
+	}
+	if s.Position() != 90 {
+		t.Errorf("servo did not arrive, got: %.2f", s.Position())
+	}
+}
+
+func TestCubicBezier(t *testing.T) {
+	ease := CubicBezier(0.42, 0, 0.58, 1)
+	for _, x := range []float64{0, 0.25, 0.5, 0.75, 1} {
+		if got, want := ease(x), EaseInOut(x); math.Abs(got-want) > 0.05 {
+			t.Errorf("wrong value at %.2f, got: %.3f, want: about %.3f", x, got, want)
+		}
+	}
+
+	linear := CubicBezier(0, 0, 1, 1)
+	for _, x := range []float64{0, 0.3, 0.6, 1} {
+		if got := linear(x); math.Abs(got-x) > 1e-6 {
+			t.Errorf("wrong linear value at %.2f, got: %.4f", x, got)
+		}
+	}
+}
+
+func TestEasingTable(t *testing.T) {
+	ease := EasingTable(0, 0.5, 1.2, 1)
+	tests := []struct{ t, want float64 }{
+		{0, 0},
+		{1.0 / 6, 0.25},
+		{2.0 / 3, 1.2},
+		{5.0 / 6, 1.1},
+		{1, 1},
+	}
+	for _, tt := range tests {
+		if got := ease(tt.t); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("wrong value at %.3f, got: %.3f, want: %.3f", tt.t, got, tt.want)
+		}
+	}
+	if got := EasingTable(1)(0.3); got != 0.3 {
+		t.Errorf("wrong value of a single sample, got: %.2f, want: 0.30", got)
+	}
+}
+
+func TestPlanEasing_custom(t *testing.T) {
+	// An elastic curve overshoots the target, and does not end at 1.0.
+	elastic := func(t float64) float64 {
+		return 1 - math.Cos(6*math.Pi*t)*math.Exp(-4*t)*0.9
+	}
+	e := planEasing(10, 170, 180, elastic)
+	s := New(99)
+	for x := 0.0; x <= e.duration(); x += 0.001 {
+		if p := s.limit(e.at(x)); p < 0 || p > 180 {
+			t.Fatalf("position out of range at %.3fs, got: %.2f", x, p)
+		}
+	}
+	if got := e.at(e.duration()); got != 170 {
+		t.Errorf("wrong end, got: %.2f, want: 170.00", got)
+	}
+
+	nan := planEasing(10, 170, 180, func(t float64) float64 { return math.NaN() })
+	if got := nan.at(nan.duration() / 2); got != 10 {
+		t.Errorf("wrong position of a broken curve, got: %.2f, want: 10.00", got)
+	}
+}
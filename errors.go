@@ -0,0 +1,67 @@
+package servo
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrPinClaimed is returned when a servo is connected or remapped to a
+	// pin used by another servo.
+	ErrPinClaimed = errors.New("pin is claimed by another servo")
+	// ErrClosed is returned when the servo package is used after
+	// servo.Close() was called.
+	ErrClosed = errors.New("servo package is closed")
+	// ErrOutOfRange is returned when a value is outside its allowed range,
+	// e.g. an unsafe pulse.
+	ErrOutOfRange = errors.New("out of range")
+	// ErrBackendUnavailable is returned when the backend that drives the
+	// servos, pi-blaster, is not available.
+	ErrBackendUnavailable = errors.New("backend is unavailable")
+	// ErrTimeout is returned when something cannot happen in time, e.g. a
+	// move that cannot reach its target by a deadline.
+	ErrTimeout = errors.New("timeout")
+	// ErrMaintenance is returned when a move is requested while the servo
+	// package is in maintenance mode.
+	ErrMaintenance = errors.New("maintenance mode")
+	// ErrCycle is returned when a servo would follow itself, directly or
+	// through other servos.
+	ErrCycle = errors.New("servo would follow itself")
+	// ErrUnsupported is returned when the backend does not support an
+	// operation, e.g. changing its pwm frequency.
+	ErrUnsupported = errors.New("not supported by the backend")
+)
+
+// Error is an error of a specific servo. Use errors.Is() to check the
+// sentinel it wraps, e.g.:
+//
+//	if errors.Is(err, servo.ErrPinClaimed) {
+//		// ...
+//	}
+type Error struct {
+	// Name is the name of the servo.
+	Name string
+	// Pin is the GPIO pin of the servo.
+	Pin int
+	// Err is the underlying error.
+	Err error
+}
+
+// Error implements the error interface.
+func (e *Error) Error() string {
+	return fmt.Sprintf("servo %q on gpio(%d): %v", e.Name, e.Pin, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// wrap returns err as an Error of the servo.
+func (s *Servo) wrap(err error) error {
+	return &Error{
+		Name: s.Name,
+		Pin:  s.Pin(),
+		Err:  err,
+	}
+}
@@ -0,0 +1,49 @@
+// +build !live
+
+package servo
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestErrors(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	s.Name = "jaw"
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	unsafe := New(98)
+	unsafe.MaxPulse = 0.5
+	tests := []struct {
+		name string
+		err  error
+		want error
+	}{
+		{"pin claimed", New(99).Connect(), ErrPinClaimed},
+		{"remap claimed", New(98).Remap(99), ErrPinClaimed},
+		{"out of range", unsafe.Connect(), ErrOutOfRange},
+		{"timeout", func() error {
+			_, err := s.MoveToBy(180, Now().Add(-time.Second))
+			return err
+		}(), ErrTimeout},
+		{"backend", errPiBlasterNotFound, ErrBackendUnavailable},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if !errors.Is(test.err, test.want) {
+				t.Errorf("got: %v, want: %v", test.err, test.want)
+			}
+		})
+	}
+
+	var e *Error
+	if err := New(99).Connect(); !errors.As(err, &e) || e.Pin != 99 || e.Name != "Servo99" {
+		t.Errorf("error does not identify the servo: %v", err)
+	}
+}
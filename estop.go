@@ -0,0 +1,110 @@
+package servo
+
+import (
+	"math"
+	"time"
+)
+
+// softStopTick is the interval between the speed updates of a soft stop.
+const softStopTick = 5 * time.Millisecond
+
+// StopAll stops every connected servo instantly.
+func StopAll() {
+	for _, s := range _blaster.connected() {
+		s.Stop()
+	}
+}
+
+// SoftStopAll decelerates every moving servo linearly to a stop within d,
+// instead of stopping them instantly as StopAll() does. It is safer for heavy
+// mechanisms, where an instant stop causes whiplash or tipping. The returned
+// Waiter waits until all the servos have stopped.
+func SoftStopAll(d time.Duration) (wait Waiter) {
+	w := new(waitAll)
+	for _, s := range _blaster.connected() {
+		w.add(s.SoftStop(d))
+	}
+	return w
+}
+
+// SoftStop decelerates the servo linearly to a stop within d. The servo
+// travels half the distance it would have traveled in d at its speed, or less
+// if it reaches its target before. An S-curve or eased move slows down along
+// its path instead. A new move during the deceleration cancels the soft stop.
+// The pending moves of the queue are dropped. The returned Waiter waits until
+// the servo has stopped and its speed is restored.
+func (s *Servo) SoftStop(d time.Duration) (wait Waiter) {
+	s.lock.Lock()
+	if s.idle || d <= 0 {
+		s.lock.Unlock()
+		s.Stop()
+		return s
+	}
+	s.queue = nil
+	step, speed := s.step, s.speed()
+	// The position is only updated by the manager, so add what the servo
+	// moved since then.
+	dist := speed * (d.Seconds()/2 + time.Since(s.deltaT).Seconds())
+	shortened := true
+	if s.planned(s.span) {
+		// Shortening the target would plan a new path from rest, so
+		// only slow the path down.
+		shortened = false
+	} else if s.target > s.position && s.target > s.position+dist {
+		s.target = s.position + dist
+	} else if s.target < s.position && s.target < s.position-dist {
+		s.target = s.position - dist
+	} else {
+		shortened = false
+	}
+	s.publish()
+	if shortened && s.span != nil {
+		// The move ends before its target.
+		s.span.stopping = true
+	}
+	stop := s.target
+	s.lock.Unlock()
+
+	done := make(closed)
+	go func() {
+		defer close(done)
+		s.rampDown(d, step, speed, stop)
+	}()
+
+	return done
+}
+
+// rampDown lowers the speed of the servo from speed to 0 within d, while the
+// servo is moving to the stop target, and then restores its step.
+func (s *Servo) rampDown(d time.Duration, step, speed, stop float64) {
+	ticker := time.NewTicker(softStopTick)
+	defer ticker.Stop()
+
+	start := time.Now()
+	for now := range ticker.C {
+		elapsed := now.Sub(start)
+
+		s.lock.Lock()
+		if s.target != stop {
+			// A new move cancels the soft stop.
+			s.step = step
+			s.lock.Unlock()
+			return
+		}
+		if s.idle || elapsed >= d {
+			s.step = step
+			idle := s.idle
+			s.lock.Unlock()
+			if !idle {
+				s.Stop()
+			}
+			return
+		}
+		// The step holds until the next tick, so use the speed at the
+		// middle of the tick, or the servo travels too far and stops
+		// early.
+		mid := elapsed + softStopTick/2
+		s.step = speed * math.Max(0, 1-mid.Seconds()/d.Seconds())
+		s.lock.Unlock()
+	}
+}
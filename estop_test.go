@@ -0,0 +1,61 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSoftStopAll(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	a, b := New(98), New(99)
+	for _, s := range []*Servo{a, b} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+	}
+	a.MoveTo(180)
+	time.Sleep(100 * time.Millisecond)
+
+	const d = 200 * time.Millisecond
+	from := a.Position()
+	start := time.Now()
+	SoftStopAll(d).Wait()
+	elapsed := time.Since(start)
+
+	if elapsed < d-20*time.Millisecond || elapsed > d+50*time.Millisecond {
+		t.Errorf("soft stop took %v, want: %v", elapsed, d)
+	}
+	// The servo travels half the distance of d at full speed.
+	want := a.maxStep * d.Seconds() / 2
+	if got := a.Position() - from; got < want*0.8 || got > want*1.2 {
+		t.Errorf("wrong stopping distance, got: %.2f, want: %.2f", got, want)
+	}
+	if a.step != a.maxStep {
+		t.Errorf("speed was not restored, got: %.2f, want: %.2f", a.step, a.maxStep)
+	}
+	if b.Position() != 0 {
+		t.Errorf("idle servo moved, got: %.2f", b.Position())
+	}
+}
+
+func TestStopAll(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.MoveTo(180)
+	time.Sleep(50 * time.Millisecond)
+	StopAll()
+	if !s.isIdle() || s.Position() == 180 {
+		t.Errorf("servo was not stopped, at: %.2f", s.Position())
+	}
+}
@@ -0,0 +1,434 @@
+package servo
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// EventType is a bit flag that identifies the kind of an Event. Several
+// types can be combined to filter a Subscription.
+type EventType uint16
+
+const (
+	// EventConnect is sent when a servo is connected to the manager.
+	EventConnect EventType = (1 << iota)
+	// EventDisconnect is sent when a servo is disconnected from the manager.
+	EventDisconnect
+	// EventMove is sent when a servo receives a new target.
+	EventMove
+	// EventPosition is sent every time the manager updates the position of a
+	// moving servo.
+	EventPosition
+	// EventFinish is sent when a servo reaches its target.
+	EventFinish
+	// EventStop is sent when a servo is stopped before reaching its target.
+	EventStop
+	// EventClose is a system event sent when the servo package is closed.
+	EventClose
+	// EventCanary is a system event sent when the canary mode is turned on or
+	// off. See servo.SetCanary().
+	EventCanary
+	// EventFault is sent when a servo enters the fault state. See
+	// Servo.Fault().
+	EventFault
+	// EventDegrade is a system event sent when the manager cannot keep up
+	// with its updates and starts updating low-priority servos less often.
+	// See servo.Degradation().
+	EventDegrade
+	// EventRecover is a system event sent when the manager recovers from
+	// degradation.
+	EventRecover
+
+	// AllEvents matches every event type.
+	AllEvents EventType = (1 << iota) - 1
+)
+
+// is check if any of the given types are set.
+func (t EventType) is(types EventType) bool {
+	return t&types != 0
+}
+
+// String implements the Stringer interface.
+func (t EventType) String() string {
+	if t == 0 {
+		return "( NONE )"
+	}
+
+	names := []string{
+		"Connect",
+		"Disconnect",
+		"Move",
+		"Position",
+		"Finish",
+		"Stop",
+		"Close",
+		"Canary",
+		"Fault",
+		"Degrade",
+		"Recover",
+	}
+
+	s := new(strings.Builder)
+
+	fmt.Fprintf(s, "(")
+	for i, name := range names {
+		if t.is(1 << i) {
+			fmt.Fprintf(s, " %s", name)
+		}
+	}
+	fmt.Fprintf(s, " )")
+
+	return s.String()
+}
+
+// Event holds the information of something that happened to a servo or to
+// the servo package.
+type Event struct {
+	// Type is the kind of event.
+	Type EventType
+	// Servo is the servo that generated the event. It is nil for system
+	// events.
+	Servo *Servo
+	// Position is the position of the servo when the event was generated,
+	// adjusted for its Flags.
+	Position float64
+	// Time is the moment the event was generated.
+	Time time.Time
+	// Meta is the metadata of the move of the servo that generated the event,
+	// if any. See Servo.MoveToWith().
+	Meta Metadata
+}
+
+// String implements the Stringer interface.
+func (e Event) String() string {
+	if e.Servo == nil {
+		return fmt.Sprintf("%v event", e.Type)
+	}
+	return fmt.Sprintf("%v event from %q at %.2f", e.Type, e.Servo.Name, e.Position)
+}
+
+// Subscription receives events from the servo package over a buffered channel.
+// Use servo.Subscribe() for correct initialization.
+type Subscription struct {
+	// dropped is accessed atomically and must be 64-bit aligned.
+	dropped uint64
+
+	// C is the channel where the events are delivered. It is closed when the
+	// subscription or the servo package is closed.
+	C <-chan Event
+
+	c      chan Event
+	types  EventType
+	servos map[*Servo]bool
+	// tag filters the servos by tag if not empty. See Tag.Subscribe().
+	tag Tag
+	bus *bus
+
+	interval time.Duration
+	last     map[*Servo]time.Time
+
+	coalesce bool
+	// queue holds the undelivered events of a coalescing subscription, up
+	// to size events.
+	queue []Event
+	size  int
+	wake  chan struct{}
+	done  chan struct{}
+	ws    *sync.WaitGroup
+
+	lock *sync.Mutex
+}
+
+// match checks if the event passes the filter of the subscription.
+func (s *Subscription) match(e Event) bool {
+	if !e.Type.is(s.types) {
+		return false
+	}
+	if e.Servo == nil {
+		return true
+	}
+	if s.tag != "" && !e.Servo.HasTag(s.tag) {
+		return false
+	}
+	return len(s.servos) == 0 || s.servos[e.Servo]
+}
+
+// send delivers the event without blocking. If the buffer is full, the event
+// is dropped, unless it can be coalesced.
+func (s *Subscription) send(e Event) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if e.Type == EventPosition && s.interval > 0 {
+		if e.Time.Sub(s.last[e.Servo]) < s.interval {
+			return
+		}
+		s.last[e.Servo] = e.Time
+	}
+
+	if s.coalesce {
+		s.enqueue(e)
+		return
+	}
+
+	select {
+	case s.c <- e:
+	default:
+		atomic.AddUint64(&s.dropped, 1)
+	}
+}
+
+// enqueue adds the event to the queue of a coalescing subscription. A position
+// event replaces the previous position event of the same servo if it has not
+// been delivered yet and no other event of that servo came after it.
+func (s *Subscription) enqueue(e Event) {
+	if e.Type == EventPosition {
+		for i := len(s.queue) - 1; i >= 0; i-- {
+			if s.queue[i].Servo != e.Servo {
+				continue
+			}
+			if s.queue[i].Type == EventPosition {
+				s.queue[i] = e
+				return
+			}
+			break
+		}
+	}
+
+	if len(s.queue) >= s.size {
+		atomic.AddUint64(&s.dropped, 1)
+		return
+	}
+	s.queue = append(s.queue, e)
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// deliver moves the queued events of a coalescing subscription to the channel
+// C, blocking until the consumer is ready.
+func (s *Subscription) deliver() {
+	defer s.ws.Done()
+
+	for {
+		s.lock.Lock()
+		if len(s.queue) == 0 {
+			s.lock.Unlock()
+			select {
+			case <-s.wake:
+				continue
+			case <-s.done:
+				return
+			}
+		}
+		e := s.queue[0]
+		s.queue = s.queue[1:]
+		s.lock.Unlock()
+
+		select {
+		case s.c <- e:
+		case <-s.done:
+			return
+		}
+	}
+}
+
+// Sample limits the position events of each servo to at most one every
+// interval. Intermediate position events are discarded, but EventFinish and
+// EventStop still report the final position. An interval of 0 disables
+// sampling. Sample returns the subscription to allow chaining.
+func (s *Subscription) Sample(interval time.Duration) *Subscription {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.interval = interval
+
+	return s
+}
+
+// Coalesce makes the subscription keep only the latest undelivered position
+// event of each servo, so a slow consumer receives fresh positions instead of
+// a backlog of stale ones. Other events are queued up to the buffer size of
+// the subscription (at least 1) and dropped afterwards. Coalesce returns the
+// subscription to allow chaining.
+func (s *Subscription) Coalesce() *Subscription {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.coalesce {
+		return s
+	}
+	select {
+	case <-s.done:
+		// The subscription is already closed.
+		return s
+	default:
+	}
+	s.coalesce = true
+	// An unbuffered subscription still keeps the latest event, which is
+	// handed over when the consumer is ready.
+	s.size = cap(s.c)
+	if s.size == 0 {
+		s.size = 1
+	}
+	s.queue = make([]Event, 0, s.size)
+
+	s.ws.Add(1)
+	go s.deliver()
+
+	return s
+}
+
+// Dropped returns the number of events that were discarded because the buffer
+// of the subscription was full.
+func (s *Subscription) Dropped() uint64 {
+	return atomic.LoadUint64(&s.dropped)
+}
+
+// stop ends the delivery goroutine, if any, and closes the channel C.
+func (s *Subscription) stop() {
+	close(s.done)
+	s.ws.Wait()
+	close(s.c)
+}
+
+// Close stops the delivery of events and closes the channel C. It is safe to
+// call Close more than once.
+func (s *Subscription) Close() {
+	s.bus.unsubscribe(s)
+}
+
+// bus distributes events to all the subscriptions.
+type bus struct {
+	subs   map[*Subscription]struct{}
+	closed bool
+	lock   *sync.RWMutex
+}
+
+func newBus() *bus {
+	return &bus{
+		subs: make(map[*Subscription]struct{}),
+		lock: new(sync.RWMutex),
+	}
+}
+
+// subscribe registers a new subscription to the bus.
+func (b *bus) subscribe(size int, types EventType, servos ...*Servo) *Subscription {
+	return b.subscribeTag(size, types, "", servos...)
+}
+
+// subscribeTag registers a new subscription to the bus. If tag is not empty,
+// only the events of the servos with the tag are received.
+func (b *bus) subscribeTag(size int, types EventType, tag Tag, servos ...*Servo) *Subscription {
+	if types == 0 {
+		types = AllEvents
+	}
+
+	c := make(chan Event, size)
+	s := &Subscription{
+		C:      c,
+		c:      c,
+		types:  types,
+		servos: make(map[*Servo]bool, len(servos)),
+		tag:    tag,
+		bus:    b,
+
+		last: make(map[*Servo]time.Time),
+		wake: make(chan struct{}, 1),
+		done: make(chan struct{}),
+		ws:   new(sync.WaitGroup),
+		lock: new(sync.Mutex),
+	}
+	for _, servo := range servos {
+		s.servos[servo] = true
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.closed {
+		s.stop()
+		return s
+	}
+	b.subs[s] = struct{}{}
+
+	return s
+}
+
+// unsubscribe removes a subscription from the bus and closes its channel.
+func (b *bus) unsubscribe(s *Subscription) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if _, ok := b.subs[s]; !ok {
+		return
+	}
+	delete(b.subs, s)
+	s.stop()
+}
+
+// publish sends the event to every subscription that matches it. It never
+// blocks.
+func (b *bus) publish(e Event) {
+	b.lock.RLock()
+	defer b.lock.RUnlock()
+
+	for s := range b.subs {
+		if s.match(e) {
+			s.send(e)
+		}
+	}
+}
+
+// close removes all the subscriptions from the bus.
+func (b *bus) close() {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	b.closed = true
+	for s := range b.subs {
+		delete(b.subs, s)
+		s.stop()
+	}
+}
+
+// Subscribe returns a Subscription that receives the events of the given types
+// over a channel buffered with size events. If types is 0, all events are
+// received. If servos are given, only the events from those servos (and system
+// events) are received. After servo.Close(), the returned Subscription is
+// already closed.
+//
+// Events are never allowed to block the manager. If the buffer is full, new
+// events are dropped and counted by Subscription.Dropped(). Use
+// Subscription.Sample() and Subscription.Coalesce() to tune the subscription
+// for slow consumers.
+func Subscribe(size int, types EventType, servos ...*Servo) *Subscription {
+	return _blaster.bus.subscribe(size, types, servos...)
+}
+
+// event creates an event of type t for the servo s at the raw position p. It
+// must be called with the servo locked.
+func (s *Servo) event(t EventType, p float64) Event {
+	return Event{
+		Type:     t,
+		Servo:    s,
+		Position: s.adjust(p),
+		Time:     time.Now(),
+		Meta:     s.meta,
+	}
+}
+
+// eventNow creates an event of type t for the servo s at its current position.
+func (s *Servo) eventNow(t EventType) Event {
+	return Event{
+		Type:     t,
+		Servo:    s,
+		Position: s.Position(),
+		Time:     time.Now(),
+	}
+}
@@ -0,0 +1,204 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestEventType_String(t *testing.T) {
+	tests := map[EventType]string{
+		0:                          "( NONE )",
+		EventMove:                  "( Move )",
+		EventConnect | EventFinish: "( Connect Finish )",
+	}
+
+	for input, want := range tests {
+		got := input.String()
+		if got != want {
+			t.Errorf("EventType(%d).String() -> got: %q, want: %q", input, got, want)
+		}
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	const gpio = 99
+	s := New(gpio)
+
+	sub := Subscribe(100, EventConnect|EventMove|EventFinish, s)
+	defer sub.Close()
+
+	other := New(98)
+	otherSub := Subscribe(100, EventPosition, other)
+	defer otherSub.Close()
+
+	err := s.Connect()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.moveTo(10)
+	s.Wait()
+
+	want := []EventType{EventConnect, EventMove, EventFinish}
+	for _, w := range want {
+		select {
+		case e := <-sub.C:
+			if e.Type != w {
+				t.Errorf("wrong event, got: %v, want: %v", e.Type, w)
+			}
+			if e.Servo != s {
+				t.Errorf("wrong servo, got: %v, want: %v", e.Servo, s)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for %v", w)
+		}
+	}
+
+	select {
+	case e := <-otherSub.C:
+		t.Errorf("filtered subscription received %v", e)
+	default:
+	}
+}
+
+func TestSubscription_Dropped(t *testing.T) {
+	b := newBus()
+	sub := b.subscribe(1, EventMove)
+
+	for i := 0; i < 5; i++ {
+		b.publish(Event{Type: EventMove})
+	}
+	if got := sub.Dropped(); got != 4 {
+		t.Errorf("dropped events, got: %d, want: %d", got, 4)
+	}
+
+	sub.Close()
+	sub.Close()
+	if _, ok := <-sub.C; !ok {
+		t.Error("buffered event was lost on Close")
+	}
+	if _, ok := <-sub.C; ok {
+		t.Error("channel was not closed")
+	}
+}
+
+func TestSubscription_Sample(t *testing.T) {
+	b := newBus()
+	sub := b.subscribe(100, EventPosition).Sample(100 * time.Millisecond)
+	defer sub.Close()
+
+	s := New(99)
+	start := time.Now()
+	for i := 0; i < 10; i++ {
+		e := s.event(EventPosition, float64(i))
+		e.Time = start.Add(time.Duration(i) * 30 * time.Millisecond)
+		b.publish(e)
+	}
+
+	// Accepted at 0ms, 120ms and 240ms.
+	want := []float64{0, 4, 8}
+	if len(sub.C) != len(want) {
+		t.Fatalf("sampled events, got: %d, want: %d", len(sub.C), len(want))
+	}
+	for _, w := range want {
+		e := <-sub.C
+		if e.Position != w {
+			t.Errorf("sampled position, got: %.2f, want: %.2f", e.Position, w)
+		}
+	}
+}
+
+func TestSubscription_Coalesce(t *testing.T) {
+	b := newBus()
+	sub := b.subscribe(4, AllEvents).Coalesce()
+	defer sub.Close()
+
+	s := New(99)
+	b.publish(s.event(EventMove, 0))
+	for i := 1; i <= 100; i++ {
+		b.publish(s.event(EventPosition, float64(i)))
+	}
+	b.publish(s.event(EventFinish, 100))
+
+	var got []Event
+	for len(got) == 0 || got[len(got)-1].Type != EventFinish {
+		select {
+		case e := <-sub.C:
+			got = append(got, e)
+		case <-time.After(time.Second):
+			t.Fatalf("timeout waiting for %v, got: %v", EventFinish, got)
+		}
+	}
+
+	// At most 4 events in the channel, 4 queued and the final ones.
+	if len(got) > 10 {
+		t.Errorf("events were not coalesced, got: %d events", len(got))
+	}
+	if got[0].Type != EventMove {
+		t.Errorf("first event, got: %v, want: %v", got[0].Type, EventMove)
+	}
+	last := got[len(got)-2]
+	if last.Type != EventPosition || last.Position != 100 {
+		t.Errorf("latest position was not kept, got: %v", last)
+	}
+	if got := sub.Dropped(); got != 0 {
+		t.Errorf("dropped events, got: %d, want: %d", got, 0)
+	}
+}
+
+func TestSubscription_Coalesce_unbuffered(t *testing.T) {
+	b := newBus()
+	sub := b.subscribe(0, AllEvents).Coalesce()
+	defer sub.Close()
+
+	s := New(99)
+	for i := 1; i <= 100; i++ {
+		b.publish(s.event(EventPosition, float64(i)))
+	}
+
+	for {
+		select {
+		case e := <-sub.C:
+			if e.Position == 100 {
+				return
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("latest position was not delivered, dropped: %d", sub.Dropped())
+		}
+	}
+}
+
+func TestServo_MoveToWith(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	sub := Subscribe(64, EventMove|EventFinish, s)
+	defer sub.Close()
+
+	meta := Metadata{"order": "42"}
+	s.MoveToWith(10, meta).Wait()
+	s.MoveTo(0).Wait()
+
+	for _, want := range []struct {
+		t     EventType
+		order string
+	}{
+		{EventMove, "42"},
+		{EventFinish, "42"},
+		{EventMove, ""},
+		{EventFinish, ""},
+	} {
+		e := <-sub.C
+		if e.Type != want.t || e.Meta["order"] != want.order {
+			t.Errorf("wrong event, got: %v with %v, want: %v with order %q", e, e.Meta, want.t, want.order)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package servo
+
+// SetExpo sets the exponential response of the targets of the servo, from 0.0
+// (linear, default) to 1.0, as the expo of an RC transmitter. With an expo,
+// the targets around the center of the range move the servo less, for a
+// finer control, and the targets towards the ends move it faster, e.g. when a
+// joystick drives the servo directly. The center and the ends of the range
+// are not changed. For example, with Centered and Normalized, a target x
+// moves the servo to (1-expo)*x + expo*x³.
+//
+// The expo applies to the targets of MoveTo() and Track(), but not to
+// SetPosition() or to the positions reported by the servo.
+func (s *Servo) SetExpo(expo float64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.expo = clamp(expo, 0, 1)
+}
+
+// Expo returns the exponential response of the targets of the servo.
+func (s *Servo) Expo() float64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.expo
+}
+
+// applyExpo applies the expo of the servo to the raw target. It must be called
+// with the servo locked.
+func (s *Servo) applyExpo(target float64) float64 {
+	if s.expo == 0 {
+		return target
+	}
+	half := s.fullRange() / 2
+	x := (target - half) / half
+	return half + half*((1-s.expo)*x+s.expo*x*x*x)
+}
+
+// removeExpo returns the raw target that the expo of the servo maps to the raw
+// target, the inverse of applyExpo. It must be called with the servo locked.
+func (s *Servo) removeExpo(target float64) float64 {
+	if s.expo == 0 {
+		return target
+	}
+	// The curve is monotonic, so it is inverted by bisection.
+	lo, hi := 0.0, s.fullRange()
+	for i := 0; i < 60; i++ {
+		mid := (lo + hi) / 2
+		if s.applyExpo(mid) < target {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
@@ -0,0 +1,112 @@
+// +build !live
+
+package servo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestServo_applyExpo(t *testing.T) {
+	s := New(99)
+	s.SetExpo(0.5)
+
+	tests := []struct{ target, want float64 }{
+		{0, 0},
+		{90, 90},
+		{180, 180},
+		// x = 0.5: 0.5*0.5 + 0.5*0.125 = 0.3125
+		{135, 118.125},
+		{45, 61.875},
+	}
+	for _, tt := range tests {
+		if got := s.applyExpo(tt.target); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("applyExpo(%.2f), got: %.3f, want: %.3f", tt.target, got, tt.want)
+		}
+	}
+
+	s.SetExpo(2)
+	if e := s.Expo(); e != 1 {
+		t.Errorf("wrong expo, got: %.2f, want: 1.00", e)
+	}
+}
+
+func TestServo_SetExpo(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	s.Flags = Centered | Normalized
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetExpo(1)
+
+	s.MoveTo(0.5).Wait()
+	if p := s.Position(); math.Abs(p-0.125) > 1e-9 {
+		t.Errorf("expo was not applied, got: %.4f, want: 0.1250", p)
+	}
+	s.MoveTo(-1).Wait()
+	if p := s.Position(); math.Abs(p+1) > 1e-9 {
+		t.Errorf("expo changed the end of the range, got: %.4f, want: -1.0000", p)
+	}
+}
+
+func TestServo_SetExpo_moveBy(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	s.Flags = Centered | Normalized
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetExpo(0.5)
+
+	s.MoveTo(0.5).Wait()
+	want := s.Position()
+	s.MoveBy(0).Wait()
+	if p := s.Position(); math.Abs(p-want) > 1e-6 {
+		t.Errorf("MoveBy(0) moved the servo, got: %.4f, want: %.4f", p, want)
+	}
+	s.MoveBy(0.1).Wait()
+	s.lock.RLock()
+	expected := s.adjust(s.applyExpo(s.raw(0.6)))
+	s.lock.RUnlock()
+	if p := s.Position(); math.Abs(p-expected) > 1e-6 {
+		t.Errorf("MoveBy(0.1) did not move from the commanded target, got: %.4f, want: %.4f", p, expected)
+	}
+
+	s.lock.RLock()
+	for _, raw := range []float64{0, 30, 90, 100, 180} {
+		if got := s.removeExpo(s.applyExpo(raw)); math.Abs(got-raw) > 1e-6 {
+			t.Errorf("removeExpo(applyExpo(%.2f)), got: %.4f", raw, got)
+		}
+	}
+	s.lock.RUnlock()
+}
+
+func TestServo_SetExpo_travel(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	s.Flags = Centered | Normalized
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetMaxSpeed(90)
+	s.SetExpo(1)
+	s.SetPosition(0)
+	s.Wait()
+
+	// The target 0.5 is driven to 0.125, 11.25 degrees from the center.
+	want := time.Duration(11.25 / 90 * float64(time.Second))
+	if d := s.travel(0.5); d < want-time.Millisecond || d > want+time.Millisecond {
+		t.Errorf("wrong travel estimate, got: %v, want: %v", d, want)
+	}
+	if d := s.duration(0.5, nil); math.Abs(d-want.Seconds()) > 1e-3 {
+		t.Errorf("wrong duration, got: %.3fs, want: %.3fs", d, want.Seconds())
+	}
+}
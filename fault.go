@@ -0,0 +1,99 @@
+package servo
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+// writeFailed counts a write error of the servo and trips the fault state
+// after MaxErrors consecutive errors.
+func (s *Servo) writeFailed(err error) {
+	s.lock.Lock()
+	s.errors++
+	s.counters.WriteErrors++
+	trip := s.MaxErrors > 0 && s.errors >= s.MaxErrors && s.fault == nil
+	n := s.errors
+	s.lock.Unlock()
+
+	if trip {
+		s.SetFault(fmt.Errorf("%d consecutive write errors, last: %w", n, err))
+	}
+}
+
+// SetFault puts the servo in the fault state with the given cause, e.g. when
+// an external sensor detects a stall. A servo in the fault state stops moving,
+// its pin is released (0 duty) and any new target is ignored until
+// ClearFault() is called. The rest of the servos are not affected.
+//
+// A servo also enters the fault state by itself after MaxErrors consecutive
+// write errors.
+func (s *Servo) SetFault(err error) {
+	s.lock.Lock()
+	if s.fault != nil {
+		s.lock.Unlock()
+		return
+	}
+	s.fault = err
+	s.counters.Faults++
+	s.target = s.position
+	s.velocity = 0
+	s.queue = nil
+	s.idle = true
+	s.publish()
+	s.finish()
+	atomic.StoreInt32(&s.release, 1)
+	_blaster.wakeUp()
+	e := s.event(EventFault, s.position)
+	s.meta = nil
+	span := s.span
+	s.span = nil
+	s.lock.Unlock()
+
+	endMove(span, MoveFaulted)
+	debugf("%v fault: %v", s, err)
+	_blaster.bus.publish(e)
+}
+
+// Fault returns the cause of the fault state of the servo, or nil if the
+// servo is working normally.
+func (s *Servo) Fault() error {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.fault
+}
+
+// ClearFault takes the servo out of the fault state. The servo holds its last
+// position again on the next update.
+func (s *Servo) ClearFault() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.fault = nil
+	s.errors = 0
+	atomic.StoreInt32(&s.release, 0)
+	// Force the manager to write the current position.
+	s.idle = false
+	s.publish()
+	_blaster.wakeUp()
+}
+
+// takeRelease checks if the manager should release the pin of the servo. It
+// returns true only once per release.
+func (s *Servo) takeRelease() bool {
+	return atomic.LoadInt32(&s.release) == 1 && atomic.CompareAndSwapInt32(&s.release, 1, 2)
+}
+
+// releasePending checks if the manager has yet to release the pin of the
+// servo.
+func (s *Servo) releasePending() bool {
+	return atomic.LoadInt32(&s.release) == 1
+}
+
+// driven marks the pin of the servo as driven again after it was released,
+// e.g. by a new move after the watchdog released it, so the servo is
+// resynchronized like the others. It must be called by the manager after
+// writing the pwm of the servo.
+func (s *Servo) driven() {
+	atomic.CompareAndSwapInt32(&s.release, 2, 0)
+}
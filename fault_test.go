@@ -0,0 +1,96 @@
+// +build !live
+
+package servo
+
+import (
+	"errors"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// errBrokenPipe is the error of a failWriter.
+var errBrokenPipe = errors.New("broken pipe")
+
+// failWriter fails every write while fail is set.
+type failWriter struct {
+	fail int32
+	syncBuffer
+}
+
+func (w *failWriter) Write(p []byte) (int, error) {
+	if atomic.LoadInt32(&w.fail) != 0 {
+		return 0, errBrokenPipe
+	}
+	return w.syncBuffer.Write(p)
+}
+
+func TestServo_Fault(t *testing.T) {
+	b := useBlaster(t)
+	w := &failWriter{fail: 1}
+	b.pi.sink = w
+	Rate(time.Millisecond)
+
+	s := New(99)
+	s.MaxErrors = 3
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	sub := Subscribe(1, EventFault, s)
+	defer sub.Close()
+
+	s.moveTo(180)
+	select {
+	case <-sub.C:
+	case <-time.After(time.Second):
+		t.Fatal("servo did not enter the fault state")
+	}
+
+	if err := s.Fault(); !errors.Is(err, errBrokenPipe) {
+		t.Errorf("fault cause, got: %v", err)
+	}
+	p := s.Position()
+	s.Wait()
+	s.moveTo(0)
+	s.SetPosition(0)
+	time.Sleep(10 * time.Millisecond)
+	if s.Position() != p || !s.isIdle() {
+		t.Errorf("servo moved in the fault state, got: %.2f, want: %.2f", s.Position(), p)
+	}
+
+	atomic.StoreInt32(&w.fail, 0)
+	s.ClearFault()
+	if err := s.Fault(); err != nil {
+		t.Errorf("fault was not cleared, got: %v", err)
+	}
+	s.moveTo(0)
+	s.Wait()
+	if s.Position() != 0 {
+		t.Errorf("servo did not move after ClearFault, got: %.2f, want: %.2f", s.Position(), 0.0)
+	}
+}
+
+func TestServo_SetFault(t *testing.T) {
+	b := useBlaster(t)
+	out := new(syncBuffer)
+	b.pi.sink = out
+	Rate(time.Millisecond)
+
+	s := New(99)
+	s.SetPosition(90)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	time.Sleep(10 * time.Millisecond)
+
+	s.SetFault(errors.New("stall"))
+	time.Sleep(20 * time.Millisecond)
+
+	if !strings.HasSuffix(out.String(), " 99=0.000000\n") {
+		t.Errorf("pin was not released, got: %q", out.String())
+	}
+}
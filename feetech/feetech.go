@@ -0,0 +1,358 @@
+// Package feetech drives Feetech SCS bus servos (e.g. SCS0009 or SCS15)
+// through their half-duplex UART, e.g. with the FE-URT-1 board.
+//
+// Set a Controller as the backend of the servo package, and use the ID of
+// each servo as its pin:
+//
+//	ft, err := feetech.Open("/dev/ttyUSB0", 1000000)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	servo.SetBackend(ft)
+//	defer servo.Close()
+//
+//	s := servo.New(1) // SCS servo with ID 1
+//
+// The position of the servos can be read back, e.g. to correct the position
+// estimated by the servo package:
+//
+//	s.Sync(ft.Feedback(s))
+package feetech
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+const (
+	// MinPulse and MaxPulse are the default pulses of the servo package at
+	// 0 and 180 degrees.
+	MinPulse = 500 * time.Microsecond
+	MaxPulse = 2500 * time.Microsecond
+	// MinPosition and MaxPosition are the default goal positions at
+	// MinPulse and MaxPulse: 180 degrees centered in the 300 degrees of an
+	// SCS0009.
+	MinPosition = 205
+	MaxPosition = 819
+
+	// readTimeout is how long to wait for the answer of a servo.
+	readTimeout = 100 * time.Millisecond
+)
+
+// Instructions, registers and IDs of the SCS protocol.
+const (
+	instRead      = 0x02
+	instSyncWrite = 0x83
+
+	regTorqueEnable    = 0x28
+	regGoalPosition    = 0x2A
+	regPresentPosition = 0x38
+
+	broadcastID = 0xFE
+	maxID       = 0xFD
+)
+
+// Controller is a bus of Feetech SCS servos. It implements the servo.Backend
+// interface. Use feetech.Open() or feetech.New() for correct initialization.
+type Controller struct {
+	// MinPulse and MaxPulse are mapped to MinPosition and MaxPosition. The
+	// pulses in between are mapped linearly, so the calibration of the
+	// servo package still applies.
+	MinPulse, MaxPulse time.Duration
+	// MinPosition and MaxPosition are the goal positions at MinPulse and
+	// MaxPulse, from 0 to 1023.
+	MinPosition, MaxPosition int
+
+	rw io.ReadWriter
+	// torque are the IDs with the torque enabled by the controller.
+	torque map[int]bool
+	lock   *sync.Mutex
+}
+
+// New creates a Controller that writes the instruction packets to rw and
+// reads the answers of the servos from it.
+func New(rw io.ReadWriter) *Controller {
+	return &Controller{
+		MinPulse:    MinPulse,
+		MaxPulse:    MaxPulse,
+		MinPosition: MinPosition,
+		MaxPosition: MaxPosition,
+		rw:          rw,
+		torque:      make(map[int]bool),
+		lock:        new(sync.Mutex),
+	}
+}
+
+// Write implements the servo.Backend interface. The goal positions of all the
+// servos are sent in a single sync write packet. A pulse of 0 disables the
+// torque of the servo, which is enabled again on its next pulse.
+func (c *Controller) Write(pulses []servo.Pulse) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, p := range pulses {
+		if p.Pin < 0 || p.Pin > maxID {
+			return fmt.Errorf("feetech: ID %d is outside the range 0 to %d", p.Pin, maxID)
+		}
+	}
+
+	var on, off, goals [][]byte
+	for _, p := range pulses {
+		id := byte(p.Pin)
+		if p.Width == 0 {
+			if c.torque[p.Pin] {
+				off = append(off, []byte{id, 0})
+				delete(c.torque, p.Pin)
+			}
+			continue
+		}
+		if !c.torque[p.Pin] {
+			on = append(on, []byte{id, 1})
+			c.torque[p.Pin] = true
+		}
+		// The registers of the SCS series are big-endian.
+		pos := c.position(p.Width)
+		goals = append(goals, []byte{id, byte(pos >> 8), byte(pos)})
+	}
+
+	var msg []byte
+	if len(off) > 0 {
+		msg = append(msg, syncWrite(regTorqueEnable, off)...)
+	}
+	if len(on) > 0 {
+		msg = append(msg, syncWrite(regTorqueEnable, on)...)
+	}
+	if len(goals) > 0 {
+		msg = append(msg, syncWrite(regGoalPosition, goals)...)
+	}
+
+	return c.write(msg)
+}
+
+// Close implements the servo.Backend interface. It disables the torque of the
+// servos driven so far. If the ReadWriter is an io.Closer, it is closed too.
+func (c *Controller) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	ids := make([]int, 0, len(c.torque))
+	for id := range c.torque {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	off := make([][]byte, len(ids))
+	for i, id := range ids {
+		off[i] = []byte{byte(id), 0}
+	}
+	c.torque = make(map[int]bool)
+
+	var err error
+	if len(off) > 0 {
+		err = c.write(syncWrite(regTorqueEnable, off))
+	}
+	if closer, ok := c.rw.(io.Closer); ok {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// ReadPosition reads the present position of the servo with the ID, from 0
+// to 1023.
+func (c *Controller) ReadPosition(id int) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	req := packet(byte(id), instRead, regPresentPosition, 2)
+	if err := c.write(req); err != nil {
+		return 0, err
+	}
+	params, err := c.read(req, byte(id), 2)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(params[0])<<8 | int(params[1]), nil
+}
+
+// Feedback returns the servo.Feedback of the servo, which reads the position
+// of the SCS servo with the pin of the servo as its ID.
+func (c *Controller) Feedback(s *servo.Servo) servo.Feedback {
+	return feedback{c, s}
+}
+
+// feedback reads the angle of a servo from its SCS servo.
+type feedback struct {
+	c *Controller
+	s *servo.Servo
+}
+
+// Angle implements the servo.Feedback interface.
+func (f feedback) Angle() (float64, error) {
+	pos, err := f.c.ReadPosition(f.s.Pin())
+	if err != nil {
+		return 0, err
+	}
+	return f.s.PulseAngle(f.c.pulse(pos)), nil
+}
+
+// position maps the pulse to a goal position, clamped from 0 to 1023.
+func (c *Controller) position(width time.Duration) int {
+	span := float64(c.MaxPulse - c.MinPulse)
+	if span == 0 {
+		return c.MinPosition
+	}
+	f := float64(width-c.MinPulse) / span
+	pos := c.MinPosition + int(f*float64(c.MaxPosition-c.MinPosition)+0.5)
+
+	switch {
+	case pos < 0:
+		return 0
+	case pos > 1023:
+		return 1023
+	}
+	return pos
+}
+
+// pulse maps the position to a pulse. It is the inverse of position.
+func (c *Controller) pulse(pos int) time.Duration {
+	span := float64(c.MaxPosition - c.MinPosition)
+	if span == 0 {
+		return c.MinPulse
+	}
+	f := float64(pos-c.MinPosition) / span
+	return c.MinPulse + time.Duration(f*float64(c.MaxPulse-c.MinPulse))
+}
+
+// syncWrite returns the sync write packet that writes the data of each entry
+// at addr. Each entry is the ID of a servo followed by its data, and all the
+// entries must have the same length.
+func syncWrite(addr byte, entries [][]byte) []byte {
+	params := []byte{addr, byte(len(entries[0]) - 1)}
+	for _, e := range entries {
+		params = append(params, e...)
+	}
+	return packet(broadcastID, instSyncWrite, params...)
+}
+
+// packet returns the instruction packet with its checksum.
+func packet(id, inst byte, params ...byte) []byte {
+	p := []byte{0xFF, 0xFF, id, byte(len(params) + 2), inst}
+	p = append(p, params...)
+	return append(p, checksum(p[2:]))
+}
+
+// checksum returns the checksum of the bytes between the header and the
+// checksum of a packet.
+func checksum(b []byte) byte {
+	var sum byte
+	for _, v := range b {
+		sum += v
+	}
+	return ^sum
+}
+
+// read reads the status packet of the servo id, with n parameters. The echo
+// of the request req, sent back by some half-duplex adapters, is skipped.
+func (c *Controller) read(req []byte, id byte, n int) ([]byte, error) {
+	r := &deadlineReader{r: c.rw, deadline: time.Now().Add(readTimeout)}
+	for {
+		p, err := r.packet()
+		if err != nil {
+			return nil, fmt.Errorf("feetech: no answer from ID %d: %v", id, err)
+		}
+		if bytes.Equal(p, req) {
+			continue
+		}
+		if checksum(p[2:len(p)-1]) != p[len(p)-1] {
+			return nil, fmt.Errorf("feetech: wrong checksum from ID %d", id)
+		}
+		if p[2] != id || len(p) != n+6 {
+			continue
+		}
+		if p[4] != 0 {
+			return nil, fmt.Errorf("feetech: ID %d answered with error 0x%02x", id, p[4])
+		}
+		return p[5 : 5+n], nil
+	}
+}
+
+// deadlineReader reads packets from r until the deadline.
+type deadlineReader struct {
+	r        io.Reader
+	deadline time.Time
+	buf      [1]byte
+}
+
+// readByte reads a single byte, retrying reads of 0 bytes until the
+// deadline.
+func (r *deadlineReader) readByte() (byte, error) {
+	for time.Now().Before(r.deadline) {
+		n, err := r.r.Read(r.buf[:])
+		if n == 1 {
+			return r.buf[0], nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return 0, fmt.Errorf("timeout")
+}
+
+// packet reads the next packet, including its header.
+func (r *deadlineReader) packet() ([]byte, error) {
+	var prev byte
+	for {
+		b, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if prev == 0xFF && b == 0xFF {
+			break
+		}
+		prev = b
+	}
+
+	p := []byte{0xFF, 0xFF}
+	for i := 0; i < 2; i++ {
+		b, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		p = append(p, b)
+	}
+	// The length counts the instruction or error, the parameters and the
+	// checksum.
+	if p[3] < 2 {
+		return nil, fmt.Errorf("invalid length %d", p[3])
+	}
+	for i := 0; i < int(p[3]); i++ {
+		b, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		p = append(p, b)
+	}
+
+	return p, nil
+}
+
+// write sends the packets to the bus.
+func (c *Controller) write(msg []byte) error {
+	if len(msg) == 0 {
+		return nil
+	}
+	if _, err := c.rw.Write(msg); err != nil {
+		return fmt.Errorf("feetech: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// +build !live
+
+package feetech
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+func TestController(t *testing.T) {
+	out := new(bytes.Buffer)
+	c := New(out)
+
+	err := c.Write([]servo.Pulse{{Pin: 1, Width: 1500 * time.Microsecond}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := append(
+		packet(broadcastID, instSyncWrite, regTorqueEnable, 1, 1, 1),
+		packet(broadcastID, instSyncWrite, regGoalPosition, 2, 1, 0x02, 0x00)...,
+	)
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("wrong packets\ngot:  % X\nwant: % X", got, want)
+	}
+
+	out.Reset()
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want = packet(broadcastID, instSyncWrite, regTorqueEnable, 1, 1, 0)
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("torque was not disabled\ngot:  % X\nwant: % X", got, want)
+	}
+}
+
+func TestController_Feedback(t *testing.T) {
+	bus := new(bytes.Buffer)
+	c := New(bus)
+
+	// Status packet of ID 1 at position 205.
+	bus.Write(packet(1, 0, 0x00, 0xCD))
+
+	s := servo.New(1)
+	got, err := c.Feedback(s).Angle()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(got) > 1e-9 {
+		t.Errorf("wrong angle, got: %.2f, want: %.2f", got, 0.0)
+	}
+
+	bus.Reset()
+	bus.Write(packet(1, 0x20, 0x00, 0xCD))
+	if _, err := c.ReadPosition(1); err == nil {
+		t.Error("status with error did not fail")
+	}
+}
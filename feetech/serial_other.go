@@ -0,0 +1,10 @@
+// +build !linux
+
+package feetech
+
+import "fmt"
+
+// Open is only supported on Linux.
+func Open(path string, baud int) (*Controller, error) {
+	return nil, fmt.Errorf("feetech: serial ports are only supported on linux")
+}
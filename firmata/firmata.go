@@ -0,0 +1,208 @@
+// Package firmata drives servos connected to a microcontroller (e.g. an
+// Arduino) running Firmata, over a serial port.
+//
+// Set a Controller as the backend of the servo package, and use the pin of
+// the board as the pin of each servo:
+//
+//	fm, err := firmata.Open("/dev/ttyACM0")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	servo.SetBackend(fm)
+//	defer servo.Close()
+//
+//	s := servo.New(9) // pin 9 of the board
+package firmata
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+const (
+	// Baud is the default baud rate of StandardFirmata.
+	Baud = 57600
+
+	// MinPulse and MaxPulse are the default pulse limits of the Servo
+	// library of Arduino.
+	MinPulse = 544 * time.Microsecond
+	MaxPulse = 2400 * time.Microsecond
+)
+
+// Firmata commands.
+const (
+	analogMessage  = 0xE0
+	setPinMode     = 0xF4
+	reportVersion  = 0xF9
+	startSysex     = 0xF0
+	endSysex       = 0xF7
+	extendedAnalog = 0x6F
+	servoConfig    = 0x70
+
+	modeOutput = 0x01
+)
+
+// Controller is a board running Firmata. It implements the servo.Backend
+// interface. Use firmata.Open() or firmata.New() for correct initialization.
+type Controller struct {
+	// MinPulse and MaxPulse are the pulse limits the pins are attached
+	// with. The board clamps the pulses to these limits. Pulses below 544us
+	// are not supported by Firmata, as they are read as angles.
+	MinPulse, MaxPulse time.Duration
+
+	w io.Writer
+	// version is the protocol version reported by the board.
+	version string
+	// attached are the pins attached as servos.
+	attached map[int]bool
+	lock     *sync.Mutex
+}
+
+// New creates a Controller that writes the Firmata messages to w.
+func New(w io.Writer) *Controller {
+	return &Controller{
+		MinPulse: MinPulse,
+		MaxPulse: MaxPulse,
+		w:        w,
+		attached: make(map[int]bool),
+		lock:     new(sync.Mutex),
+	}
+}
+
+// Version returns the Firmata protocol version reported by the board when it
+// was opened, or an empty string if unknown.
+func (c *Controller) Version() string {
+	return c.version
+}
+
+// Write implements the servo.Backend interface. A pin is attached as a servo
+// on its first pulse and detached with a pulse of 0, which turns it into a
+// digital output. All the messages are sent in a single write.
+func (c *Controller) Write(pulses []servo.Pulse) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var msg []byte
+	for _, p := range pulses {
+		if p.Width == 0 {
+			if c.attached[p.Pin] {
+				msg = append(msg, setPinMode, byte(p.Pin), modeOutput)
+				delete(c.attached, p.Pin)
+			}
+			continue
+		}
+		if !c.attached[p.Pin] {
+			msg = c.attach(msg, p.Pin)
+			c.attached[p.Pin] = true
+		}
+		msg = analog(msg, p.Pin, uint(p.Width/time.Microsecond))
+	}
+
+	return c.write(msg)
+}
+
+// Close implements the servo.Backend interface. It detaches the attached
+// pins. If the writer is an io.Closer, it is closed too.
+func (c *Controller) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	pins := make([]int, 0, len(c.attached))
+	for pin := range c.attached {
+		pins = append(pins, pin)
+	}
+	sort.Ints(pins)
+
+	var msg []byte
+	for _, pin := range pins {
+		msg = append(msg, setPinMode, byte(pin), modeOutput)
+	}
+	c.attached = make(map[int]bool)
+
+	err := c.write(msg)
+	if closer, ok := c.w.(io.Closer); ok {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// attach appends the servo configuration of pin to msg, which also sets the
+// pin in servo mode.
+func (c *Controller) attach(msg []byte, pin int) []byte {
+	min := uint(c.MinPulse / time.Microsecond)
+	max := uint(c.MaxPulse / time.Microsecond)
+	return append(msg, startSysex, servoConfig, byte(pin),
+		byte(min&0x7F), byte(min>>7&0x7F),
+		byte(max&0x7F), byte(max>>7&0x7F),
+		endSysex)
+}
+
+// analog appends the analog message that sets pin to value to msg. The short
+// message only addresses pins 0 to 15 and 14-bit values, otherwise the
+// extended analog message is used.
+func analog(msg []byte, pin int, value uint) []byte {
+	if pin < 16 && value < 1<<14 {
+		return append(msg, analogMessage|byte(pin), byte(value&0x7F), byte(value>>7&0x7F))
+	}
+
+	msg = append(msg, startSysex, extendedAnalog, byte(pin))
+	for {
+		msg = append(msg, byte(value&0x7F))
+		value >>= 7
+		if value == 0 {
+			break
+		}
+	}
+	return append(msg, endSysex)
+}
+
+// write sends the messages to the board.
+func (c *Controller) write(msg []byte) error {
+	if len(msg) == 0 {
+		return nil
+	}
+	if _, err := c.w.Write(msg); err != nil {
+		return fmt.Errorf("firmata: %v", err)
+	}
+	return nil
+}
+
+// waitVersion reads from r until the board reports its protocol version, and
+// returns it as "major.minor". A read of 0 bytes is retried until the
+// deadline, as the board can take a while to boot after the port is opened.
+func waitVersion(r io.Reader, deadline time.Time) (string, error) {
+	buf := make([]byte, 1)
+	var got []byte
+	for time.Now().Before(deadline) {
+		n, err := r.Read(buf)
+		if err != nil && err != io.EOF {
+			return "", fmt.Errorf("firmata: %v", err)
+		}
+		if n == 0 {
+			if err == io.EOF {
+				break
+			}
+			continue
+		}
+
+		switch {
+		case buf[0] == reportVersion:
+			got = []byte{reportVersion}
+		case len(got) > 0:
+			got = append(got, buf[0])
+		}
+		if len(got) == 3 {
+			return fmt.Sprintf("%d.%d", got[1], got[2]), nil
+		}
+	}
+
+	return "", fmt.Errorf("firmata: the board did not report its version")
+}
@@ -0,0 +1,71 @@
+// +build !live
+
+package firmata
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+func TestController(t *testing.T) {
+	out := new(bytes.Buffer)
+	c := New(out)
+
+	err := c.Write([]servo.Pulse{
+		{Pin: 9, Width: 1500 * time.Microsecond},
+		{Pin: 20, Width: 1000 * time.Microsecond},
+		{Pin: 3, Width: 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{
+		// servo config of pin 9: 544us to 2400us
+		0xF0, 0x70, 9, 0x20, 0x04, 0x60, 0x12, 0xF7,
+		// analog pin 9: 1500us
+		0xE9, 0x5C, 0x0B,
+		// servo config of pin 20
+		0xF0, 0x70, 20, 0x20, 0x04, 0x60, 0x12, 0xF7,
+		// extended analog pin 20: 1000us
+		0xF0, 0x6F, 20, 0x68, 0x07, 0xF7,
+	}
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("wrong messages\ngot:  % X\nwant: % X", got, want)
+	}
+
+	out.Reset()
+	if err := c.Write([]servo.Pulse{{Pin: 9, Width: 2000 * time.Microsecond}, {Pin: 20, Width: 0}}); err != nil {
+		t.Fatal(err)
+	}
+	want = []byte{0xE9, 0x50, 0x0F, 0xF4, 20, 0x01}
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("attached pins were configured again\ngot:  % X\nwant: % X", got, want)
+	}
+
+	out.Reset()
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want = []byte{0xF4, 9, 0x01}
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("pins were not detached\ngot:  % X\nwant: % X", got, want)
+	}
+}
+
+func TestWaitVersion(t *testing.T) {
+	r := bytes.NewReader([]byte{0x00, 0xF9, 2, 5, 0xF0, 0x79})
+	got, err := waitVersion(r, time.Now().Add(time.Second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != "2.5" {
+		t.Errorf("wrong version, got: %q, want: %q", got, "2.5")
+	}
+
+	if _, err := waitVersion(bytes.NewReader([]byte{0xF9, 2}), time.Now().Add(time.Second)); err == nil {
+		t.Error("incomplete version report did not fail")
+	}
+}
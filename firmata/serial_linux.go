@@ -0,0 +1,73 @@
+// +build linux
+
+package firmata
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"time"
+	"unsafe"
+)
+
+const (
+	// bootTimeout is how long to wait for the board to report its version.
+	// Most boards reset when the port is opened and take a couple of seconds
+	// to boot.
+	bootTimeout = 3 * time.Second
+	// cbaud is the mask of the baud rate in the control flags of Linux.
+	cbaud = 0x100f
+)
+
+// Open opens the board at the serial port path (e.g. "/dev/ttyACM0" or
+// "/dev/ttyUSB0") at the baud rate of StandardFirmata, and waits until the
+// board reports its version.
+func Open(path string) (*Controller, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := raw(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("firmata: could not configure %s: %v", path, err)
+	}
+
+	// Ask for the version in case the board did not reset.
+	if _, err := f.Write([]byte{reportVersion}); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("firmata: %v", err)
+	}
+	version, err := waitVersion(f, time.Now().Add(bootTimeout))
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	c := New(f)
+	c.version = version
+	return c, nil
+}
+
+// raw sets the serial port in raw mode, 8N1 at Baud. Reads return after 0.1s
+// without data.
+func raw(f *os.File) error {
+	var t syscall.Termios
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&t))); errno != 0 {
+		return errno
+	}
+
+	t.Iflag = 0
+	t.Oflag = 0
+	t.Lflag = 0
+	t.Cflag &^= cbaud | syscall.CSIZE | syscall.PARENB | syscall.CSTOPB
+	t.Cflag |= syscall.B57600 | syscall.CS8 | syscall.CREAD | syscall.CLOCAL
+	t.Ispeed = syscall.B57600
+	t.Ospeed = syscall.B57600
+	t.Cc[syscall.VMIN] = 0
+	t.Cc[syscall.VTIME] = 1
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TCSETS, uintptr(unsafe.Pointer(&t))); errno != 0 {
+		return errno
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+// +build !linux
+
+package firmata
+
+import "fmt"
+
+// Open is only supported on Linux.
+func Open(path string) (*Controller, error) {
+	return nil, fmt.Errorf("firmata: serial ports are only supported on linux")
+}
@@ -0,0 +1,114 @@
+package servo
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// followLock serializes the calls to Follow(), so two servos cannot both pass
+// the cycle check and then follow each other.
+var followLock sync.Mutex
+
+// Follow makes the servo mirror the position of master, e.g. two servos on
+// either side of a jaw. The angle of the servo, adjusted for its Flags, is
+// ratio times the angle of master, adjusted for its Flags, plus offset, e.g.
+// a ratio of -1 and an offset of 180 for a reversed servo. The manager updates
+// the servo in the same tick as master, so it never lags behind.
+//
+// While following, the moves of the servo end right away as stopped, and its
+// speed and acceleration limits are ignored. The layers of master are not
+// mirrored. It returns ErrCycle if master follows the servo, directly or
+// not, or is the servo itself.
+func (s *Servo) Follow(master *Servo, ratio, offset float64) error {
+	followLock.Lock()
+	for m := master; m != nil; m = m.leader() {
+		if m == s {
+			followLock.Unlock()
+			return s.wrap(fmt.Errorf("%w: %v", ErrCycle, master))
+		}
+	}
+
+	s.lock.Lock()
+	s.master = master
+	s.ratio, s.offset = ratio, offset
+	s.queue = nil
+	span := s.span
+	s.span = nil
+	s.idle = false
+	s.publish()
+	s.lock.Unlock()
+	followLock.Unlock()
+	_blaster.wakeUp()
+
+	endMove(span, MoveStopped)
+	return nil
+}
+
+// Unfollow stops mirroring the master of the servo, if any. The servo stays
+// where it is.
+func (s *Servo) Unfollow() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.master = nil
+}
+
+// leader returns the servo mirrored by the servo, or nil.
+func (s *Servo) leader() *Servo {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.master
+}
+
+// depth returns the number of leaders above the servo.
+func (s *Servo) depth() int {
+	n := 0
+	for m := s.leader(); m != nil; m = m.leader() {
+		n++
+	}
+	return n
+}
+
+// sortFollowers sorts the followers so every leader comes before its
+// followers.
+func sortFollowers(followers []*Servo) {
+	depths := make(map[*Servo]int, len(followers))
+	for _, f := range followers {
+		depths[f] = f.depth()
+	}
+	sort.Slice(followers, func(i, j int) bool {
+		return depths[followers[i]] < depths[followers[j]]
+	})
+}
+
+// mirror sets the position of the servo from the position of its master,
+// which must already be updated in this tick. It returns false if the servo
+// does not follow anymore, or is in the fault state. It must be called by the
+// manager.
+func (s *Servo) mirror(now time.Time) (gpio, pwm, bool) {
+	s.lock.Lock()
+	master := s.master
+	if master == nil || s.fault != nil {
+		s.lock.Unlock()
+		return 0, 0, false
+	}
+
+	m := master.snapshot()
+	p := s.limit(s.raw(s.ratio*master.adjust(m.position) + s.offset))
+	s.position, s.target = p, p
+	s.velocity = 0
+	s.deltaT = now
+	s.lastPWM = s.pulse(s.layered(p, now))
+	s.idle = m.idle
+	s.publish()
+	pin, out := s.gpio(), s.lastPWM
+	s.lock.Unlock()
+
+	if m.idle {
+		s.finish()
+	}
+	return pin, out, true
+}
@@ -0,0 +1,119 @@
+// +build !live
+
+package servo
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestServo_Follow(t *testing.T) {
+	useBlaster(t)
+	fb := new(frameBackend)
+	SetBackend(fb)
+	defer SetBackend(nil)
+
+	a, b := New(98), New(99)
+	for _, s := range []*Servo{a, b} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+	}
+	a.SetPosition(30)
+	a.Wait()
+
+	if err := b.Follow(a, -1, 180); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	if p := b.Position(); p != 150 {
+		t.Errorf("follower did not mirror its idle master, got: %.2f, want: 150.00", p)
+	}
+
+	a.MoveTo(120).Wait()
+	b.Wait()
+	if p := b.Position(); p != 60 {
+		t.Errorf("follower did not mirror its master, got: %.2f, want: 60.00", p)
+	}
+
+	// Both servos are written in the same frames, so the follower never lags.
+	fb.lock.Lock()
+	for i, frame := range fb.frames {
+		widths := make(map[int]time.Duration)
+		for _, p := range frame {
+			widths[p.Pin] = p.Width
+		}
+		wa, okA := widths[98]
+		wb, okB := widths[99]
+		if okA != okB {
+			t.Errorf("frame %d: servos written apart, got: %v", i, frame)
+			continue
+		}
+		if d := wa + wb - 3000*time.Microsecond; okA && (d < -2*time.Microsecond || d > 2*time.Microsecond) {
+			t.Errorf("frame %d: follower lags, got: %v", i, frame)
+		}
+	}
+	fb.lock.Unlock()
+
+	if got := b.MoveTo(10).Result(); got != MoveStopped {
+		t.Errorf("follower moved by itself, got: %v, want: %v", got, MoveStopped)
+	}
+
+	b.Unfollow()
+	a.MoveTo(0).Wait()
+	time.Sleep(20 * time.Millisecond)
+	if p := b.Position(); p != 60 {
+		t.Errorf("servo still follows, got: %.2f, want: 60.00", p)
+	}
+}
+
+func TestServo_Follow_cycle(t *testing.T) {
+	a, b, c := New(97), New(98), New(99)
+	if err := b.Follow(a, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Follow(b, 1, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, s := range []*Servo{a, c} {
+		if err := s.Follow(c, 1, 0); !errors.Is(err, ErrCycle) {
+			t.Errorf("%v: wrong error, got: %v, want: %v", s, err, ErrCycle)
+		}
+	}
+
+	followers := []*Servo{c, b}
+	sortFollowers(followers)
+	if followers[0] != b {
+		t.Error("follower sorted before its master")
+	}
+}
+
+func TestServo_Follow_concurrent(t *testing.T) {
+	a, b := New(98), New(99)
+	for i := 0; i < 200; i++ {
+		errs := make(chan error, 2)
+		start := make(chan struct{})
+		for _, pair := range [][2]*Servo{{a, b}, {b, a}} {
+			go func(s, master *Servo) {
+				<-start
+				errs <- s.Follow(master, 1, 0)
+			}(pair[0], pair[1])
+		}
+		close(start)
+
+		cycles := 0
+		for j := 0; j < 2; j++ {
+			if errors.Is(<-errs, ErrCycle) {
+				cycles++
+			}
+		}
+		if cycles != 1 {
+			t.Fatalf("servos follow each other, got %d cycle errors, want: 1", cycles)
+		}
+		a.Unfollow()
+		b.Unfollow()
+	}
+}
@@ -0,0 +1,56 @@
+// +build linux
+
+package gpiocdev
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Requests of the GPIO character device, from linux/gpio.h.
+const (
+	getLineEventIoctl = 0xC030B404
+
+	handleRequestInput = 1 << 0
+	eventRequestBoth   = 0x03
+)
+
+// eventRequest is a struct gpioevent_request.
+type eventRequest struct {
+	lineOffset  uint32
+	handleFlags uint32
+	eventFlags  uint32
+	consumer    [32]byte
+	fd          int32
+}
+
+// Open requests the line of the GPIO chip at path (e.g. "/dev/gpiochip0") as
+// an input that reports both edges.
+func Open(path string, line int) (*Input, error) {
+	chip, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer chip.Close()
+
+	req := eventRequest{
+		lineOffset:  uint32(line),
+		handleFlags: handleRequestInput,
+		eventFlags:  eventRequestBoth,
+	}
+	copy(req.consumer[:], "servo-loopback")
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, chip.Fd(), getLineEventIoctl, uintptr(unsafe.Pointer(&req))); errno != 0 {
+		return nil, fmt.Errorf("gpiocdev: could not request line %d of %s: %v", line, path, errno)
+	}
+
+	// A non-blocking file supports read deadlines.
+	if err := syscall.SetNonblock(int(req.fd), true); err != nil {
+		syscall.Close(int(req.fd))
+		return nil, fmt.Errorf("gpiocdev: %v", err)
+	}
+	f := os.NewFile(uintptr(req.fd), fmt.Sprintf("%s:%d", path, line))
+
+	return &Input{Timeout: Timeout, events: f}, nil
+}
@@ -0,0 +1,15 @@
+// +build linux,!live
+
+package gpiocdev
+
+import (
+	"testing"
+	"unsafe"
+)
+
+func TestEventRequest(t *testing.T) {
+	// The ioctl encodes the size of the struct gpioevent_request.
+	if got, want := unsafe.Sizeof(eventRequest{}), uintptr(getLineEventIoctl>>16&0x3FFF); got != want {
+		t.Errorf("wrong size of eventRequest, got: %d, want: %d", got, want)
+	}
+}
@@ -0,0 +1,10 @@
+// +build !linux
+
+package gpiocdev
+
+import "fmt"
+
+// Open is only supported on Linux.
+func Open(path string, line int) (*Input, error) {
+	return nil, fmt.Errorf("gpiocdev: the GPIO character device is only supported on linux")
+}
@@ -0,0 +1,77 @@
+// Package gpiocdev reads the edges of an input pin with the GPIO character
+// device of Linux (/dev/gpiochipN), which timestamps each edge in the kernel.
+// Wire a spare GPIO to the signal of a servo to check the pulses actually
+// produced by the backend with Servo.Loopback():
+//
+//	in, err := gpiocdev.Open("/dev/gpiochip0", 26)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	defer in.Close()
+//
+//	report, err := s.Loopback(servo.LoopbackConfig{Input: in})
+//	fmt.Println(report)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+package gpiocdev
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+const (
+	// Timeout is the default time to wait for an edge.
+	Timeout = time.Second
+
+	// eventSize is the size of a struct gpioevent_data.
+	eventSize = 16
+	// eventRising is the id of a rising edge.
+	eventRising = 0x01
+)
+
+// Input is an input pin that reports its edges. It implements the
+// servo.EdgeReader interface. Use gpiocdev.Open() for correct initialization.
+type Input struct {
+	// Timeout is how long ReadEdge waits for an edge. (default 1s)
+	Timeout time.Duration
+
+	events events
+	buf    [eventSize]byte
+}
+
+// events are the edge events of a line.
+type events interface {
+	io.ReadCloser
+	SetReadDeadline(t time.Time) error
+}
+
+// ReadEdge implements the servo.EdgeReader interface. The time of the edges
+// is the timestamp of the kernel.
+func (in *Input) ReadEdge() (servo.Edge, error) {
+	if err := in.events.SetReadDeadline(time.Now().Add(in.Timeout)); err != nil {
+		return servo.Edge{}, fmt.Errorf("gpiocdev: %v", err)
+	}
+	if _, err := io.ReadFull(in.events, in.buf[:]); err != nil {
+		return servo.Edge{}, fmt.Errorf("gpiocdev: %v", err)
+	}
+	return decode(in.buf[:]), nil
+}
+
+// Close releases the pin.
+func (in *Input) Close() error {
+	return in.events.Close()
+}
+
+// decode returns the edge of a struct gpioevent_data.
+func decode(b []byte) servo.Edge {
+	return servo.Edge{
+		Time:   time.Duration(binary.LittleEndian.Uint64(b[0:8])),
+		Rising: binary.LittleEndian.Uint32(b[8:12]) == eventRising,
+	}
+}
@@ -0,0 +1,46 @@
+// +build !live
+
+package gpiocdev
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+// fakeEvents replays recorded events.
+type fakeEvents struct {
+	*bytes.Reader
+}
+
+func (fakeEvents) SetReadDeadline(t time.Time) error { return nil }
+func (fakeEvents) Close() error                      { return nil }
+
+func TestInput_ReadEdge(t *testing.T) {
+	data := []byte{
+		// Rising edge at 1000ns.
+		0xE8, 0x03, 0, 0, 0, 0, 0, 0, 0x01, 0, 0, 0, 0, 0, 0, 0,
+		// Falling edge at 1500000ns.
+		0x60, 0xE3, 0x16, 0, 0, 0, 0, 0, 0x02, 0, 0, 0, 0, 0, 0, 0,
+	}
+	in := &Input{Timeout: Timeout, events: fakeEvents{bytes.NewReader(data)}}
+
+	e, err := in.ReadEdge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !e.Rising || e.Time != 1000 {
+		t.Errorf("wrong edge, got: %+v", e)
+	}
+	e, err = in.ReadEdge()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Rising || e.Time != 1500*time.Microsecond {
+		t.Errorf("wrong edge, got: %+v", e)
+	}
+
+	if _, err := in.ReadEdge(); err == nil {
+		t.Error("read an edge without events")
+	}
+}
@@ -0,0 +1,150 @@
+package servo
+
+import (
+	"fmt"
+	"math"
+	"sync"
+)
+
+// Group is a set of servos moved together, e.g. the joints of an arm, so they
+// all arrive at their targets at the same time. Use servo.NewGroup() for
+// correct initialization. Group is designed to be concurrent-safe.
+type Group struct {
+	servos []*Servo
+
+	// speed scales the moves of the group. It is guarded by the lock.
+	speed float64
+	lock  *sync.Mutex
+	// done is the channel of Done(), shared until the group stops moving.
+	done *doneCache
+}
+
+// NewGroup creates a Group of the servos.
+func NewGroup(servos ...*Servo) *Group {
+	return &Group{
+		servos: append([]*Servo(nil), servos...),
+		speed:  1,
+		lock:   new(sync.Mutex),
+		done:   new(doneCache),
+	}
+}
+
+// Servos returns the servos of the group, in order.
+func (g *Group) Servos() []*Servo {
+	return append([]*Servo(nil), g.servos...)
+}
+
+// MoveTo moves each servo of the group to its target, one target per servo in
+// the order of the group, so they all start on the same update and arrive at
+// the same time. The servo with the longest move moves at its own speed, and
+// the moves of the others are slowed down proportionally, keeping their
+// acceleration and jerk limits. All the moves are slowed down further by the
+// speed of the group, see SetSpeed(). It returns ErrOutOfRange without moving
+// if the number of targets does not match the group.
+//
+// The returned Waiter waits for all the servos of the group.
+func (g *Group) MoveTo(targets ...float64) (wait Waiter, err error) {
+	return g.MoveToEach(targets, nil)
+}
+
+// MoveToEach is like MoveTo, with the options of the move of each servo, one
+// slice per servo in the order of the group, e.g. to ease out the jaw while
+// the neck moves linearly:
+//
+//	g := servo.NewGroup(jaw, neck)
+//	g.MoveToEach([]float64{30, 90}, [][]servo.MoveOption{{servo.WithEasing(servo.EaseOut)}, nil})
+//
+// The duration of each move includes its options, and the profiles of the
+// shorter moves are stretched, so the servos still arrive at the same time.
+// opts may be nil for no options. It returns ErrOutOfRange without moving if
+// the number of targets or options does not match the group.
+func (g *Group) MoveToEach(targets []float64, opts [][]MoveOption) (wait Waiter, err error) {
+	if len(targets) != len(g.servos) {
+		return nil, fmt.Errorf("%w: %d targets for %d servos", ErrOutOfRange, len(targets), len(g.servos))
+	}
+	if opts != nil && len(opts) != len(g.servos) {
+		return nil, fmt.Errorf("%w: %d options for %d servos", ErrOutOfRange, len(opts), len(g.servos))
+	}
+	if opts == nil {
+		opts = make([][]MoveOption, len(g.servos))
+	}
+
+	speed := g.Speed()
+	if speed == 0 {
+		g.Stop()
+		return g, nil
+	}
+
+	var longest float64
+	for i, s := range g.servos {
+		longest = math.Max(longest, s.duration(targets[i], opts[i]))
+	}
+	longest /= speed
+
+	return Batch(func(tx *Tx) {
+		for i, s := range g.servos {
+			o := append([]MoveOption(nil), opts[i]...)
+			tx.MoveTo(s, targets[i], append(o, arriveIn(longest))...)
+		}
+	}), nil
+}
+
+// SetSpeed scales the moves of the group from 0.0 (still) to 1.0 (the speeds
+// of the servos, default), without changing the speeds of the servos
+// themselves. It applies to the next moves of the group. Setting a speed of
+// 0.0 stops the servos on the next move of the group.
+func (g *Group) SetSpeed(percentage float64) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.speed = clamp(percentage, 0, 1)
+}
+
+// Speed returns the speed of the group, from 0.0 to 1.0.
+func (g *Group) Speed() float64 {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	return g.speed
+}
+
+// Stop stops every servo of the group instantly.
+func (g *Group) Stop() {
+	for _, s := range g.servos {
+		s.Stop()
+	}
+}
+
+// Wait waits until every servo of the group stops moving.
+func (g *Group) Wait() {
+	for _, s := range g.servos {
+		s.Wait()
+	}
+}
+
+// Done returns a channel that is closed when every servo of the group stopped
+// moving.
+func (g *Group) Done() <-chan struct{} {
+	return g.done.get(g.Wait)
+}
+
+// duration returns the seconds the servo takes to move from its position to
+// target, adjusted for its Flags, with its speed, acceleration and jerk
+// limits and the options of the move, e.g. its easing curve. It returns 0 if
+// the servo cannot move.
+func (s *Servo) duration(target float64, opts []MoveOption) float64 {
+	m := &Move{servo: s}
+	for _, opt := range opts {
+		opt(m)
+	}
+
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	raw := s.limit(s.applyExpo(s.raw(target)))
+	speed := s.speedOf(m)
+	if speed == 0 || s.fault != nil {
+		return 0
+	}
+	return s.shape(m, s.position, raw, speed).duration()
+}
@@ -0,0 +1,155 @@
+// +build !live
+
+package servo
+
+import (
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+func TestGroup_MoveTo(t *testing.T) {
+	useBlaster(t)
+
+	a, b := New(98), New(99)
+	for _, s := range []*Servo{a, b} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+		s.SetMaxSpeed(180)
+		s.SetPosition(0)
+		s.Wait()
+	}
+	b.SetAcceleration(720, 720)
+	g := NewGroup(a, b)
+
+	sub := Subscribe(4, EventFinish)
+	defer sub.Close()
+
+	start := time.Now()
+	w, err := g.MoveTo(180, 45)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(500 * time.Millisecond)
+	// b is slowed down to arrive with a, so it is half way.
+	if p := b.Position(); math.Abs(p-22.5) > 5 {
+		t.Errorf("wrong position half way, got: %.2f, want: about 22.50", p)
+	}
+	w.Wait()
+	if elapsed, want := time.Since(start), time.Second; elapsed < want-50*time.Millisecond || elapsed > want+60*time.Millisecond {
+		t.Errorf("wrong travel time, got: %v, want: %v", elapsed, want)
+	}
+	if a.Position() != 180 || b.Position() != 45 {
+		t.Errorf("wrong positions, got: %.2f, %.2f, want: 180.00, 45.00", a.Position(), b.Position())
+	}
+
+	first, second := <-sub.C, <-sub.C
+	if d := second.Time.Sub(first.Time); d > 10*time.Millisecond {
+		t.Errorf("servos did not arrive together, %v apart", d)
+	}
+}
+
+func TestGroup_MoveToEach(t *testing.T) {
+	useBlaster(t)
+
+	a, b := New(98), New(99)
+	for _, s := range []*Servo{a, b} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+		s.SetMaxSpeed(180)
+		s.SetPosition(0)
+		s.Wait()
+	}
+	g := NewGroup(a, b)
+
+	sub := Subscribe(4, EventFinish)
+	defer sub.Close()
+
+	start := time.Now()
+	w, err := g.MoveToEach([]float64{180, 45}, [][]MoveOption{nil, {WithEasing(EaseIn)}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(500 * time.Millisecond)
+	// b keeps its easing, stretched to arrive with a.
+	if p := b.Position(); math.Abs(p-11.25) > 4 {
+		t.Errorf("wrong eased position half way, got: %.2f, want: about 11.25", p)
+	}
+	w.Wait()
+	if elapsed, want := time.Since(start), time.Second; elapsed < want-50*time.Millisecond || elapsed > want+60*time.Millisecond {
+		t.Errorf("wrong travel time, got: %v, want: %v", elapsed, want)
+	}
+	first, second := <-sub.C, <-sub.C
+	if d := second.Time.Sub(first.Time); d > 10*time.Millisecond {
+		t.Errorf("servos did not arrive together, %v apart", d)
+	}
+
+	if _, err := g.MoveToEach([]float64{0, 0}, [][]MoveOption{nil}); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("wrong error, got: %v, want: %v", err, ErrOutOfRange)
+	}
+}
+
+func TestGroup_MoveTo_targets(t *testing.T) {
+	g := NewGroup(New(98), New(99))
+	if _, err := g.MoveTo(10); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("wrong error, got: %v, want: %v", err, ErrOutOfRange)
+	}
+	if len(g.Servos()) != 2 {
+		t.Errorf("wrong servos, got: %v", g.Servos())
+	}
+}
+
+func TestGroup_SetSpeed(t *testing.T) {
+	useBlaster(t)
+
+	a, b := New(98), New(99)
+	for _, s := range []*Servo{a, b} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+		s.SetMaxSpeed(180)
+		s.SetPosition(0)
+		s.Wait()
+	}
+	g := NewGroup(a, b)
+	g.SetSpeed(0.5)
+	if g.Speed() != 0.5 {
+		t.Errorf("wrong speed, got: %.2f, want: 0.50", g.Speed())
+	}
+
+	start := time.Now()
+	if _, err := g.MoveTo(90, 45); err != nil {
+		t.Fatal(err)
+	}
+	g.Wait()
+	if elapsed, want := time.Since(start), time.Second; elapsed < want-50*time.Millisecond || elapsed > want+60*time.Millisecond {
+		t.Errorf("wrong travel time, got: %v, want: %v", elapsed, want)
+	}
+	a.lock.RLock()
+	speed := a.speed()
+	a.lock.RUnlock()
+	if speed != 180 {
+		t.Errorf("group changed the speed of a servo, got: %.2f, want: 180.00", speed)
+	}
+
+	g.SetSpeed(1)
+	if _, err := g.MoveTo(0, 0); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	g.Stop()
+	select {
+	case <-g.Done():
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("group did not stop")
+	}
+	if a.Position() == 0 || b.Position() == 0 {
+		t.Errorf("servos were not stopped, got: %.2f, %.2f", a.Position(), b.Position())
+	}
+}
@@ -0,0 +1,108 @@
+package servo
+
+import (
+	"sync"
+	"time"
+)
+
+// GuestLimits are the constraints of a Guest. Angles are adjusted for the
+// Flags of the servo.
+type GuestLimits struct {
+	// Min and Max are the range of angles a guest can request.
+	Min, Max float64
+	// Speed is the maximum speed a guest can request, from 0.0 to 1.0.
+	Speed float64
+	// Home is the angle the servo returns to after Timeout without commands.
+	Home float64
+	// Timeout is the inactivity after which the servo returns home. If 0, the
+	// servo never returns home by itself.
+	Timeout time.Duration
+}
+
+// Guest is a constrained control of a servo meant for external clients, such
+// as visitors of a museum or demo setup. Whatever the client requests, the
+// angle is kept within the limits, the speed is capped, and the servo returns
+// home after a period of inactivity. Use servo.NewGuest() for correct
+// initialization.
+type Guest struct {
+	servo  *Servo
+	limits GuestLimits
+	speed  float64
+	timer  *time.Timer
+	// closed is set by Close(), so a timer that already fired does not
+	// return the servo home.
+	closed bool
+	lock   *sync.Mutex
+}
+
+// NewGuest creates a Guest control of the servo with the given limits. The
+// moves of the guest start at the capped speed.
+func NewGuest(s *Servo, limits GuestLimits) *Guest {
+	limits.Speed = clamp(limits.Speed, 0, 1)
+	return &Guest{
+		servo:  s,
+		limits: limits,
+		speed:  limits.Speed,
+		lock:   new(sync.Mutex),
+	}
+}
+
+// MoveTo moves the servo to target, clamped to the limits of the guest, at the
+// speed of the guest, and restarts the inactivity timeout. The speed of the
+// servo itself is not changed, and it also limits the move.
+func (g *Guest) MoveTo(target float64) (wait Waiter) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.rearm()
+
+	return g.servo.MoveTo(clamp(target, g.limits.Min, g.limits.Max), WithSpeed(g.speed))
+}
+
+// SetSpeed changes the speed of the next moves of the guest, capped to the
+// speed limit of the guest.
+func (g *Guest) SetSpeed(percentage float64) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.speed = clamp(percentage, 0, g.limits.Speed)
+	g.rearm()
+}
+
+// rearm restarts the inactivity timeout. It must be called with the guest
+// locked.
+func (g *Guest) rearm() {
+	if g.limits.Timeout <= 0 {
+		return
+	}
+	if g.timer != nil {
+		g.timer.Stop()
+	}
+	g.timer = time.AfterFunc(g.limits.Timeout, g.home)
+}
+
+// home returns the servo home at the capped speed, unless the guest was
+// closed.
+func (g *Guest) home() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if g.closed {
+		return
+	}
+	debugf("guest of %v inactive, returning home", g.servo)
+	g.speed = g.limits.Speed
+	g.servo.MoveTo(g.limits.Home, WithSpeed(g.speed))
+}
+
+// Close stops the inactivity timeout. The servo stays where it is.
+func (g *Guest) Close() {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	g.closed = true
+	if g.timer != nil {
+		g.timer.Stop()
+		g.timer = nil
+	}
+}
@@ -0,0 +1,81 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGuest(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	g := NewGuest(s, GuestLimits{
+		Min:     30,
+		Max:     60,
+		Speed:   1,
+		Home:    45,
+		Timeout: 200 * time.Millisecond,
+	})
+	defer g.Close()
+
+	g.MoveTo(170).Wait()
+	if s.Position() != 60 {
+		t.Errorf("target was not clamped, got: %.2f, want: %.2f", s.Position(), 60.0)
+	}
+
+	time.Sleep(300 * time.Millisecond)
+	s.Wait()
+	if s.Position() != 45 {
+		t.Errorf("servo did not return home, got: %.2f, want: %.2f", s.Position(), 45.0)
+	}
+}
+
+func TestGuest_SetSpeed(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	g := NewGuest(s, GuestLimits{Max: 180, Speed: 0.5})
+	defer g.Close()
+
+	g.SetSpeed(1)
+	g.MoveTo(180)
+	s.lock.RLock()
+	own, move := s.step/s.maxStep, s.speed()/s.maxStep
+	s.lock.RUnlock()
+	s.Stop()
+	if own != 1 {
+		t.Errorf("guest changed the speed of the servo, got: %.2f, want: %.2f", own, 1.0)
+	}
+	if move != 0.5 {
+		t.Errorf("speed was not capped, got: %.2f, want: %.2f", move, 0.5)
+	}
+}
+
+func TestGuest_Close(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	g := NewGuest(s, GuestLimits{Max: 180, Speed: 1, Home: 90, Timeout: time.Hour})
+
+	// A timer that already fired runs after Close.
+	g.Close()
+	g.home()
+	if !s.isIdle() || s.Position() != 0 {
+		t.Errorf("closed guest returned home, got: %.2f", s.Position())
+	}
+}
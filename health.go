@@ -0,0 +1,92 @@
+package servo
+
+import (
+	"log"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// piBlasterPID returns the process ID of the oldest pi-blaster, and whether
+// pi-blaster is running. It depends on /bin/sh and pgrep.
+var piBlasterPID = func() (int, bool) {
+	out, err := exec.Command("/bin/sh", "-c", "pgrep -o pi-blaster").Output()
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(out)))
+	if err != nil {
+		return 0, false
+	}
+	return pid, true
+}
+
+// MonitorPiBlaster checks every interval that the pi-blaster daemon is
+// running, until stop is called. pi-blaster forgets all the duty cycles when
+// it restarts, so when the daemon comes back or is replaced by a new process,
+// the current pwm of every connected servo is written again. Released servos
+// stay released. stop waits until the monitor has stopped.
+func MonitorPiBlaster(interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		last, running := piBlasterPID()
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+			}
+
+			pid, ok := piBlasterPID()
+			switch {
+			case !ok && running:
+				log.Println("WARNING: pi-blaster is not running")
+			case ok && !running:
+				log.Printf("pi-blaster is running again (pid %d), resynchronizing the servos", pid)
+				_blaster.resync()
+			case ok && pid != last:
+				log.Printf("pi-blaster was restarted (pid %d to %d), resynchronizing the servos", last, pid)
+				_blaster.resync()
+			}
+			last, running = pid, ok
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
+// resync makes the manager write the current pwm of every connected servo
+// that is not released, reopening the pipe of pi-blaster.
+func (b *blaster) resync() {
+	b.pi.lock.Lock()
+	b.pi.closePipe()
+	b.pi.lock.Unlock()
+
+	b.rewrite()
+}
+
+// rewrite makes the manager write the current pwm of every connected servo
+// that is not released, e.g. after the backend lost the pulses.
+func (b *blaster) rewrite() {
+	for _, s := range b.connected() {
+		if atomic.LoadInt32(&s.release) != 0 {
+			continue
+		}
+		s.lock.Lock()
+		s.idle = false
+		s.publish()
+		s.lock.Unlock()
+	}
+	b.wakeUp()
+}
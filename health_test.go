@@ -0,0 +1,147 @@
+// +build !live
+
+package servo
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestMonitorPiBlaster(t *testing.T) {
+	useBlaster(t)
+	rb := &recordBackend{pulses: make(map[int]time.Duration)}
+	SetBackend(rb)
+	defer SetBackend(nil)
+	Rate(time.Millisecond)
+
+	var lock sync.Mutex
+	pid, running := 100, true
+	old := piBlasterPID
+	piBlasterPID = func() (int, bool) {
+		lock.Lock()
+		defer lock.Unlock()
+		return pid, running
+	}
+	defer func() { piBlasterPID = old }()
+
+	s, released := New(98), New(99)
+	for _, s := range []*Servo{s, released} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+		s.MoveTo(90).Wait()
+	}
+	released.detach(false)
+
+	stop := MonitorPiBlaster(5 * time.Millisecond)
+	defer stop()
+	time.Sleep(20 * time.Millisecond)
+
+	written := func() (time.Duration, time.Duration) {
+		rb.lock.Lock()
+		defer rb.lock.Unlock()
+		return rb.pulses[98], rb.pulses[99]
+	}
+	clear := func() {
+		rb.lock.Lock()
+		defer rb.lock.Unlock()
+		rb.pulses = make(map[int]time.Duration)
+	}
+
+	clear()
+	time.Sleep(20 * time.Millisecond)
+	if got, _ := written(); got != 0 {
+		t.Fatalf("servo was written without restart: %v", got)
+	}
+
+	// pi-blaster restarts with a new pid.
+	lock.Lock()
+	pid = 200
+	lock.Unlock()
+	time.Sleep(50 * time.Millisecond)
+
+	got, gotReleased := written()
+	if want := 1500 * time.Microsecond; got != want {
+		t.Errorf("servo was not resynchronized, got: %v, want: %v", got, want)
+	}
+	if gotReleased != 0 {
+		t.Errorf("released servo was driven: %v", gotReleased)
+	}
+
+	// pi-blaster goes away and comes back.
+	lock.Lock()
+	running = false
+	lock.Unlock()
+	time.Sleep(20 * time.Millisecond)
+	clear()
+	lock.Lock()
+	running = true
+	lock.Unlock()
+	time.Sleep(50 * time.Millisecond)
+
+	if got, _ := written(); got != 1500*time.Microsecond {
+		t.Errorf("servo was not resynchronized after pi-blaster came back, got: %v", got)
+	}
+}
+
+func TestMonitorPiBlaster_drivenAgain(t *testing.T) {
+	useBlaster(t)
+	rb := &recordBackend{pulses: make(map[int]time.Duration)}
+	SetBackend(rb)
+	defer SetBackend(nil)
+	Rate(time.Millisecond)
+
+	var lock sync.Mutex
+	pid := 100
+	old := piBlasterPID
+	piBlasterPID = func() (int, bool) {
+		lock.Lock()
+		defer lock.Unlock()
+		return pid, true
+	}
+	defer func() { piBlasterPID = old }()
+
+	s := New(98)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetPosition(0)
+
+	w := s.Watchdog(WatchdogConfig{
+		Timeout: 20 * time.Millisecond,
+		Action:  WatchdogRelease,
+	})
+	time.Sleep(60 * time.Millisecond)
+	w.Stop()
+	rb.lock.Lock()
+	released := rb.pulses[98]
+	rb.lock.Unlock()
+	if released != 0 {
+		t.Fatalf("pin was not released, got: %v", released)
+	}
+
+	// A new move drives the released servo again.
+	s.MoveTo(90).Wait()
+
+	stop := MonitorPiBlaster(5 * time.Millisecond)
+	defer stop()
+	time.Sleep(20 * time.Millisecond)
+	rb.lock.Lock()
+	rb.pulses = make(map[int]time.Duration)
+	rb.lock.Unlock()
+
+	// pi-blaster restarts with a new pid.
+	lock.Lock()
+	pid = 200
+	lock.Unlock()
+	time.Sleep(50 * time.Millisecond)
+
+	rb.lock.Lock()
+	defer rb.lock.Unlock()
+	if got, want := rb.pulses[98], 1500*time.Microsecond; got != want {
+		t.Errorf("servo driven again was not resynchronized, got: %v, want: %v", got, want)
+	}
+}
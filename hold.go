@@ -0,0 +1,183 @@
+package servo
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// defaultHoldInterval is the default interval between feedback reads of a
+// Hold.
+const defaultHoldInterval = 50 * time.Millisecond
+
+// Feedback reads the actual angle of a servo, e.g. the potentiometer of an
+// analog feedback servo read through an ADC.
+type Feedback interface {
+	// Angle returns the measured angle of the servo, adjusted for its
+	// Flags.
+	Angle() (float64, error)
+}
+
+// Sync sets the position of an idle servo to the angle read from fb, so
+// Position() reports the measured angle instead of the estimate of the
+// manager. The servo is not driven to the angle. Sync does nothing if the
+// servo is moving.
+func (s *Servo) Sync(fb Feedback) error {
+	angle, err := fb.Angle()
+	if err != nil {
+		return s.wrap(err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.idle {
+		return nil
+	}
+	s.position = clamp(s.raw(angle), 0, s.fullRange())
+	s.target = s.position
+	s.publish()
+
+	return nil
+}
+
+// HoldConfig is the configuration of a Hold.
+type HoldConfig struct {
+	// Feedback reads the actual angle of the servo.
+	Feedback Feedback
+	// Threshold is the position error, in degrees, that re-engages the full
+	// hold of a relaxed servo.
+	Threshold float64
+	// Settle is how long the servo must stay within Threshold of its
+	// position before it is relaxed.
+	Settle time.Duration
+	// Interval is the time between feedback reads. (default 50ms)
+	Interval time.Duration
+}
+
+// Hold relaxes an idle servo to cut its power draw and heat while the load is
+// light enough for the gears to hold the position, and re-engages the full
+// hold as soon as the feedback drifts away from the position. A relaxed servo
+// is released (0 duty) and is driven again on its next move or when
+// re-engaged. Use Servo.Hold() for correct initialization.
+type Hold struct {
+	servo   *Servo
+	config  HoldConfig
+	relaxed int32
+	stop    chan struct{}
+	once    *sync.Once
+	done    chan struct{}
+}
+
+// Hold starts the hold-torque modulation of the servo. Call Stop() to
+// re-engage the full hold and stop reading the feedback.
+func (s *Servo) Hold(config HoldConfig) *Hold {
+	if config.Interval <= 0 {
+		config.Interval = defaultHoldInterval
+	}
+	h := &Hold{
+		servo:  s,
+		config: config,
+		stop:   make(chan struct{}),
+		once:   new(sync.Once),
+		done:   make(chan struct{}),
+	}
+	go h.run()
+
+	return h
+}
+
+// Relaxed returns true if the servo is currently relaxed.
+func (h *Hold) Relaxed() bool {
+	return atomic.LoadInt32(&h.relaxed) == 1
+}
+
+// Stop re-engages the full hold of the servo and stops the modulation.
+func (h *Hold) Stop() {
+	h.once.Do(func() { close(h.stop) })
+	<-h.done
+}
+
+// run reads the feedback at every interval until the hold is stopped.
+func (h *Hold) run() {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.config.Interval)
+	defer ticker.Stop()
+
+	var settled time.Time
+	for {
+		select {
+		case <-h.stop:
+			if h.Relaxed() {
+				h.engage()
+			}
+			return
+		case now := <-ticker.C:
+			if h.check(now, &settled) {
+				settled = time.Time{}
+			}
+		}
+	}
+}
+
+// check compares the feedback with the position of the servo, relaxing or
+// engaging it. It returns true if the settle time must restart.
+func (h *Hold) check(now time.Time, settled *time.Time) bool {
+	s := h.servo
+	if !s.isIdle() || s.hasLayers() || s.Fault() != nil {
+		// A move drives the servo again by itself.
+		atomic.StoreInt32(&h.relaxed, 0)
+		return true
+	}
+
+	angle, err := h.config.Feedback.Angle()
+	if err != nil {
+		debugf("%v hold feedback: %v", s, err)
+		if h.Relaxed() {
+			h.engage()
+		}
+		return true
+	}
+
+	s.lock.RLock()
+	drift := math.Abs(s.raw(angle) - s.position)
+	s.lock.RUnlock()
+
+	if drift > h.config.Threshold {
+		if h.Relaxed() {
+			h.engage()
+		}
+		return true
+	}
+	if h.Relaxed() {
+		return false
+	}
+
+	if settled.IsZero() {
+		*settled = now
+	}
+	if now.Sub(*settled) >= h.config.Settle {
+		debugf("%v hold relaxed", s)
+		atomic.StoreInt32(&h.relaxed, 1)
+		atomic.StoreInt32(&s.release, 1)
+		_blaster.wakeUp()
+	}
+	return false
+}
+
+// engage drives the servo at its position again.
+func (h *Hold) engage() {
+	s := h.servo
+	debugf("%v hold engaged", s)
+	atomic.StoreInt32(&h.relaxed, 0)
+	atomic.StoreInt32(&s.release, 0)
+
+	s.lock.Lock()
+	// Force the manager to write the current position.
+	s.idle = false
+	s.publish()
+	s.lock.Unlock()
+	_blaster.wakeUp()
+}
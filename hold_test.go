@@ -0,0 +1,81 @@
+// +build !live
+
+package servo
+
+import (
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// fakeFeedback reports the angle stored in bits.
+type fakeFeedback struct {
+	bits uint64
+}
+
+func (f *fakeFeedback) set(angle float64) {
+	atomic.StoreUint64(&f.bits, math.Float64bits(angle))
+}
+
+func (f *fakeFeedback) Angle() (float64, error) {
+	return math.Float64frombits(atomic.LoadUint64(&f.bits)), nil
+}
+
+func TestServo_Hold(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetPosition(90)
+
+	fb := new(fakeFeedback)
+	fb.set(90.5)
+	h := s.Hold(HoldConfig{
+		Feedback:  fb,
+		Threshold: 2,
+		Settle:    30 * time.Millisecond,
+		Interval:  5 * time.Millisecond,
+	})
+	defer h.Stop()
+
+	time.Sleep(100 * time.Millisecond)
+	if !h.Relaxed() {
+		t.Fatal("settled servo was not relaxed")
+	}
+	if got, _ := s.LastPWM(); got != 0 {
+		t.Errorf("relaxed servo was not released, got: %.4f, want: %.4f", got, 0.0)
+	}
+
+	fb.set(85)
+	time.Sleep(30 * time.Millisecond)
+	if got, _ := s.LastPWM(); got == 0 {
+		t.Error("full hold was not re-engaged")
+	}
+	if r := atomic.LoadInt32(&s.release); r != 0 {
+		t.Errorf("engaged servo is still released, got: %d, want: 0", r)
+	}
+}
+
+func TestServo_Sync(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	fb := new(fakeFeedback)
+	fb.set(42)
+	if err := s.Sync(fb); err != nil {
+		t.Fatal(err)
+	}
+	if got := s.Position(); got != 42 {
+		t.Errorf("position was not synced, got: %.2f, want: %.2f", got, 42.0)
+	}
+}
@@ -0,0 +1,126 @@
+package servo
+
+import "sync"
+
+// hooks holds the functions called at different points of the lifecycle of
+// the servo package.
+type hooks struct {
+	onStart, beforeShutdown, afterShutdown []func()
+	onWriteError                           []func(error)
+
+	running bool
+	lock    *sync.Mutex
+}
+
+func newHooks() *hooks {
+	return &hooks{
+		lock: new(sync.Mutex),
+	}
+}
+
+// call runs the hooks in order of registration.
+func call(fns []func()) {
+	for _, fn := range fns {
+		fn()
+	}
+}
+
+// started calls the start hooks. Start hooks registered afterwards are called
+// immediately.
+func (h *hooks) started() {
+	h.lock.Lock()
+	h.running = true
+	fns := h.onStart
+	h.lock.Unlock()
+
+	call(fns)
+}
+
+// shutdown calls the hooks registered with BeforeShutdown.
+func (h *hooks) shutdown() {
+	h.lock.Lock()
+	fns := h.beforeShutdown
+	h.lock.Unlock()
+
+	call(fns)
+}
+
+// closed calls the hooks registered with AfterShutdown.
+func (h *hooks) closed() {
+	h.lock.Lock()
+	fns := h.afterShutdown
+	h.lock.Unlock()
+
+	call(fns)
+}
+
+// writeFailed calls the hooks registered with OnWriteError. It returns false
+// if there are none.
+func (h *hooks) writeFailed(err error) bool {
+	h.lock.Lock()
+	fns := h.onWriteError
+	h.lock.Unlock()
+
+	for _, fn := range fns {
+		fn(err)
+	}
+	return len(fns) > 0
+}
+
+// OnStart registers fn to be called when the manager starts, which happens the
+// first time a servo is connected or servo.Rate() is called. If the manager is
+// already running, fn is called immediately.
+func OnStart(fn func()) {
+	h := _blaster.hooks
+
+	h.lock.Lock()
+	h.onStart = append(h.onStart, fn)
+	running := h.running
+	h.lock.Unlock()
+
+	if running {
+		fn()
+	}
+}
+
+// BeforeShutdown registers fn to be called by servo.Close() before the final
+// flush to pi-blaster. The servos are still connected and moving, so fn can
+// be used to park mechanisms, e.g. myServo.MoveTo(0).Wait().
+//
+// fn must not call servo.Close().
+func BeforeShutdown(fn func()) {
+	h := _blaster.hooks
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.beforeShutdown = append(h.beforeShutdown, fn)
+}
+
+// AfterShutdown registers fn to be called by servo.Close() after all PWM
+// outputs were turned off and the manager has stopped. Use it to flush
+// telemetry or notify supervisors.
+func AfterShutdown(fn func()) {
+	h := _blaster.hooks
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.afterShutdown = append(h.afterShutdown, fn)
+}
+
+// OnWriteError registers fn to be called with every error of a write to the
+// backend, e.g. when pi-blaster was restarted and its pipe cannot be opened.
+// The manager keeps running and retries on the next flush. Without any
+// handler, the errors are logged.
+//
+// fn is called by the manager, so it must return quickly and must not call
+// Servo.Connect(), Servo.Close() or servo.Close().
+func OnWriteError(fn func(err error)) {
+	h := _blaster.hooks
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.onWriteError = append(h.onWriteError, fn)
+}
@@ -0,0 +1,91 @@
+// +build !live
+
+package servo
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestHooks(t *testing.T) {
+	useBlaster(t)
+
+	var got []string
+	OnStart(func() { got = append(got, "start") })
+	BeforeShutdown(func() { got = append(got, "shutdown") })
+	AfterShutdown(func() { got = append(got, "closed") })
+
+	if len(got) != 0 {
+		t.Fatalf("hooks were called before the manager started, got: %v", got)
+	}
+
+	s := New(99)
+	var parked bool
+	BeforeShutdown(func() {
+		s.MoveTo(1).Wait()
+		parked = s.Position() == 1
+	})
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	OnStart(func() { got = append(got, "late start") })
+
+	Close()
+
+	want := []string{"start", "late start", "shutdown", "closed"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("hooks were called out of order\ngot:\n%v\nwant:\n%v", got, want)
+	}
+	if !parked {
+		t.Error("servo could not move during BeforeShutdown")
+	}
+}
+
+// brokenBackend fails every write and close.
+type brokenBackend struct{}
+
+func (brokenBackend) Write(pulses []Pulse) error { return os.ErrNotExist }
+func (brokenBackend) Close() error               { return os.ErrNotExist }
+
+func TestOnWriteError(t *testing.T) {
+	useBlaster(t)
+	SetBackend(brokenBackend{})
+	defer SetBackend(nil)
+	Rate(time.Millisecond)
+
+	errs := make(chan error, 100)
+	OnWriteError(func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	})
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	s.MoveTo(10)
+
+	select {
+	case err := <-errs:
+		if !errors.Is(err, os.ErrNotExist) {
+			t.Errorf("wrong error, got: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("write error was not reported")
+	}
+
+	if err := s.Close(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Servo.Close() did not return the write error, got: %v", err)
+	}
+	if err := Close(); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Close() did not return the release error, got: %v", err)
+	}
+	if err := Close(); err != nil {
+		t.Errorf("second Close() returned an error: %v", err)
+	}
+}
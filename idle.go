@@ -0,0 +1,31 @@
+package servo
+
+import "time"
+
+// idleTick is the interval of the tickers of the manager while all the servos
+// are idle. It bounds the delay of a change that did not wake the manager.
+const idleTick = 250 * time.Millisecond
+
+// wakeUp makes the manager resume its tickers if it is sleeping because all
+// the servos were idle. It never blocks.
+func (b *blaster) wakeUp() {
+	select {
+	case b.wake <- struct{}{}:
+	default:
+	}
+}
+
+// asleep checks if the manager can slow down its tickers: no servo is moving,
+// has motion layers or waits for the release of its pin, and there is no data
+// left to flush. It must be called by the manager.
+func (b *blaster) asleep(data map[gpio]pwm) bool {
+	if len(data) != 0 {
+		return false
+	}
+	for _, s := range b._servos {
+		if !s.isIdle() || s.hasLayers() || s.releasePending() {
+			return false
+		}
+	}
+	return true
+}
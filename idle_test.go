@@ -0,0 +1,70 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBlaster_asleep(t *testing.T) {
+	b := newBlaster()
+	s := New(99)
+	b._servos[99] = s
+
+	if !b.asleep(nil) {
+		t.Error("idle servo kept the manager awake")
+	}
+	if b.asleep(map[gpio]pwm{99: 0.15}) {
+		t.Error("slept with data to flush")
+	}
+
+	s.idle = false
+	s.publish()
+	if b.asleep(nil) {
+		t.Error("slept while a servo was moving")
+	}
+	s.idle = true
+	s.publish()
+
+	l := s.AddLayer(constMotion(10))
+	if b.asleep(nil) {
+		t.Error("slept while a servo had layers")
+	}
+	l.Remove()
+	s.idle = true
+	s.publish()
+
+	s.release = 1
+	if b.asleep(nil) {
+		t.Error("slept while a release was pending")
+	}
+}
+
+func TestIdle_wakeUp(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.MoveTo(10).Wait()
+	// Let the manager fall asleep.
+	time.Sleep(100 * time.Millisecond)
+
+	sub := Subscribe(10, EventPosition, s)
+	defer sub.Close()
+
+	start := time.Now()
+	s.MoveTo(20)
+	select {
+	case <-sub.C:
+		if d := time.Since(start); d > idleTick/2 {
+			t.Errorf("manager resumed after %v", d)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("manager did not resume")
+	}
+	s.Wait()
+}
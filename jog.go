@@ -0,0 +1,150 @@
+package servo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// key is a key press decoded by readKey.
+type key int
+
+const (
+	keyOther key = iota
+	keyLeft
+	keyRight
+	keyUp
+	keyDown
+	keyEnter
+	keyQuit
+)
+
+// readKey decodes the next key press from r. Arrow keys are expected as ANSI
+// escape sequences, as sent by a terminal in raw mode.
+func readKey(r *bufio.Reader) (key, error) {
+	b, err := r.ReadByte()
+	if err != nil {
+		return keyOther, err
+	}
+
+	switch b {
+	case '\r', '\n':
+		return keyEnter, nil
+	case 'q', 0x03: // Ctrl-C
+		return keyQuit, nil
+	case 'h':
+		return keyLeft, nil
+	case 'l':
+		return keyRight, nil
+	case 'k':
+		return keyUp, nil
+	case 'j':
+		return keyDown, nil
+	case 0x1b: // ESC
+	default:
+		return keyOther, nil
+	}
+
+	if b, err = r.ReadByte(); err != nil || b != '[' {
+		return keyOther, err
+	}
+	if b, err = r.ReadByte(); err != nil {
+		return keyOther, err
+	}
+	switch b {
+	case 'A':
+		return keyUp, nil
+	case 'B':
+		return keyDown, nil
+	case 'C':
+		return keyRight, nil
+	case 'D':
+		return keyLeft, nil
+	}
+
+	return keyOther, nil
+}
+
+var (
+	// errJogAborted is thrown when the user quits Jog before accepting both
+	// end points.
+	errJogAborted = fmt.Errorf("calibration aborted")
+)
+
+// Jog interactively calibrates the pulse end points of a connected servo. It
+// reads key presses from in (usually a terminal in raw mode) and writes a
+// live readout of the angle and pulse to out.
+//
+// First, the servo is set to 0 degrees and the left/right arrow keys (or h/l)
+// decrease/increase MinPulse until the horn points to the physical 0 degrees.
+// Press Enter to accept it. Then, the servo is set to its Range and MaxPulse
+// is adjusted the same way. The up/down arrow keys (or k/j) change the size of
+// the jog step.
+//
+// Jog returns the accepted calibration, which is also applied to the servo.
+// If the user quits with q or Ctrl-C, the original calibration is restored
+// and an error is returned.
+//
+// The pulses are kept within the safe range, unless AllowUnsafePulse is set.
+//
+// CAUTION: Jogging beyond the mechanical limits of the servo might damage it.
+// Use small steps near the end points.
+func Jog(s *Servo, in io.Reader, out io.Writer) (Calibration, error) {
+	original := s.Calibration()
+	c := original
+	r := bufio.NewReader(in)
+	s.lock.RLock()
+	end := s.fullRange()
+	s.lock.RUnlock()
+
+	phases := []struct {
+		name  string
+		angle float64
+		pulse *float64
+	}{
+		{"min", 0, &c.MinPulse},
+		{"max", end, &c.MaxPulse},
+	}
+
+	step := 0.001
+
+	for _, phase := range phases {
+		s.setPosition(phase.angle)
+
+	jog:
+		for {
+			if err := s.Calibrate(c); err != nil {
+				s.Calibrate(original)
+				return original, err
+			}
+			fmt.Fprintf(out, "\r%s: angle=%6.2f pulse=%.4f step=%.4f   ",
+				phase.name, phase.angle, *phase.pulse, step)
+
+			k, err := readKey(r)
+			if err != nil {
+				s.Calibrate(original)
+				return original, err
+			}
+
+			switch k {
+			case keyLeft:
+				*phase.pulse = s.clampPulse(*phase.pulse - step)
+			case keyRight:
+				*phase.pulse = s.clampPulse(*phase.pulse + step)
+			case keyUp:
+				step = clamp(step*10, 0.0001, 0.1)
+			case keyDown:
+				step = clamp(step/10, 0.0001, 0.1)
+			case keyEnter:
+				fmt.Fprintf(out, "\r\n")
+				break jog
+			case keyQuit:
+				fmt.Fprintf(out, "\r\n")
+				s.Calibrate(original)
+				return original, errJogAborted
+			}
+		}
+	}
+
+	return c, nil
+}
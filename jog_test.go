@@ -0,0 +1,44 @@
+// +build !live
+
+package servo
+
+import (
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestJog(t *testing.T) {
+	s := New(99)
+
+	// right x2, accept min, step up, left, accept max.
+	in := strings.NewReader("\x1b[C\x1b[C\r\x1b[A\x1b[D\r")
+	got, err := Jog(s, in, ioutil.Discard)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := Calibration{MinPulse: 0.052, MaxPulse: 0.24}
+	if !approx(got.MinPulse, want.MinPulse) || !approx(got.MaxPulse, want.MaxPulse) {
+		t.Errorf("Jog() -> got: %+v, want: %+v", got, want)
+	}
+	if s.Calibration() != got {
+		t.Errorf("calibration was not applied, got: %+v, want: %+v", s.Calibration(), got)
+	}
+
+	t.Run("Quit", func(t *testing.T) {
+		original := s.Calibration()
+		_, err := Jog(s, strings.NewReader("\x1b[D\x1b[Dq"), ioutil.Discard)
+		if err != errJogAborted {
+			t.Errorf("Jog() error -> got: %v, want: %v", err, errJogAborted)
+		}
+		if s.Calibration() != original {
+			t.Errorf("calibration was not restored, got: %+v, want: %+v", s.Calibration(), original)
+		}
+	})
+}
+
+func approx(a, b float64) bool {
+	const epsilon = 1e-9
+	return a-b < epsilon && b-a < epsilon
+}
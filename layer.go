@@ -0,0 +1,146 @@
+package servo
+
+import "time"
+
+// Motion is a source of motion that can be layered on top of the position of
+// a servo with Servo.AddLayer().
+type Motion interface {
+	// Offset returns the offset in degrees from the position of the servo at
+	// the time t since the layer was added.
+	Offset(t time.Duration) float64
+}
+
+// Layer is a Motion layered on top of the position of a servo. Layers are
+// generated by the manager, so the resulting angle is kept within the range
+// and the travel limits of the servo and each layer is limited by the speed of
+// the servo.
+type Layer struct {
+	servo  *Servo
+	motion Motion
+	start  time.Time
+
+	// weight and disabled are guarded by the lock of the servo.
+	weight   float64
+	disabled bool
+
+	// offset is the last offset of the layer at time t. It is only accessed
+	// by the manager.
+	offset float64
+	t      time.Time
+}
+
+// AddLayer adds a motion layer to the servo. The positions reported by the
+// servo and its events do not include the layers, only the pwm sent to
+// pi-blaster does.
+func (s *Servo) AddLayer(m Motion) *Layer {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	return s.addLayer(m)
+}
+
+// addLayer adds a motion layer to the servo. It must be called with the servo
+// locked.
+func (s *Servo) addLayer(m Motion) *Layer {
+	now := time.Now()
+	l := &Layer{
+		servo:  s,
+		motion: m,
+		start:  now,
+		weight: 1,
+		t:      now,
+	}
+
+	s.layers = append(s.layers, l)
+	_blaster.wakeUp()
+
+	return l
+}
+
+// Remove removes the layer from its servo. It is safe to call Remove more than
+// once.
+func (l *Layer) Remove() {
+	s := l.servo
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.removeLayer(l)
+}
+
+// removeLayer removes the layer l from the servo, if present. It must be
+// called with the servo locked.
+func (s *Servo) removeLayer(l *Layer) {
+	for i, layer := range s.layers {
+		if layer == l {
+			s.layers = append(s.layers[:i], s.layers[i+1:]...)
+			// Force the manager to write the pwm without the layer.
+			s.idle = false
+			s.publish()
+			_blaster.wakeUp()
+			return
+		}
+	}
+}
+
+// SetWeight scales the offset of the layer from 0 (no effect) to 1 (full
+// effect, default). The change is applied smoothly, at most at the speed of
+// the servo, so a show can dial a layer down and back up while it plays.
+func (l *Layer) SetWeight(w float64) {
+	l.servo.lock.Lock()
+	defer l.servo.lock.Unlock()
+
+	l.weight = clamp(w, 0, 1)
+}
+
+// Weight returns the weight of the layer.
+func (l *Layer) Weight() float64 {
+	l.servo.lock.RLock()
+	defer l.servo.lock.RUnlock()
+
+	return l.weight
+}
+
+// Enable turns the layer on or off without removing it from the servo. A
+// disabled layer eases back to no offset, at most at the speed of the servo.
+func (l *Layer) Enable(on bool) {
+	l.servo.lock.Lock()
+	defer l.servo.lock.Unlock()
+
+	l.disabled = !on
+}
+
+// Enabled checks if the layer is turned on.
+func (l *Layer) Enabled() bool {
+	l.servo.lock.RLock()
+	defer l.servo.lock.RUnlock()
+
+	return !l.disabled
+}
+
+// layered adds the offsets of the layers to the raw position p at time now,
+// limiting the change of each layer to the speed of the servo. It must be
+// called by the manager with the servo locked.
+func (s *Servo) layered(p float64, now time.Time) float64 {
+	for _, l := range s.layers {
+		maxDelta := now.Sub(l.t).Seconds() * s.speed()
+		var target float64
+		if !l.disabled {
+			target = l.weight * l.motion.Offset(now.Sub(l.start))
+		}
+		l.offset = clamp(target, l.offset-maxDelta, l.offset+maxDelta)
+		l.t = now
+		p += l.offset
+	}
+
+	return s.limit(p)
+}
+
+// hasLayers checks if the servo has any motion layer to generate. The layers
+// of a faulted servo are not generated.
+func (s *Servo) hasLayers() bool {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return len(s.layers) > 0 && s.fault == nil
+}
@@ -0,0 +1,108 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+// constMotion is a Motion with a constant offset.
+type constMotion float64
+
+func (m constMotion) Offset(time.Duration) float64 {
+	return float64(m)
+}
+
+func TestServo_AddLayer(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetPosition(90)
+	s.Wait()
+
+	center := pwm(remap(90, 0, 180, s.MinPulse, s.MaxPulse))
+	shifted := pwm(remap(100, 0, 180, s.MinPulse, s.MaxPulse))
+
+	l := s.AddLayer(constMotion(10))
+	time.Sleep(200 * time.Millisecond)
+
+	if got, _ := s.LastPWM(); pwm(got) != shifted {
+		t.Errorf("layer was not applied, got: %.4f, want: %.4f", got, shifted)
+	}
+	if s.Position() != 90 {
+		t.Errorf("layer changed the position, got: %.2f, want: %.2f", s.Position(), 90.0)
+	}
+
+	l.Remove()
+	l.Remove()
+	time.Sleep(100 * time.Millisecond)
+
+	if got, _ := s.LastPWM(); pwm(got) != center {
+		t.Errorf("layer was not removed, got: %.4f, want: %.4f", got, center)
+	}
+}
+
+func TestServo_LayerSpeed(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	s.SetSpeed(0.1)
+	l := s.AddLayer(constMotion(90))
+
+	// The layer can only move as fast as the servo.
+	start := l.t
+	s.lock.RLock()
+	p := s.layered(90, start.Add(100*time.Millisecond))
+	s.lock.RUnlock()
+
+	if want := 90 + s.step*0.1; !approx(p, want) {
+		t.Errorf("layer exceeded the speed of the servo, got: %.2f, want: %.2f", p, want)
+	}
+}
+
+func TestLayer_Weight(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	l := s.AddLayer(constMotion(10))
+	now := l.t.Add(time.Second)
+
+	layered := func() float64 {
+		now = now.Add(time.Second)
+		s.lock.RLock()
+		defer s.lock.RUnlock()
+		return s.layered(90, now)
+	}
+
+	if p := layered(); !approx(p, 100) {
+		t.Errorf("wrong full weight position, got: %.2f, want: %.2f", p, 100.0)
+	}
+
+	l.SetWeight(0.5)
+	if l.Weight() != 0.5 {
+		t.Errorf("weight was not set, got: %.2f, want: %.2f", l.Weight(), 0.5)
+	}
+	if p := layered(); !approx(p, 95) {
+		t.Errorf("wrong half weight position, got: %.2f, want: %.2f", p, 95.0)
+	}
+
+	l.Enable(false)
+	if l.Enabled() {
+		t.Error("layer was not disabled")
+	}
+	if p := layered(); !approx(p, 90) {
+		t.Errorf("wrong disabled position, got: %.2f, want: %.2f", p, 90.0)
+	}
+
+	l.Enable(true)
+	l.SetWeight(2)
+	if p := layered(); !approx(p, 100) {
+		t.Errorf("weight was not clamped, got: %.2f, want: %.2f", p, 100.0)
+	}
+}
@@ -0,0 +1,329 @@
+// Package lewansoul drives LewanSoul (Hiwonder) LX-16A bus servos through
+// their half-duplex UART, e.g. with the BusLinker debug board.
+//
+// Set a Controller as the backend of the servo package, and use the ID of
+// each LX-16A as the pin of its servo:
+//
+//	lx, err := lewansoul.Open("/dev/ttyUSB0")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	servo.SetBackend(lx)
+//	defer servo.Close()
+//
+//	s := servo.New(1) // LX-16A with ID 1
+//
+// The position of the servos can be read back, e.g. to correct the position
+// estimated by the servo package:
+//
+//	s.Sync(lx.Feedback(s))
+package lewansoul
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+const (
+	// Baud is the baud rate of the LX-16A.
+	Baud = 115200
+
+	// MinPulse and MaxPulse are the default pulses of the servo package at
+	// 0 and 180 degrees.
+	MinPulse = 500 * time.Microsecond
+	MaxPulse = 2500 * time.Microsecond
+	// MinPosition and MaxPosition are the default positions at MinPulse and
+	// MaxPulse: 180 degrees centered in the 240 degrees of an LX-16A.
+	MinPosition = 125
+	MaxPosition = 875
+
+	// readTimeout is how long to wait for the answer of a servo.
+	readTimeout = 100 * time.Millisecond
+)
+
+// Commands of the LX-16A.
+const (
+	cmdMoveTimeWrite = 1
+	cmdPosRead       = 28
+	cmdLoadWrite     = 31
+
+	maxID = 253
+)
+
+// header starts every packet.
+var header = []byte{0x55, 0x55}
+
+// Controller is a bus of LX-16A servos. It implements the servo.Backend
+// interface. Use lewansoul.Open() or lewansoul.New() for correct
+// initialization.
+type Controller struct {
+	// MinPulse and MaxPulse are mapped to MinPosition and MaxPosition. The
+	// pulses in between are mapped linearly, so the calibration of the
+	// servo package still applies.
+	MinPulse, MaxPulse time.Duration
+	// MinPosition and MaxPosition are the positions at MinPulse and
+	// MaxPulse, from 0 to 1000.
+	MinPosition, MaxPosition int
+
+	rw io.ReadWriter
+	// loaded are the IDs driven by the controller.
+	loaded map[int]bool
+	lock   *sync.Mutex
+}
+
+// New creates a Controller that writes the commands to rw and reads the
+// answers of the servos from it.
+func New(rw io.ReadWriter) *Controller {
+	return &Controller{
+		MinPulse:    MinPulse,
+		MaxPulse:    MaxPulse,
+		MinPosition: MinPosition,
+		MaxPosition: MaxPosition,
+		rw:          rw,
+		loaded:      make(map[int]bool),
+		lock:        new(sync.Mutex),
+	}
+}
+
+// Write implements the servo.Backend interface. Each servo is moved to its
+// position immediately, and all the commands are sent in a single write. A
+// pulse of 0 unloads the motor of the servo, which is loaded again on its
+// next pulse.
+func (c *Controller) Write(pulses []servo.Pulse) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, p := range pulses {
+		if p.Pin < 0 || p.Pin > maxID {
+			return fmt.Errorf("lewansoul: ID %d is outside the range 0 to %d", p.Pin, maxID)
+		}
+	}
+
+	var msg []byte
+	for _, p := range pulses {
+		id := byte(p.Pin)
+		if p.Width == 0 {
+			if c.loaded[p.Pin] {
+				msg = append(msg, packet(id, cmdLoadWrite, 0)...)
+				delete(c.loaded, p.Pin)
+			}
+			continue
+		}
+		c.loaded[p.Pin] = true
+		pos := c.position(p.Width)
+		msg = append(msg, packet(id, cmdMoveTimeWrite, byte(pos), byte(pos>>8), 0, 0)...)
+	}
+
+	return c.write(msg)
+}
+
+// Close implements the servo.Backend interface. It unloads the motors of the
+// servos driven so far. If the ReadWriter is an io.Closer, it is closed too.
+func (c *Controller) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	ids := make([]int, 0, len(c.loaded))
+	for id := range c.loaded {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+
+	var msg []byte
+	for _, id := range ids {
+		msg = append(msg, packet(byte(id), cmdLoadWrite, 0)...)
+	}
+	c.loaded = make(map[int]bool)
+
+	err := c.write(msg)
+	if closer, ok := c.rw.(io.Closer); ok {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// ReadPosition reads the position of the servo with the ID, from 0 to 1000.
+// The position is negative if the servo is under its range.
+func (c *Controller) ReadPosition(id int) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	req := packet(byte(id), cmdPosRead)
+	if err := c.write(req); err != nil {
+		return 0, err
+	}
+	params, err := c.read(req, byte(id), cmdPosRead, 2)
+	if err != nil {
+		return 0, err
+	}
+
+	return int(int16(uint16(params[0]) | uint16(params[1])<<8)), nil
+}
+
+// Feedback returns the servo.Feedback of the servo, which reads the position
+// of the LX-16A with the pin of the servo as its ID.
+func (c *Controller) Feedback(s *servo.Servo) servo.Feedback {
+	return feedback{c, s}
+}
+
+// feedback reads the angle of a servo from its LX-16A.
+type feedback struct {
+	c *Controller
+	s *servo.Servo
+}
+
+// Angle implements the servo.Feedback interface.
+func (f feedback) Angle() (float64, error) {
+	pos, err := f.c.ReadPosition(f.s.Pin())
+	if err != nil {
+		return 0, err
+	}
+	return f.s.PulseAngle(f.c.pulse(pos)), nil
+}
+
+// position maps the pulse to a position, clamped from 0 to 1000.
+func (c *Controller) position(width time.Duration) int {
+	span := float64(c.MaxPulse - c.MinPulse)
+	if span == 0 {
+		return c.MinPosition
+	}
+	f := float64(width-c.MinPulse) / span
+	pos := c.MinPosition + int(f*float64(c.MaxPosition-c.MinPosition)+0.5)
+
+	switch {
+	case pos < 0:
+		return 0
+	case pos > 1000:
+		return 1000
+	}
+	return pos
+}
+
+// pulse maps the position to a pulse. It is the inverse of position.
+func (c *Controller) pulse(pos int) time.Duration {
+	span := float64(c.MaxPosition - c.MinPosition)
+	if span == 0 {
+		return c.MinPulse
+	}
+	f := float64(pos-c.MinPosition) / span
+	return c.MinPulse + time.Duration(f*float64(c.MaxPulse-c.MinPulse))
+}
+
+// packet returns the command packet with its checksum.
+func packet(id, cmd byte, params ...byte) []byte {
+	p := append([]byte{}, header...)
+	p = append(p, id, byte(len(params)+3), cmd)
+	p = append(p, params...)
+	return append(p, checksum(p[2:]))
+}
+
+// checksum returns the checksum of the bytes between the header and the
+// checksum of a packet.
+func checksum(b []byte) byte {
+	var sum byte
+	for _, v := range b {
+		sum += v
+	}
+	return ^sum
+}
+
+// read reads the answer of the servo id to cmd, with n parameters. The echo
+// of the request req, sent back by some half-duplex adapters, is skipped.
+func (c *Controller) read(req []byte, id, cmd byte, n int) ([]byte, error) {
+	r := &deadlineReader{r: c.rw, deadline: time.Now().Add(readTimeout)}
+	for {
+		p, err := r.packet()
+		if err != nil {
+			return nil, fmt.Errorf("lewansoul: no answer from ID %d: %v", id, err)
+		}
+		if bytes.Equal(p, req) {
+			continue
+		}
+		if checksum(p[2:len(p)-1]) != p[len(p)-1] {
+			return nil, fmt.Errorf("lewansoul: wrong checksum from ID %d", id)
+		}
+		if p[2] == id && p[4] == cmd && len(p) == n+6 {
+			return p[5 : 5+n], nil
+		}
+	}
+}
+
+// deadlineReader reads packets from r until the deadline.
+type deadlineReader struct {
+	r        io.Reader
+	deadline time.Time
+	buf      [1]byte
+}
+
+// readByte reads a single byte, retrying reads of 0 bytes until the
+// deadline.
+func (r *deadlineReader) readByte() (byte, error) {
+	for time.Now().Before(r.deadline) {
+		n, err := r.r.Read(r.buf[:])
+		if n == 1 {
+			return r.buf[0], nil
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+	return 0, fmt.Errorf("timeout")
+}
+
+// packet reads the next packet, including its header.
+func (r *deadlineReader) packet() ([]byte, error) {
+	var prev byte
+	for {
+		b, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		if prev == header[0] && b == header[1] {
+			break
+		}
+		prev = b
+	}
+
+	p := append([]byte{}, header...)
+	for i := 0; i < 2; i++ {
+		b, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		p = append(p, b)
+	}
+	// The length counts itself, the command, the parameters and the
+	// checksum.
+	if p[3] < 3 {
+		return nil, fmt.Errorf("invalid length %d", p[3])
+	}
+	for i := 0; i < int(p[3])-1; i++ {
+		b, err := r.readByte()
+		if err != nil {
+			return nil, err
+		}
+		p = append(p, b)
+	}
+
+	return p, nil
+}
+
+// write sends the commands to the bus.
+func (c *Controller) write(msg []byte) error {
+	if len(msg) == 0 {
+		return nil
+	}
+	if _, err := c.rw.Write(msg); err != nil {
+		return fmt.Errorf("lewansoul: %v", err)
+	}
+	return nil
+}
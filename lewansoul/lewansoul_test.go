@@ -0,0 +1,69 @@
+// +build !live
+
+package lewansoul
+
+import (
+	"bytes"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+func TestPacket(t *testing.T) {
+	// Move ID 1 to position 500 in 1000ms, from the LX-16A manual.
+	got := packet(1, cmdMoveTimeWrite, 0xF4, 0x01, 0xE8, 0x03)
+	want := []byte{0x55, 0x55, 0x01, 0x07, 0x01, 0xF4, 0x01, 0xE8, 0x03, 0x16}
+	if !bytes.Equal(got, want) {
+		t.Errorf("wrong packet\ngot:  % X\nwant: % X", got, want)
+	}
+}
+
+func TestController(t *testing.T) {
+	out := new(bytes.Buffer)
+	c := New(out)
+
+	err := c.Write([]servo.Pulse{
+		{Pin: 1, Width: 1500 * time.Microsecond},
+		{Pin: 2, Width: 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := packet(1, cmdMoveTimeWrite, 0xF4, 0x01, 0, 0)
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("wrong commands\ngot:  % X\nwant: % X", got, want)
+	}
+
+	out.Reset()
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want = packet(1, cmdLoadWrite, 0)
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("motors were not unloaded\ngot:  % X\nwant: % X", got, want)
+	}
+}
+
+func TestController_Feedback(t *testing.T) {
+	bus := new(bytes.Buffer)
+	c := New(bus)
+
+	// Another servo answers first, then the servo at position 875.
+	bus.Write(packet(2, cmdPosRead, 0x00, 0x00))
+	bus.Write(packet(1, cmdPosRead, 0x6B, 0x03))
+
+	s := servo.New(1)
+	got, err := c.Feedback(s).Angle()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(got-180) > 1e-9 {
+		t.Errorf("wrong angle, got: %.2f, want: %.2f", got, 180.0)
+	}
+
+	if _, err := c.ReadPosition(1); err == nil {
+		t.Error("read without answer did not fail")
+	}
+}
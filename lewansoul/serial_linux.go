@@ -0,0 +1,52 @@
+// +build linux
+
+package lewansoul
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// cbaud is the mask of the baud rate in the control flags of Linux.
+const cbaud = 0x100f
+
+// Open opens the bus at the serial port path (e.g. "/dev/ttyUSB0") at the
+// baud rate of the LX-16A.
+func Open(path string) (*Controller, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := raw(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("lewansoul: could not configure %s: %v", path, err)
+	}
+
+	return New(f), nil
+}
+
+// raw sets the serial port in raw mode, 8N1 at Baud. Reads return after 0.1s
+// without data.
+func raw(f *os.File) error {
+	var t syscall.Termios
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&t))); errno != 0 {
+		return errno
+	}
+
+	t.Iflag = 0
+	t.Oflag = 0
+	t.Lflag = 0
+	t.Cflag &^= cbaud | syscall.CSIZE | syscall.PARENB | syscall.CSTOPB
+	t.Cflag |= syscall.B115200 | syscall.CS8 | syscall.CREAD | syscall.CLOCAL
+	t.Ispeed = syscall.B115200
+	t.Ospeed = syscall.B115200
+	t.Cc[syscall.VMIN] = 0
+	t.Cc[syscall.VTIME] = 1
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TCSETS, uintptr(unsafe.Pointer(&t))); errno != 0 {
+		return errno
+	}
+	return nil
+}
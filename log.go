@@ -0,0 +1,77 @@
+package servo
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// debug is set to 1 when debug logging is enabled. It is accessed atomically.
+var debug int32
+
+// debugUntil is the UnixNano time when the temporary debug logging started by
+// Debug ends. It is accessed atomically.
+var debugUntil int64
+
+// debugEnabled checks if debug logging is enabled.
+func debugEnabled() bool {
+	return atomic.LoadInt32(&debug) != 0
+}
+
+// debugf logs a message if debug logging is enabled.
+func debugf(format string, v ...interface{}) {
+	if !debugEnabled() {
+		return
+	}
+	log.Printf("DEBUG: "+format, v...)
+}
+
+// SetDebug enables or disables the debug logging of the servo package.
+func SetDebug(enable bool) {
+	var v int32
+	if enable {
+		v = 1
+	}
+	atomic.StoreInt32(&debug, v)
+	atomic.StoreInt64(&debugUntil, 0)
+}
+
+// Debug enables the debug logging of the servo package for the duration d.
+// Calling Debug again extends the duration.
+func Debug(d time.Duration) {
+	until := time.Now().Add(d).UnixNano()
+	atomic.StoreInt64(&debugUntil, until)
+	atomic.StoreInt32(&debug, 1)
+	log.Printf("servo debug logging enabled for %v", d)
+
+	time.AfterFunc(d, func() {
+		// Only the last call to Debug can disable the logging.
+		if atomic.CompareAndSwapInt64(&debugUntil, until, 0) {
+			atomic.StoreInt32(&debug, 0)
+			log.Printf("servo debug logging disabled")
+		}
+	})
+}
+
+// DumpState writes the state of the servo package and every connected servo
+// to w.
+func DumpState(w io.Writer) {
+	servos := _blaster.connected()
+
+	fmt.Fprintf(w, "servo package: closed=%t, pi-blaster disabled=%t, %d servos connected\n",
+		_blaster.isClosed(), _blaster.pi.isDisabled(), len(servos))
+	for _, s := range servos {
+		fmt.Fprintf(w, "\t%s\n", s.state())
+	}
+}
+
+// state returns a one-line summary of the current state of the servo.
+func (s *Servo) state() string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return fmt.Sprintf("%v position=%.2f target=%.2f speed=%.2f idle=%t pwm=%.6f",
+		s, s.adjust(s.position), s.adjust(s.target), s.speedFraction(), s.idle, s.lastPWM)
+}
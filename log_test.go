@@ -0,0 +1,52 @@
+// +build !live
+
+package servo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDebug(t *testing.T) {
+	defer SetDebug(false)
+
+	Debug(20 * time.Millisecond)
+	if !debugEnabled() {
+		t.Fatal("Debug() did not enable debug logging")
+	}
+	Debug(60 * time.Millisecond)
+
+	time.Sleep(40 * time.Millisecond)
+	if !debugEnabled() {
+		t.Error("Debug() was not extended by the second call")
+	}
+	time.Sleep(40 * time.Millisecond)
+	if debugEnabled() {
+		t.Error("Debug() did not disable debug logging")
+	}
+}
+
+func TestDumpState(t *testing.T) {
+	useBlaster(t)
+
+	for _, pin := range []int{12, 3} {
+		s := New(pin)
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+	}
+
+	b := new(strings.Builder)
+	DumpState(b)
+	got := b.String()
+
+	want := `servo package: closed=false, pi-blaster disabled=true, 2 servos connected
+	servo "Servo3" connected to gpio(3) [flags: ( NONE )] position=0.00 target=0.00 speed=1.00 idle=true pwm=0.000000
+	servo "Servo12" connected to gpio(12) [flags: ( NONE )] position=0.00 target=0.00 speed=1.00 idle=true pwm=0.000000
+`
+	if got != want {
+		t.Errorf("state does not match\ngot:\n%v\nwant:\n%v", got, want)
+	}
+}
@@ -0,0 +1,157 @@
+package servo
+
+import (
+	"fmt"
+	"math"
+	"time"
+)
+
+const (
+	// defaultLoopbackCycles is the default number of pwm cycles measured by
+	// Loopback().
+	defaultLoopbackCycles = 20
+	// defaultLoopbackTolerance is the default fraction of error allowed by
+	// Loopback().
+	defaultLoopbackTolerance = 0.05
+)
+
+// Edge is a level change of an input pin.
+type Edge struct {
+	// Time is the timestamp of the edge, from any fixed origin.
+	Time time.Duration
+	// Rising is true for a low to high change.
+	Rising bool
+}
+
+// EdgeReader reads the edges of an input pin, e.g. a spare GPIO wired to the
+// signal of a servo. See the gpiocdev package for the GPIO character device of
+// Linux.
+type EdgeReader interface {
+	// ReadEdge blocks until the next edge, or returns an error if no edge
+	// comes in time.
+	ReadEdge() (Edge, error)
+}
+
+// LoopbackConfig is the configuration of Servo.Loopback().
+type LoopbackConfig struct {
+	// Input reads the edges of the pin wired to the signal of the servo.
+	Input EdgeReader
+	// Cycles is the number of pwm cycles measured. (default 20)
+	Cycles int
+	// Period is the expected pwm period. (default 10ms, the cycle time of
+	// pi-blaster, see servo.SetPiBlasterFrequency())
+	Period time.Duration
+	// Tolerance is the fraction of error allowed in the pulse width and in
+	// the period. (default 0.05)
+	Tolerance float64
+}
+
+// LoopbackReport is the result of Servo.Loopback().
+type LoopbackReport struct {
+	// Commanded is the last pulse width written for the servo, and
+	// Measured the average pulse width measured on the input.
+	Commanded, Measured time.Duration
+	// Expected is the expected pwm period, and Period the average period
+	// measured on the input.
+	Expected, Period time.Duration
+}
+
+// WidthError returns the difference between the measured and the commanded
+// pulse widths.
+func (r LoopbackReport) WidthError() time.Duration {
+	return r.Measured - r.Commanded
+}
+
+// PeriodError returns the difference between the measured and the expected
+// pwm periods.
+func (r LoopbackReport) PeriodError() time.Duration {
+	return r.Period - r.Expected
+}
+
+// String implements the Stringer interface.
+func (r LoopbackReport) String() string {
+	return fmt.Sprintf("pulse %v (commanded %v, error %v), period %v (expected %v, error %v)",
+		r.Measured, r.Commanded, r.WidthError(), r.Period, r.Expected, r.PeriodError())
+}
+
+// Loopback measures the pulses actually produced for the servo on a spare
+// input pin wired back to its signal, and compares them with the last pulse
+// written for the servo. It catches timing problems of the backend, such as
+// pi-blaster running with a wrong cycle time. The servo must hold a pulse
+// while measuring, e.g. be idle after a move.
+//
+// Loopback returns the report of the measurement, and an error wrapping
+// ErrOutOfRange if the pulse width or the period is off by more than the
+// tolerance.
+func (s *Servo) Loopback(cfg LoopbackConfig) (LoopbackReport, error) {
+	if cfg.Cycles <= 0 {
+		cfg.Cycles = defaultLoopbackCycles
+	}
+	if cfg.Period <= 0 {
+		cfg.Period = piBlasterPeriod()
+	}
+	if cfg.Tolerance <= 0 {
+		cfg.Tolerance = defaultLoopbackTolerance
+	}
+
+	r := LoopbackReport{Expected: cfg.Period}
+	written, at := s.LastPWM()
+	if at.IsZero() || written == 0 {
+		return r, s.wrap(fmt.Errorf("loopback: no pulse was written"))
+	}
+	r.Commanded = time.Duration(written * float64(cfg.Period))
+
+	var err error
+	r.Measured, r.Period, err = measurePulses(cfg.Input, cfg.Cycles)
+	if err != nil {
+		return r, s.wrap(fmt.Errorf("loopback: %v", err))
+	}
+
+	if off(r.Period, r.Expected, cfg.Tolerance) {
+		return r, s.wrap(fmt.Errorf("%w: loopback: period %v, expected %v: check the cycle time of the backend", ErrOutOfRange, r.Period, r.Expected))
+	}
+	if off(r.Measured, r.Commanded, cfg.Tolerance) {
+		return r, s.wrap(fmt.Errorf("%w: loopback: pulse %v, commanded %v", ErrOutOfRange, r.Measured, r.Commanded))
+	}
+
+	return r, nil
+}
+
+// off checks if got is off want by more than the fraction tolerance of want.
+func off(got, want time.Duration, tolerance float64) bool {
+	return math.Abs(float64(got-want)) > tolerance*float64(want)
+}
+
+// measurePulses returns the average pulse width and period of cycles pwm
+// cycles read from r. The first edges are skipped until a rising edge.
+func measurePulses(r EdgeReader, cycles int) (width, period time.Duration, err error) {
+	var rise time.Duration
+	started, high := false, false
+	var widths, periods time.Duration
+	nw, np := 0, 0
+
+	for np < cycles {
+		e, err := r.ReadEdge()
+		if err != nil {
+			return 0, 0, err
+		}
+		switch {
+		case e.Rising:
+			if started {
+				periods += e.Time - rise
+				np++
+			}
+			rise = e.Time
+			started, high = true, true
+		case high:
+			widths += e.Time - rise
+			nw++
+			high = false
+		}
+	}
+	if nw == 0 {
+		return 0, 0, fmt.Errorf("no falling edge")
+	}
+
+	return widths / time.Duration(nw), periods / time.Duration(np), nil
+}
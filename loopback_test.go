@@ -0,0 +1,80 @@
+// +build !live
+
+package servo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// fakeEdges generates the edges of a pwm signal.
+type fakeEdges struct {
+	width, period time.Duration
+	now           time.Duration
+	high          bool
+}
+
+func (f *fakeEdges) ReadEdge() (Edge, error) {
+	if f.width == 0 {
+		return Edge{}, fmt.Errorf("timeout")
+	}
+	if f.high {
+		f.high = false
+		return Edge{Time: f.now + f.width}, nil
+	}
+	f.now += f.period
+	f.high = true
+	return Edge{Time: f.now, Rising: true}, nil
+}
+
+func TestServo_Loopback(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if _, err := s.Loopback(LoopbackConfig{Input: &fakeEdges{}}); err == nil {
+		t.Error("measured a servo without pulse")
+	}
+
+	s.MoveTo(90).Wait()
+	time.Sleep(20 * time.Millisecond)
+
+	r, err := s.Loopback(LoopbackConfig{Input: &fakeEdges{
+		width:  1510 * time.Microsecond,
+		period: 10 * time.Millisecond,
+		// Start in the middle of a pulse.
+		high: true,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := r.Measured, 1510*time.Microsecond; got != want {
+		t.Errorf("wrong pulse, got: %v, want: %v", got, want)
+	}
+	if got, want := r.WidthError(), 10*time.Microsecond; got < want-time.Microsecond || got > want+time.Microsecond {
+		t.Errorf("wrong pulse error, got: %v, want: %v", got, want)
+	}
+
+	// pi-blaster with a cycle time of 20ms makes the pulses twice as long.
+	r, err = s.Loopback(LoopbackConfig{Input: &fakeEdges{
+		width:  3000 * time.Microsecond,
+		period: 20 * time.Millisecond,
+	}})
+	if !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("wrong cycle time was not caught, got: %v", err)
+	}
+	if got, want := r.PeriodError(), 10*time.Millisecond; got != want {
+		t.Errorf("wrong period error, got: %v, want: %v", got, want)
+	}
+
+	if _, err := s.Loopback(LoopbackConfig{Input: &fakeEdges{}}); err == nil {
+		t.Error("measured without edges")
+	}
+}
@@ -0,0 +1,222 @@
+// Package maestro drives servos with a Pololu Maestro USB servo controller,
+// using its compact protocol over the serial command port. The Maestro
+// generates the pulses in hardware, so they are free of the jitter of the GPIO
+// timing of the Pi.
+//
+// Set a Controller as the backend of the servo package, and use the channel
+// of the Maestro as the pin of each servo:
+//
+//	m, err := maestro.Open("/dev/ttyACM0")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	servo.SetBackend(m)
+//	defer servo.Close()
+//
+//	s := servo.New(0) // channel 0 of the Maestro
+package maestro
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+// Commands of the compact protocol.
+const (
+	cmdSetTarget       = 0x84
+	cmdSetSpeed        = 0x87
+	cmdSetAcceleration = 0x89
+	cmdGetPosition     = 0x90
+
+	// Channels is the maximum number of channels of a Maestro.
+	Channels = 24
+
+	// maxWidth is the longest target of the 14 bits of the protocol.
+	maxWidth = (1<<14 - 1) * time.Microsecond / 4
+
+	// readTimeout is how long to wait for the answer of the Maestro.
+	readTimeout = 100 * time.Millisecond
+)
+
+// Controller is a Pololu Maestro. It implements the servo.Backend interface.
+// Use maestro.Open() or maestro.New() for correct initialization.
+type Controller struct {
+	rw io.ReadWriter
+	// channels are the channels written since the last Close.
+	channels map[int]bool
+	lock     *sync.Mutex
+}
+
+// New creates a Controller that writes the commands to rw and reads the
+// answers of the Maestro from it.
+func New(rw io.ReadWriter) *Controller {
+	return &Controller{
+		rw:       rw,
+		channels: make(map[int]bool),
+		lock:     new(sync.Mutex),
+	}
+}
+
+// Write implements the servo.Backend interface. All the targets are sent in a
+// single write. A pulse of 0 stops the pulses of the channel.
+func (c *Controller) Write(pulses []servo.Pulse) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, p := range pulses {
+		if err := check(p.Pin); err != nil {
+			return err
+		}
+		if p.Width < 0 || p.Width > maxWidth {
+			return fmt.Errorf("maestro: pulse %v of channel %d is too long", p.Width, p.Pin)
+		}
+	}
+
+	var msg []byte
+	for _, p := range pulses {
+		// The targets are in quarters of microsecond.
+		msg = command(msg, cmdSetTarget, p.Pin, int(p.Width*4/time.Microsecond))
+		c.channels[p.Pin] = true
+	}
+
+	return c.write(msg)
+}
+
+// Close implements the servo.Backend interface. It stops the pulses of the
+// channels written so far. If the ReadWriter is an io.Closer, it is closed
+// too.
+func (c *Controller) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	channels := make([]int, 0, len(c.channels))
+	for ch := range c.channels {
+		channels = append(channels, ch)
+	}
+	sort.Ints(channels)
+
+	var msg []byte
+	for _, ch := range channels {
+		msg = command(msg, cmdSetTarget, ch, 0)
+	}
+	c.channels = make(map[int]bool)
+
+	err := c.write(msg)
+	if closer, ok := c.rw.(io.Closer); ok {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// SetSpeed sets the native speed limit of the channel, in units of 0.25us per
+// 10ms, or 0 for no limit (default). The servo package already limits the
+// speed of the servos, so the native limit is only needed to smooth the steps
+// between the writes of the manager.
+func (c *Controller) SetSpeed(channel, speed int) error {
+	return c.set(cmdSetSpeed, channel, speed)
+}
+
+// SetAcceleration sets the native acceleration limit of the channel, from 1
+// to 255 in units of 0.25us per 10ms per 80ms, or 0 for no limit (default).
+func (c *Controller) SetAcceleration(channel, acceleration int) error {
+	return c.set(cmdSetAcceleration, channel, acceleration)
+}
+
+// ReadPosition reads the pulse the Maestro is sending to the channel, which
+// lags behind the target while a native speed or acceleration limit applies.
+func (c *Controller) ReadPosition(channel int) (time.Duration, error) {
+	if err := check(channel); err != nil {
+		return 0, err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.write([]byte{cmdGetPosition, byte(channel)}); err != nil {
+		return 0, err
+	}
+
+	var answer [2]byte
+	deadline := time.Now().Add(readTimeout)
+	for n := 0; n < len(answer); {
+		if !time.Now().Before(deadline) {
+			return 0, fmt.Errorf("maestro: no answer for channel %d", channel)
+		}
+		m, err := c.rw.Read(answer[n:])
+		n += m
+		if err != nil && n < len(answer) {
+			return 0, fmt.Errorf("maestro: no answer for channel %d: %v", channel, err)
+		}
+	}
+
+	quarters := int(answer[0]) | int(answer[1])<<8
+	return time.Duration(quarters) * time.Microsecond / 4, nil
+}
+
+// Feedback returns the servo.Feedback of the servo, which reads the pulse of
+// the channel of the servo.
+func (c *Controller) Feedback(s *servo.Servo) servo.Feedback {
+	return feedback{c, s}
+}
+
+// feedback reads the angle of a servo from the pulse of its channel.
+type feedback struct {
+	c *Controller
+	s *servo.Servo
+}
+
+// Angle implements the servo.Feedback interface.
+func (f feedback) Angle() (float64, error) {
+	width, err := f.c.ReadPosition(f.s.Pin())
+	if err != nil {
+		return 0, err
+	}
+	return f.s.PulseAngle(width), nil
+}
+
+// set sends a command with a 14-bit value to the channel.
+func (c *Controller) set(cmd byte, channel, value int) error {
+	if err := check(channel); err != nil {
+		return err
+	}
+	if value < 0 || value >= 1<<14 {
+		return fmt.Errorf("maestro: value %d is outside the range 0 to %d", value, 1<<14-1)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.write(command(nil, cmd, channel, value))
+}
+
+// check returns an error if the channel does not exist.
+func check(channel int) error {
+	if channel < 0 || channel >= Channels {
+		return fmt.Errorf("maestro: channel %d is outside the range 0 to %d", channel, Channels-1)
+	}
+	return nil
+}
+
+// command appends the command with the 14-bit value for the channel to msg.
+func command(msg []byte, cmd byte, channel, value int) []byte {
+	return append(msg, cmd, byte(channel), byte(value&0x7F), byte(value>>7&0x7F))
+}
+
+// write sends the commands to the Maestro.
+func (c *Controller) write(msg []byte) error {
+	if len(msg) == 0 {
+		return nil
+	}
+	if _, err := c.rw.Write(msg); err != nil {
+		return fmt.Errorf("maestro: %v", err)
+	}
+	return nil
+}
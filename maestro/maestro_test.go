@@ -0,0 +1,110 @@
+// +build !live
+
+package maestro
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+func TestCommand(t *testing.T) {
+	// Set the target of channel 2 to 1500us (6000), from the Maestro manual.
+	got := command(nil, cmdSetTarget, 2, 6000)
+	want := []byte{0x84, 0x02, 0x70, 0x2E}
+	if !bytes.Equal(got, want) {
+		t.Errorf("wrong command\ngot:  % X\nwant: % X", got, want)
+	}
+}
+
+func TestController(t *testing.T) {
+	out := new(bytes.Buffer)
+	c := New(out)
+
+	err := c.Write([]servo.Pulse{
+		{Pin: 2, Width: 1500 * time.Microsecond},
+		{Pin: 0, Width: 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x84, 0x02, 0x70, 0x2E, 0x84, 0x00, 0x00, 0x00}
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("wrong commands\ngot:  % X\nwant: % X", got, want)
+	}
+
+	out.Reset()
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	want = []byte{0x84, 0x00, 0x00, 0x00, 0x84, 0x02, 0x00, 0x00}
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("pulses were not stopped\ngot:  % X\nwant: % X", got, want)
+	}
+}
+
+func TestController_invalid(t *testing.T) {
+	out := new(bytes.Buffer)
+	c := New(out)
+
+	if err := c.Write([]servo.Pulse{{Pin: Channels, Width: time.Millisecond}}); err == nil {
+		t.Error("wrote a channel out of range")
+	}
+	if err := c.Write([]servo.Pulse{{Pin: 0, Width: 5 * time.Millisecond}}); err == nil {
+		t.Error("wrote a pulse out of range")
+	}
+	if err := c.SetSpeed(0, 1<<14); err == nil {
+		t.Error("set a speed out of range")
+	}
+	if out.Len() != 0 {
+		t.Errorf("invalid writes were sent: % X", out.Bytes())
+	}
+}
+
+func TestController_SetSpeed(t *testing.T) {
+	out := new(bytes.Buffer)
+	c := New(out)
+
+	if err := c.SetSpeed(1, 140); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetAcceleration(1, 4); err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{0x87, 0x01, 0x0C, 0x01, 0x89, 0x01, 0x04, 0x00}
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("wrong commands\ngot:  % X\nwant: % X", got, want)
+	}
+}
+
+func TestController_Feedback(t *testing.T) {
+	bus := new(bytes.Buffer)
+	c := New(bus)
+
+	s := servo.New(3)
+	// The Maestro answers 2500us (10000) after the request.
+	bus.Write([]byte{0x10, 0x27})
+	got, err := c.Feedback(s).Angle()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(got-180) > 0.5 {
+		t.Errorf("wrong angle, got: %.2f, want: 180.00", got)
+	}
+	if want := []byte{0x90, 0x03}; !bytes.Equal(bus.Bytes(), want) {
+		t.Errorf("wrong request\ngot:  % X\nwant: % X", bus.Bytes(), want)
+	}
+
+	silent := struct {
+		io.Reader
+		io.Writer
+	}{new(bytes.Buffer), ioutil.Discard}
+	if _, err := New(silent).ReadPosition(3); err == nil {
+		t.Error("read a position without an answer")
+	}
+}
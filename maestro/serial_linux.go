@@ -0,0 +1,47 @@
+// +build linux
+
+package maestro
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+// Open opens the Maestro at its command port path (e.g. "/dev/ttyACM0"). The
+// command port is a USB virtual serial port, so its baud rate does not matter.
+func Open(path string) (*Controller, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|syscall.O_NOCTTY, 0)
+	if err != nil {
+		return nil, err
+	}
+	if err := raw(f); err != nil {
+		f.Close()
+		return nil, fmt.Errorf("maestro: could not configure %s: %v", path, err)
+	}
+
+	return New(f), nil
+}
+
+// raw sets the serial port in raw mode, 8N1. Reads return after 0.1s without
+// data.
+func raw(f *os.File) error {
+	var t syscall.Termios
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TCGETS, uintptr(unsafe.Pointer(&t))); errno != 0 {
+		return errno
+	}
+
+	t.Iflag = 0
+	t.Oflag = 0
+	t.Lflag = 0
+	t.Cflag &^= syscall.CSIZE | syscall.PARENB | syscall.CSTOPB
+	t.Cflag |= syscall.CS8 | syscall.CREAD | syscall.CLOCAL
+	t.Cc[syscall.VMIN] = 0
+	t.Cc[syscall.VTIME] = 1
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), syscall.TCSETS, uintptr(unsafe.Pointer(&t))); errno != 0 {
+		return errno
+	}
+	return nil
+}
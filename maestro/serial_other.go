@@ -0,0 +1,10 @@
+// +build !linux
+
+package maestro
+
+import "fmt"
+
+// Open is only supported on Linux.
+func Open(path string) (*Controller, error) {
+	return nil, fmt.Errorf("maestro: serial ports are only supported on linux")
+}
@@ -0,0 +1,60 @@
+package servo
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// maintenanceUntil is the end of the maintenance mode in Unix nanoseconds, or
+// 0 if not in maintenance mode. It is accessed atomically.
+var maintenanceUntil int64
+
+// EnterMaintenance puts the servo package in maintenance mode for d, e.g.
+// while a technician has their hands inside a machine driven by remote
+// clients. Every connected servo is stopped and released (0 duty), and no
+// servo is driven until the maintenance mode ends: MoveTo() and SetPosition()
+// are ignored, and the calls that return errors, like MoveToBy(), return
+// ErrMaintenance. Events and metrics keep working.
+//
+// The maintenance mode ends by itself after d, or when ExitMaintenance() is
+// called. The servos stay released until their next move.
+func EnterMaintenance(d time.Duration) {
+	atomic.StoreInt64(&maintenanceUntil, time.Now().Add(d).UnixNano())
+	debugf("maintenance mode for %v", d)
+
+	for _, s := range _blaster.connected() {
+		s.detach(false)
+	}
+}
+
+// ExitMaintenance ends the maintenance mode before its time.
+func ExitMaintenance() {
+	atomic.StoreInt64(&maintenanceUntil, 0)
+	debugf("maintenance mode ended")
+}
+
+// Maintenance returns the end of the maintenance mode, or the zero time if the
+// servo package is not in maintenance mode.
+func Maintenance() time.Time {
+	until := atomic.LoadInt64(&maintenanceUntil)
+	if until == 0 || time.Now().UnixNano() >= until {
+		return time.Time{}
+	}
+	return time.Unix(0, until)
+}
+
+// InMaintenance returns true if the servo package is in maintenance mode.
+func InMaintenance() bool {
+	return !Maintenance().IsZero()
+}
+
+// maintenanceErr returns ErrMaintenance with the end of the maintenance mode,
+// or nil if not in maintenance mode.
+func maintenanceErr() error {
+	until := Maintenance()
+	if until.IsZero() {
+		return nil
+	}
+	return fmt.Errorf("%w until %v", ErrMaintenance, until.Format(time.RFC3339))
+}
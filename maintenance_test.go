@@ -0,0 +1,56 @@
+// +build !live
+
+package servo
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEnterMaintenance(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+	defer ExitMaintenance()
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.MoveTo(180)
+	time.Sleep(20 * time.Millisecond)
+
+	EnterMaintenance(100 * time.Millisecond)
+	if !InMaintenance() {
+		t.Fatal("not in maintenance mode")
+	}
+	time.Sleep(20 * time.Millisecond)
+	if got, _ := s.LastPWM(); got != 0 {
+		t.Errorf("servo was not released, got: %.4f, want: %.4f", got, 0.0)
+	}
+
+	at := s.Position()
+	s.MoveTo(0).Wait()
+	if s.Position() != at {
+		t.Errorf("servo moved in maintenance mode, got: %.2f, want: %.2f", s.Position(), at)
+	}
+	if _, err := s.MoveToBy(0, Now().Add(time.Hour)); !errors.Is(err, ErrMaintenance) {
+		t.Errorf("MoveToBy in maintenance mode, got: %v, want: %v", err, ErrMaintenance)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if InMaintenance() {
+		t.Fatal("maintenance mode did not end by itself")
+	}
+	s.MoveTo(0).Wait()
+	if s.Position() != 0 {
+		t.Errorf("servo did not move after maintenance, got: %.2f, want: %.2f", s.Position(), 0.0)
+	}
+
+	EnterMaintenance(time.Hour)
+	ExitMaintenance()
+	if InMaintenance() {
+		t.Error("ExitMaintenance did not end the maintenance mode")
+	}
+}
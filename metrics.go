@@ -0,0 +1,85 @@
+package servo
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Counters holds the monotonic counters of a servo. They only increase during
+// the life of the servo, so alerting rules can watch their rate.
+type Counters struct {
+	// Connects counts the successful calls to Connect().
+	Connects uint64
+	// Clamps counts the targets clamped to the range of the servo.
+	Clamps uint64
+	// Stops counts the calls to Stop(), including the stops of servo.Close().
+	Stops uint64
+	// Faults counts the times the servo entered the fault state.
+	Faults uint64
+	// WriteErrors counts the failed writes of the servo.
+	WriteErrors uint64
+	// WatchdogTrips counts the times the watchdog of the servo tripped.
+	WatchdogTrips uint64
+}
+
+// Counters returns the current counters of the servo.
+func (s *Servo) Counters() Counters {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.counters
+}
+
+// labelEscaper escapes the value of a label in the Prometheus text format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// WriteMetrics writes the counters of the connected servos to w in the
+// Prometheus text exposition format, labeled by servo name and pin. Serve it
+// on an HTTP handler to scrape it:
+//
+//	http.HandleFunc("/metrics", func(w http.ResponseWriter, r *http.Request) {
+//		servo.WriteMetrics(w)
+//	})
+func WriteMetrics(w io.Writer) error {
+	servos := _blaster.connected()
+	counters := make([]Counters, len(servos))
+	for i, s := range servos {
+		counters[i] = s.Counters()
+	}
+
+	metrics := []struct {
+		name, help string
+		value      func(c Counters) uint64
+	}{
+		{"servo_connects_total", "Number of times the servo was connected.",
+			func(c Counters) uint64 { return c.Connects }},
+		{"servo_clamps_total", "Number of targets clamped to the range of the servo.",
+			func(c Counters) uint64 { return c.Clamps }},
+		{"servo_stops_total", "Number of times the servo was stopped.",
+			func(c Counters) uint64 { return c.Stops }},
+		{"servo_faults_total", "Number of times the servo entered the fault state.",
+			func(c Counters) uint64 { return c.Faults }},
+		{"servo_write_errors_total", "Number of failed writes of the servo.",
+			func(c Counters) uint64 { return c.WriteErrors }},
+		{"servo_watchdog_trips_total", "Number of times the watchdog of the servo tripped.",
+			func(c Counters) uint64 { return c.WatchdogTrips }},
+	}
+
+	bw := bufio.NewWriter(w)
+	for _, m := range metrics {
+		fmt.Fprintf(bw, "# HELP %s %s\n", m.name, m.help)
+		fmt.Fprintf(bw, "# TYPE %s counter\n", m.name)
+		for i, s := range servos {
+			fmt.Fprintf(bw, "%s{servo=\"%s\",pin=\"%d\"} %d\n",
+				m.name, labelEscaper.Replace(s.Name), s.gpio(), m.value(counters[i]))
+		}
+	}
+
+	fmt.Fprintf(bw, "# HELP servo_dropped_frames_total Number of frames that could not be written to the backend.\n")
+	fmt.Fprintf(bw, "# TYPE servo_dropped_frames_total counter\n")
+	fmt.Fprintf(bw, "servo_dropped_frames_total %d\n", DroppedFrames())
+
+	return bw.Flush()
+}
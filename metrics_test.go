@@ -0,0 +1,43 @@
+// +build !live
+
+package servo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWriteMetrics(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	s.Name = `jaw "left"`
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.MoveTo(270)
+	s.Stop()
+	s.SetFault(errJogAborted)
+
+	c := s.Counters()
+	want := Counters{Connects: 1, Clamps: 1, Stops: 1, Faults: 1}
+	if c != want {
+		t.Errorf("wrong counters, got: %+v, want: %+v", c, want)
+	}
+
+	buf := new(strings.Builder)
+	if err := WriteMetrics(buf); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{
+		"# TYPE servo_clamps_total counter\n",
+		`servo_clamps_total{servo="jaw \"left\"",pin="99"} 1` + "\n",
+		`servo_write_errors_total{servo="jaw \"left\"",pin="99"} 0` + "\n",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Errorf("missing %q in metrics:\n%s", line, buf)
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package servo
+
+import "sync"
+
+// MoveCanceled is the outcome of a move canceled by Move.Cancel().
+const MoveCanceled MoveOutcome = "canceled"
+
+// Move is the handle of a single move of a servo, returned by Servo.MoveTo().
+// Unlike Servo.Wait(), which waits until the servo is idle, a Move only waits
+// for its own move, and tells how it ended. A goroutine waiting on a move can
+// then find out that another goroutine overrode it.
+type Move struct {
+	servo *Servo
+	// trace is the span of the move, if there is a tracer.
+	trace Span
+	// stopping is set when the move is shortened by Servo.SoftStop(). It is
+	// guarded by the lock of the servo.
+	stopping bool
+	// jerk is the jerk limit of the move, if hasJerk. See WithJerk().
+	jerk    float64
+	hasJerk bool
+	// moveSpeed is the speed limit of the move, if hasSpeed. See
+	// WithSpeed().
+	moveSpeed float64
+	hasSpeed  bool
+	// ease is the easing curve of the move, if not nil. See WithEasing().
+	ease Easing
+	// planner plans the profile of the move from the raw position from at
+	// speed, if it follows a path, e.g. Servo.FollowPath() and
+	// Servo.Sweep().
+	planner func(from, speed float64) profile
+	// arrive is the least duration of the move in seconds, if not 0. See
+	// arriveIn().
+	arrive float64
+	// path is the planned position of the move, speed the speed of the
+	// servo when it was planned, and elapsed the seconds played of it. They
+	// are only accessed by the manager.
+	path    profile
+	speed   float64
+	elapsed float64
+
+	outcome MoveOutcome
+	done    chan struct{}
+	once    *sync.Once
+}
+
+// MoveOption sets an option of a single move, e.g.:
+//
+//	s.MoveTo(90, servo.WithJerk(2000))
+type MoveOption func(m *Move)
+
+// newMove starts a move of the servo to target, adjusted for its Flags, with
+// the options.
+func newMove(s *Servo, target float64, opts []MoveOption) *Move {
+	m := &Move{
+		servo: s,
+		trace: startSpan(s, target),
+		done:  make(chan struct{}),
+		once:  new(sync.Once),
+	}
+	for _, opt := range opts {
+		opt(m)
+	}
+	return m
+}
+
+// Wait waits for the move to end, even if the servo keeps moving to the
+// target of another move.
+func (m *Move) Wait() {
+	<-m.done
+}
+
+// Done returns a channel that is closed when the move ends.
+func (m *Move) Done() <-chan struct{} {
+	return m.done
+}
+
+// Result waits for the move to end and returns how it ended: MoveFinished if
+// it reached its target, MoveReplaced if another move overrode it,
+// MoveStopped if the servo was stopped, MoveCanceled if it was canceled, or
+// MoveFaulted if the servo is in the fault state.
+func (m *Move) Result() MoveOutcome {
+	<-m.done
+	return m.outcome
+}
+
+// Cancel stops the servo if the move is still its current move, like
+// Servo.Stop(). It does nothing if the move already ended, so it never stops
+// the move of another goroutine.
+func (m *Move) Cancel() {
+	m.servo.stop(m, MoveCanceled)
+}
+
+// flushed traces a pwm of the move written to the backend.
+func (m *Move) flushed(pwm float64) {
+	if m.trace != nil {
+		m.trace.Flushed(pwm)
+	}
+}
+
+// end records the outcome of the move and releases its waiters. Only the
+// first call has any effect.
+func (m *Move) end(outcome MoveOutcome) {
+	m.once.Do(func() {
+		m.outcome = outcome
+		close(m.done)
+		endSpan(m.trace, outcome)
+	})
+}
+
+// endMove ends the move, if any.
+func endMove(m *Move, outcome MoveOutcome) {
+	if m != nil {
+		m.end(outcome)
+	}
+}
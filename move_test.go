@@ -0,0 +1,113 @@
+// +build !live
+
+package servo
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMove_Result(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetSpeed(0.5)
+
+	if got := s.MoveTo(20).Result(); got != MoveFinished {
+		t.Errorf("wrong outcome, got: %v, want: %v", got, MoveFinished)
+	}
+
+	first := s.MoveTo(180)
+	second := s.MoveTo(90)
+	if got := first.Result(); got != MoveReplaced {
+		t.Errorf("wrong outcome of the first move, got: %v, want: %v", got, MoveReplaced)
+	}
+	select {
+	case <-second.Done():
+		t.Error("second move ended with the first one")
+	default:
+	}
+	if got := second.Result(); got != MoveFinished {
+		t.Errorf("wrong outcome of the second move, got: %v, want: %v", got, MoveFinished)
+	}
+
+	m := s.MoveTo(180)
+	s.Stop()
+	if got := m.Result(); got != MoveStopped {
+		t.Errorf("wrong outcome, got: %v, want: %v", got, MoveStopped)
+	}
+
+	m = s.MoveTo(0)
+	s.SetPosition(90)
+	if got := m.Result(); got != MoveReplaced {
+		t.Errorf("wrong outcome after SetPosition, got: %v, want: %v", got, MoveReplaced)
+	}
+	s.Wait()
+
+	m = s.MoveTo(180)
+	time.Sleep(20 * time.Millisecond)
+	s.SoftStop(20 * time.Millisecond).Wait()
+	if got := m.Result(); got != MoveStopped {
+		t.Errorf("wrong outcome after SoftStop, got: %v, want: %v", got, MoveStopped)
+	}
+}
+
+func TestMove_Cancel(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetSpeed(0.5)
+
+	m := s.MoveTo(180)
+	time.Sleep(20 * time.Millisecond)
+	m.Cancel()
+	if got := m.Result(); got != MoveCanceled {
+		t.Errorf("wrong outcome, got: %v, want: %v", got, MoveCanceled)
+	}
+	if !s.isIdle() {
+		t.Error("servo kept moving after Cancel()")
+	}
+
+	// Canceling an old move does not stop the current one.
+	old := s.MoveTo(0)
+	current := s.MoveTo(90)
+	old.Cancel()
+	if got := old.Result(); got != MoveReplaced {
+		t.Errorf("wrong outcome of the old move, got: %v, want: %v", got, MoveReplaced)
+	}
+	if got := current.Result(); got != MoveFinished {
+		t.Errorf("wrong outcome of the current move, got: %v, want: %v", got, MoveFinished)
+	}
+	if s.Position() != 90 {
+		t.Errorf("wrong position, got: %.2f, want: 90.00", s.Position())
+	}
+}
+
+func TestMove_fault(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetSpeed(0.5)
+
+	m := s.MoveTo(180)
+	s.SetFault(errors.New("stall"))
+	if got := m.Result(); got != MoveFaulted {
+		t.Errorf("wrong outcome, got: %v, want: %v", got, MoveFaulted)
+	}
+	if got := s.MoveTo(0).Result(); got != MoveFaulted {
+		t.Errorf("wrong outcome of a refused move, got: %v, want: %v", got, MoveFaulted)
+	}
+}
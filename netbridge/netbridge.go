@@ -0,0 +1,238 @@
+// Package netbridge streams the pulses of the servos over UDP or TCP to a
+// remote agent, e.g. a microcontroller or another Raspberry Pi that drives the
+// servos. The motion logic can then run on any machine of the network.
+//
+// On the machine with the motion logic, set a Controller as the backend of
+// the servo package:
+//
+//	nb, err := netbridge.Dial("udp", "robot.local:7700")
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	servo.SetBackend(nb)
+//	defer servo.Close()
+//
+// A Go agent can apply the received frames to a local backend with
+// netbridge.Serve(). Agents in other languages implement the frame format
+// documented in Controller.Write().
+package netbridge
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+const (
+	// Port is the default port of the agents.
+	Port = 7700
+
+	// magic and version start every frame.
+	magic   = 'S'
+	version = 1
+	// headerSize is the size of the header of a frame, and pulseSize the
+	// size of each pulse.
+	headerSize = 7
+	pulseSize  = 3
+	// maxPulses is the maximum number of pulses in a frame.
+	maxPulses = 255
+	// staleWindow is how far behind the last sequence number a frame is
+	// considered stale. Frames further behind are taken as a restart of the
+	// Controller.
+	staleWindow = 1 << 16
+)
+
+// Controller streams the pulses to a remote agent. It implements the
+// servo.Backend interface. Use netbridge.Dial() or netbridge.New() for correct
+// initialization.
+type Controller struct {
+	w io.Writer
+	// seq is the sequence number of the last frame. It starts at the
+	// current time in milliseconds, so a restarted Controller is not taken
+	// for a stale one.
+	seq uint32
+	// pins are the pins written since the last Close.
+	pins map[int]bool
+	lock *sync.Mutex
+}
+
+// Dial connects to the agent at address on the network, "udp" or "tcp".
+func Dial(network, address string) (*Controller, error) {
+	conn, err := net.Dial(network, address)
+	if err != nil {
+		return nil, err
+	}
+	return New(conn), nil
+}
+
+// New creates a Controller that writes the frames to w. Each frame is sent in
+// a single write, so w can be a datagram connection.
+func New(w io.Writer) *Controller {
+	return &Controller{
+		w:    w,
+		seq:  uint32(time.Now().UnixNano() / int64(time.Millisecond)),
+		pins: make(map[int]bool),
+		lock: new(sync.Mutex),
+	}
+}
+
+// Write implements the servo.Backend interface. The pulses are sent as a
+// single frame:
+//
+//	byte   'S'
+//	byte   version (1)
+//	uint32 sequence number, increased on every frame
+//	byte   number of pulses
+//	then, for each pulse:
+//	byte   pin
+//	uint16 width in microseconds, 0 releases the pin
+//
+// All the integers are big-endian. Pins must be between 0 and 255.
+func (c *Controller) Write(pulses []servo.Pulse) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.send(pulses); err != nil {
+		return err
+	}
+	for _, p := range pulses {
+		c.pins[p.Pin] = true
+	}
+	return nil
+}
+
+// Close implements the servo.Backend interface. It releases the pins written
+// so far. If the writer is an io.Closer, it is closed too.
+func (c *Controller) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	pins := make([]int, 0, len(c.pins))
+	for pin := range c.pins {
+		pins = append(pins, pin)
+	}
+	sort.Ints(pins)
+
+	pulses := make([]servo.Pulse, len(pins))
+	for i, pin := range pins {
+		pulses[i] = servo.Pulse{Pin: pin}
+	}
+	c.pins = make(map[int]bool)
+
+	var err error
+	if len(pulses) > 0 {
+		err = c.send(pulses)
+	}
+	if closer, ok := c.w.(io.Closer); ok {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// send writes the pulses in frames of at most maxPulses.
+func (c *Controller) send(pulses []servo.Pulse) error {
+	for len(pulses) > 0 {
+		n := len(pulses)
+		if n > maxPulses {
+			n = maxPulses
+		}
+		c.seq++
+		frame, err := encode(c.seq, pulses[:n])
+		if err != nil {
+			return err
+		}
+		if _, err := c.w.Write(frame); err != nil {
+			return fmt.Errorf("netbridge: %v", err)
+		}
+		pulses = pulses[n:]
+	}
+	return nil
+}
+
+// encode returns the frame of the pulses.
+func encode(seq uint32, pulses []servo.Pulse) ([]byte, error) {
+	frame := make([]byte, headerSize, headerSize+pulseSize*len(pulses))
+	frame[0] = magic
+	frame[1] = version
+	binary.BigEndian.PutUint32(frame[2:], seq)
+	frame[6] = byte(len(pulses))
+
+	for _, p := range pulses {
+		if p.Pin < 0 || p.Pin > 255 {
+			return nil, fmt.Errorf("netbridge: pin %d is outside the range 0 to 255", p.Pin)
+		}
+		us := p.Width / time.Microsecond
+		if us < 0 || us > 0xFFFF {
+			return nil, fmt.Errorf("netbridge: pulse %v of pin %d is too long", p.Width, p.Pin)
+		}
+		frame = append(frame, byte(p.Pin), byte(us>>8), byte(us))
+	}
+
+	return frame, nil
+}
+
+// readFrame reads the next frame from r.
+func readFrame(r io.Reader) (uint32, []servo.Pulse, error) {
+	header := make([]byte, headerSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, nil, err
+	}
+	if header[0] != magic || header[1] != version {
+		return 0, nil, fmt.Errorf("netbridge: unknown frame % X", header[:2])
+	}
+	seq := binary.BigEndian.Uint32(header[2:])
+
+	body := make([]byte, pulseSize*int(header[6]))
+	if _, err := io.ReadFull(r, body); err != nil {
+		return 0, nil, err
+	}
+	pulses := make([]servo.Pulse, 0, header[6])
+	for i := 0; i < len(body); i += pulseSize {
+		pulses = append(pulses, servo.Pulse{
+			Pin:   int(body[i]),
+			Width: time.Duration(binary.BigEndian.Uint16(body[i+1:])) * time.Microsecond,
+		})
+	}
+
+	return seq, pulses, nil
+}
+
+// Serve reads the frames of a Controller from r and writes their pulses to b,
+// until r returns io.EOF. Frames that arrive after a newer frame, e.g. over
+// UDP, are dropped. Serve does not close b.
+func Serve(r io.Reader, b servo.Backend) error {
+	// A bufio.Reader reads a whole datagram at once.
+	br := bufio.NewReader(r)
+
+	var last uint32
+	first := true
+	for {
+		seq, pulses, err := readFrame(br)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		if !first && last-seq < staleWindow {
+			continue
+		}
+		first = false
+		last = seq
+
+		if err := b.Write(pulses); err != nil {
+			return err
+		}
+	}
+}
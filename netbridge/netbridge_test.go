@@ -0,0 +1,99 @@
+// +build !live
+
+package netbridge
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+// recordBackend records the pulses written to it.
+type recordBackend struct {
+	writes [][]servo.Pulse
+}
+
+func (b *recordBackend) Write(pulses []servo.Pulse) error {
+	b.writes = append(b.writes, pulses)
+	return nil
+}
+
+func (b *recordBackend) Close() error { return nil }
+
+func TestController(t *testing.T) {
+	out := new(bytes.Buffer)
+	c := New(out)
+	c.seq = 41
+
+	err := c.Write([]servo.Pulse{
+		{Pin: 4, Width: 1500 * time.Microsecond},
+		{Pin: 17, Width: 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []byte{'S', 1, 0, 0, 0, 42, 2, 4, 0x05, 0xDC, 17, 0, 0}
+	if got := out.Bytes(); !bytes.Equal(got, want) {
+		t.Errorf("wrong frame\ngot:  % X\nwant: % X", got, want)
+	}
+
+	if err := c.Write([]servo.Pulse{{Pin: 256, Width: time.Millisecond}}); err == nil {
+		t.Error("pin out of range was sent")
+	}
+}
+
+func TestServe(t *testing.T) {
+	in := new(bytes.Buffer)
+	c := New(in)
+	c.Write([]servo.Pulse{{Pin: 4, Width: time.Millisecond}})
+	stale := append([]byte(nil), in.Bytes()...)
+	c.Write([]servo.Pulse{{Pin: 4, Width: 2 * time.Millisecond}})
+	in.Write(stale)
+	c.Close()
+
+	b := new(recordBackend)
+	if err := Serve(in, b); err != nil {
+		t.Fatal(err)
+	}
+
+	got := fmt.Sprint(b.writes)
+	if want := "[[4=1ms] [4=2ms] [4=0s]]"; got != want {
+		t.Errorf("wrong pulses, got: %v, want: %v", got, want)
+	}
+}
+
+func TestDial(t *testing.T) {
+	conn, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Skip(err)
+	}
+	defer conn.Close()
+
+	c, err := Dial("udp", conn.LocalAddr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Write([]servo.Pulse{{Pin: 4, Width: time.Millisecond}}); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(time.Second))
+	n, _, err := conn.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, pulses, err := readFrame(bytes.NewReader(buf[:n]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(pulses); got != "[4=1ms]" {
+		t.Errorf("wrong datagram, got: %v, want: %v", got, "[4=1ms]")
+	}
+}
@@ -0,0 +1,108 @@
+package servo
+
+import (
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Noise is a smooth random Motion based on Perlin noise. Layer it on an idle
+// character to make it look alive, or on a gimbal to add handheld-camera-style
+// imperfection.
+type Noise struct {
+	// Amplitude is the maximum offset in degrees.
+	Amplitude float64
+	// Frequency is the approximate number of changes of direction per second.
+	Frequency float64
+
+	gradients [256]float64
+}
+
+// NewNoise creates a Noise motion. The same seed always generates the same
+// motion.
+func NewNoise(amplitude, frequency float64, seed int64) *Noise {
+	n := &Noise{
+		Amplitude: amplitude,
+		Frequency: frequency,
+	}
+
+	r := rand.New(rand.NewSource(seed))
+	for i := range n.gradients {
+		n.gradients[i] = r.Float64()*2 - 1
+	}
+
+	return n
+}
+
+// Offset implements the Motion interface.
+func (n *Noise) Offset(t time.Duration) float64 {
+	return n.Amplitude * n.at(t.Seconds()*n.Frequency)
+}
+
+// SetIdleNoise makes the servo move randomly and smoothly around its position
+// while it is idle, so an animatronic character does not look dead when it
+// stands still. amplitude is the maximum offset in degrees, and frequency the
+// approximate number of changes of direction per second, as for a Noise. The
+// noise fades out at the speed of the servo when it starts moving, and fades
+// back in when it stops. An amplitude or frequency of 0 turns the noise off.
+//
+// Like a layer, the noise is only added to the pwm sent to pi-blaster, not to
+// the position reported by the servo.
+func (s *Servo) SetIdleNoise(amplitude, frequency float64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.idleNoise != nil {
+		s.removeLayer(s.idleNoise)
+		s.idleNoise = nil
+	}
+	if amplitude <= 0 || frequency <= 0 {
+		return
+	}
+	n := NewNoise(amplitude, frequency, time.Now().UnixNano())
+	s.idleNoise = s.addLayer(idleNoise{servo: s, noise: n})
+}
+
+// IdleNoise returns the amplitude and frequency of the noise added while the
+// servo is idle, or 0 if it is turned off. See SetIdleNoise().
+func (s *Servo) IdleNoise() (amplitude, frequency float64) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	if s.idleNoise == nil {
+		return 0, 0
+	}
+	n := s.idleNoise.motion.(idleNoise).noise
+	return n.Amplitude, n.Frequency
+}
+
+// idleNoise is a Noise that is only generated while its servo is idle.
+type idleNoise struct {
+	servo *Servo
+	noise *Noise
+}
+
+// Offset implements the Motion interface. It is called by the manager with the
+// servo locked, so it reads the snapshot of the servo.
+func (n idleNoise) Offset(t time.Duration) float64 {
+	if !n.servo.isIdle() {
+		return 0
+	}
+	return n.noise.Offset(t)
+}
+
+// at returns the 1D Perlin noise at x, from -1 to 1.
+func (n *Noise) at(x float64) float64 {
+	x0 := math.Floor(x)
+	f := x - x0
+	i := int(x0) & 0xff
+
+	g0 := n.gradients[i] * f
+	g1 := n.gradients[(i+1)&0xff] * (f - 1)
+
+	// Smootherstep fade.
+	u := f * f * f * (f*(f*6-15) + 10)
+
+	// The 1D Perlin noise lies within -0.5 to 0.5.
+	return clamp(2*(g0+u*(g1-g0)), -1, 1)
+}
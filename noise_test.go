@@ -0,0 +1,106 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNoise(t *testing.T) {
+	a := NewNoise(5, 2, 1)
+	b := NewNoise(5, 2, 1)
+	c := NewNoise(5, 2, 2)
+
+	same, moved := true, false
+	for i := 0; i < 1000; i++ {
+		d := time.Duration(i) * 7 * time.Millisecond
+		o := a.Offset(d)
+		if o < -5 || o > 5 {
+			t.Fatalf("offset out of amplitude at %v: %.2f", d, o)
+		}
+		if o != b.Offset(d) {
+			t.Fatalf("same seed generated different noise at %v", d)
+		}
+		if o != c.Offset(d) {
+			same = false
+		}
+		if o != 0 {
+			moved = true
+		}
+	}
+	if same {
+		t.Error("different seeds generated the same noise")
+	}
+	if !moved {
+		t.Error("noise did not move")
+	}
+}
+
+func TestServo_SetIdleNoise(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetPosition(90)
+	s.Wait()
+
+	s.SetIdleNoise(5, 4)
+	s.SetIdleNoise(5, 4)
+	if a, f := s.IdleNoise(); a != 5 || f != 4 {
+		t.Errorf("wrong noise, got: %.2f, %.2f, want: 5.00, 4.00", a, f)
+	}
+	s.lock.RLock()
+	layers := len(s.layers)
+	s.lock.RUnlock()
+	if layers != 1 {
+		t.Errorf("noise was added twice, got %d layers", layers)
+	}
+
+	low := pwm(remap(85, 0, 180, s.MinPulse, s.MaxPulse))
+	high := pwm(remap(95, 0, 180, s.MinPulse, s.MaxPulse))
+	first, _ := s.LastPWM()
+	moved := false
+	for i := 0; i < 50; i++ {
+		time.Sleep(10 * time.Millisecond)
+		got, _ := s.LastPWM()
+		if pwm(got) < low || pwm(got) > high {
+			t.Fatalf("noise out of amplitude, got: %.4f, want: %.4f to %.4f", got, low, high)
+		}
+		if got != first {
+			moved = true
+		}
+	}
+	if !moved {
+		t.Error("idle servo did not move")
+	}
+	if s.Position() != 90 {
+		t.Errorf("noise changed the position, got: %.2f, want: 90.00", s.Position())
+	}
+
+	s.SetIdleNoise(0, 4)
+	time.Sleep(100 * time.Millisecond)
+	if got, _ := s.LastPWM(); pwm(got) != pwm(remap(90, 0, 180, s.MinPulse, s.MaxPulse)) {
+		t.Errorf("noise was not turned off, got: %.4f", got)
+	}
+}
+
+func TestIdleNoise_moving(t *testing.T) {
+	s := New(99)
+	n := idleNoise{servo: s, noise: NewNoise(5, 4, 1)}
+
+	s.lock.Lock()
+	s.idle = false
+	s.publish()
+	s.lock.Unlock()
+
+	for i := 0; i < 100; i++ {
+		if o := n.Offset(time.Duration(i) * 13 * time.Millisecond); o != 0 {
+			t.Fatalf("noise generated while moving, got: %.2f", o)
+		}
+	}
+}
@@ -0,0 +1,130 @@
+package servo
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// splineSamples is the number of samples per segment of a spline used to find
+// its fastest speed.
+const splineSamples = 64
+
+// Waypoint is a point of a path followed by Servo.FollowPath().
+type Waypoint struct {
+	// At is the time of the waypoint from the start of the path.
+	At time.Duration
+	// Angle is the angle of the servo at the waypoint, adjusted for its
+	// Flags.
+	Angle float64
+}
+
+// FollowPath moves the servo through the waypoints along a smooth curve, a
+// Catmull-Rom spline, without stopping at each waypoint as chaining MoveTo()
+// and Wait() does. The path starts at the current position of the servo and
+// ends at rest at the last waypoint. The waypoints are sorted by time, and
+// the ones at or before the start of the path are skipped. If there are
+// waypoints at the same time, the last one is kept.
+//
+// If reaching the waypoints in time would exceed the speed of the servo, the
+// whole path is slowed down. Changes of the speed while following the path,
+// e.g. by SetSpeed() or SoftStop(), slow down or speed up the path as well.
+// The returned Move ends when the servo reaches the last waypoint.
+func (s *Servo) FollowPath(points []Waypoint, opts ...MoveOption) *Move {
+	times, raws := s.waypoints(points)
+	if len(times) == 0 {
+		return s.moveToWith(s.adjust(s.snapshot().target), nil, opts...)
+	}
+
+	opts = append(opts, func(m *Move) {
+		m.planner = func(from, speed float64) profile {
+			return planSpline(from, times, raws, speed)
+		}
+	})
+	return s.moveToWith(s.adjust(raws[len(raws)-1]), nil, opts...)
+}
+
+// waypoints returns the times in seconds and the raw positions of the sorted
+// waypoints, clamped to the travel limits of the servo.
+func (s *Servo) waypoints(points []Waypoint) (times, raws []float64) {
+	sorted := make([]Waypoint, len(points))
+	copy(sorted, points)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].At < sorted[j].At
+	})
+
+	for _, p := range sorted {
+		if p.At <= 0 {
+			continue
+		}
+		raw := s.limit(s.raw(p.Angle))
+		if n := len(times); n > 0 && times[n-1] == p.At.Seconds() {
+			raws[n-1] = raw
+			continue
+		}
+		times = append(times, p.At.Seconds())
+		raws = append(raws, raw)
+	}
+	return times, raws
+}
+
+// spline is a cubic Hermite spline through points at times, with Catmull-Rom
+// tangents, starting and ending at rest. Its times are stretched by scale.
+type spline struct {
+	times, points, tangents []float64
+	scale                   float64
+}
+
+// planSpline plans a spline from the raw position from through the raw points
+// at times in seconds, slowed down so it never exceeds speed.
+func planSpline(from float64, times, points []float64, speed float64) spline {
+	sp := spline{
+		times:  append([]float64{0}, times...),
+		points: append([]float64{from}, points...),
+		scale:  1,
+	}
+	n := len(sp.points)
+	sp.tangents = make([]float64, n)
+	for i := 1; i < n-1; i++ {
+		sp.tangents[i] = (sp.points[i+1] - sp.points[i-1]) / (sp.times[i+1] - sp.times[i-1])
+	}
+
+	var fastest float64
+	for i := 0; i < n-1; i++ {
+		dt := (sp.times[i+1] - sp.times[i]) / splineSamples
+		last := sp.points[i]
+		for j := 1; j <= splineSamples; j++ {
+			p := sp.at(sp.times[i] + float64(j)*dt)
+			fastest = math.Max(fastest, math.Abs(p-last)/dt)
+			last = p
+		}
+	}
+	if speed > 0 && fastest > speed {
+		sp.scale = fastest / speed
+	}
+	return sp
+}
+
+func (sp spline) duration() float64 {
+	return sp.times[len(sp.times)-1] * sp.scale
+}
+
+func (sp spline) at(t float64) float64 {
+	t /= sp.scale
+	n := len(sp.times)
+	if t >= sp.times[n-1] {
+		return sp.points[n-1]
+	}
+	if t <= 0 {
+		return sp.points[0]
+	}
+
+	i := sort.SearchFloat64s(sp.times, t) - 1
+	h := sp.times[i+1] - sp.times[i]
+	u := (t - sp.times[i]) / h
+	u2, u3 := u*u, u*u*u
+	return (2*u3-3*u2+1)*sp.points[i] +
+		(u3-2*u2+u)*h*sp.tangents[i] +
+		(-2*u3+3*u2)*sp.points[i+1] +
+		(u3-u2)*h*sp.tangents[i+1]
+}
@@ -0,0 +1,100 @@
+// +build !live
+
+package servo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPlanSpline(t *testing.T) {
+	sp := planSpline(0, []float64{0.5, 1, 2}, []float64{45, 90, 60}, 1000)
+	tests := []struct{ t, want float64 }{
+		{0, 0},
+		{0.5, 45},
+		{1, 90},
+		{2, 60},
+		{3, 60},
+	}
+	for _, tt := range tests {
+		if got := sp.at(tt.t); math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("wrong position at %.1fs, got: %.2f, want: %.2f", tt.t, got, tt.want)
+		}
+	}
+	if d := sp.duration(); d != 2 {
+		t.Errorf("wrong duration, got: %.2fs, want: 2.00s", d)
+	}
+
+	// The servo does not stop at the waypoints, but starts and ends at rest.
+	const dt = 1e-4
+	if v := (sp.at(0.5+dt) - sp.at(0.5-dt)) / (2 * dt); v < 1 {
+		t.Errorf("servo stops at a waypoint, got: %.2f°/s", v)
+	}
+	for _, at := range []float64{0, 2 - dt} {
+		if v := (sp.at(at+dt) - sp.at(at)) / dt; math.Abs(v) > 0.1 {
+			t.Errorf("servo not at rest at %.1fs, got: %.2f°/s", at, v)
+		}
+	}
+}
+
+func TestPlanSpline_slow(t *testing.T) {
+	sp := planSpline(0, []float64{0.1}, []float64{180}, 180)
+	if sp.scale <= 1 {
+		t.Fatalf("path was not slowed down, got scale: %.2f", sp.scale)
+	}
+	const dt = 0.001
+	for x := 0.0; x < sp.duration(); x += dt {
+		if v := math.Abs(sp.at(x+dt)-sp.at(x)) / dt; v > 180*1.01 {
+			t.Fatalf("speed exceeded at %.3fs, got: %.2f°/s", x, v)
+		}
+	}
+}
+
+func TestServo_waypoints(t *testing.T) {
+	s := New(99)
+	s.Flags = Centered
+	times, raws := s.waypoints([]Waypoint{
+		{At: time.Second, Angle: 0},
+		{At: 0, Angle: 45},
+		{At: 500 * time.Millisecond, Angle: -45},
+		{At: time.Second, Angle: 100},
+	})
+	if len(times) != 2 || times[0] != 0.5 || times[1] != 1 {
+		t.Errorf("wrong times, got: %v, want: [0.5 1]", times)
+	}
+	if len(raws) != 2 || raws[0] != 45 || raws[1] != 180 {
+		t.Errorf("wrong positions, got: %v, want: [45 180]", raws)
+	}
+}
+
+func TestServo_FollowPath(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// The path comes back to its start, so the move must not end when the
+	// servo passes by its target.
+	start := time.Now()
+	m := s.FollowPath([]Waypoint{
+		{At: 300 * time.Millisecond, Angle: 60},
+		{At: 600 * time.Millisecond, Angle: 0},
+	})
+	time.Sleep(300 * time.Millisecond)
+	if p := s.Position(); p < 50 {
+		t.Errorf("servo did not follow the path, got: %.2f, want: about 60.00", p)
+	}
+	if got := m.Result(); got != MoveFinished {
+		t.Errorf("wrong outcome, got: %v, want: %v", got, MoveFinished)
+	}
+	if elapsed, want := time.Since(start), 600*time.Millisecond; elapsed < want-30*time.Millisecond || elapsed > want+60*time.Millisecond {
+		t.Errorf("wrong travel time, got: %v, want: %v", elapsed, want)
+	}
+	if s.Position() != 0 {
+		t.Errorf("servo did not arrive, got: %.2f", s.Position())
+	}
+}
@@ -0,0 +1,57 @@
+// +build linux
+
+package pca9685
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+)
+
+// i2cSlave is the ioctl that sets the address of the I2C device.
+const i2cSlave = 0x0703
+
+// Open opens the PCA9685 board at the I2C address addr of the bus device
+// path (e.g. "/dev/i2c-1") and initializes it at the default frequency for
+// servos.
+func Open(path string, addr int) (*Controller, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), i2cSlave, uintptr(addr)); errno != 0 {
+		f.Close()
+		return nil, fmt.Errorf("pca9685: could not set address 0x%02x: %v", addr, errno)
+	}
+
+	c, err := New(f, Frequency)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Scan returns the addresses of the PCA9685 boards found on the I2C bus
+// device path (e.g. "/dev/i2c-1"). A board is found if it answers a read of
+// its MODE1 register.
+func Scan(path string) ([]int, error) {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return scan(func(addr int) bool {
+		if _, _, errno := syscall.Syscall(syscall.SYS_IOCTL, f.Fd(), i2cSlave, uintptr(addr)); errno != 0 {
+			return false
+		}
+		if _, err := f.Write([]byte{regMode1}); err != nil {
+			return false
+		}
+		_, err := f.Read(make([]byte, 1))
+		return err == nil
+	}), nil
+}
@@ -0,0 +1,15 @@
+// +build !linux
+
+package pca9685
+
+import "fmt"
+
+// Open is only supported on Linux.
+func Open(path string, addr int) (*Controller, error) {
+	return nil, fmt.Errorf("pca9685: I2C is only supported on linux")
+}
+
+// Scan is only supported on Linux.
+func Scan(path string) ([]int, error) {
+	return nil, fmt.Errorf("pca9685: I2C is only supported on linux")
+}
@@ -0,0 +1,239 @@
+// Package pca9685 drives servos with a PCA9685 16-channel PWM board over I2C.
+//
+// Set a Controller as the backend of the servo package, and use the channel
+// of the board as the pin of each servo:
+//
+//	pca, err := pca9685.Open("/dev/i2c-1", pca9685.Address)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	servo.SetBackend(pca)
+//	defer servo.Close()
+//
+//	s := servo.New(0) // channel 0 of the board
+//	if err := s.Connect(); err != nil {
+//		log.Fatal(err)
+//	}
+//	s.MoveTo(90).Wait()
+package pca9685
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+const (
+	// Address is the default I2C address of a PCA9685 board.
+	Address = 0x40
+	// Channels is the number of channels of a PCA9685 board.
+	Channels = 16
+	// Frequency is the default pwm frequency for servos, in Hz.
+	Frequency = 50
+
+	// minAddress and maxAddress are the range of the addresses a PCA9685
+	// board can be configured to.
+	minAddress = 0x40
+	maxAddress = 0x7f
+	// allCall is the default LED All Call address, to which every board
+	// answers.
+	allCall = 0x70
+
+	// oscillator is the frequency of the internal oscillator.
+	oscillator = 25e6
+	// steps is the resolution of a pwm period.
+	steps = 4096
+
+	regMode1    = 0x00
+	regLED0     = 0x06
+	regAllLED   = 0xfa
+	regPrescale = 0xfe
+
+	mode1Restart = 0x80
+	mode1AI      = 0x20
+	mode1Sleep   = 0x10
+
+	// fullOff is set in LEDn_OFF_H to turn a channel fully off.
+	fullOff = 0x10
+)
+
+// Controller is a PCA9685 board. It implements the servo.Backend and
+// servo.FrequencyBackend interfaces. Use pca9685.Open() or pca9685.New() for
+// correct initialization.
+type Controller struct {
+	w      io.Writer
+	period time.Duration
+	// widths are the pulses written to the channels, rewritten when the
+	// frequency changes.
+	widths [Channels]time.Duration
+	lock   *sync.Mutex
+}
+
+// New initializes a PCA9685 board at the given pwm frequency in Hz. Each
+// write to w must be a single I2C transaction to the board, e.g. a /dev/i2c-N
+// file after the I2C_SLAVE ioctl. Use pca9685.Open() on Linux.
+func New(w io.Writer, frequency float64) (*Controller, error) {
+	c := &Controller{
+		w:    w,
+		lock: new(sync.Mutex),
+	}
+	if err := c.setFrequency(frequency); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// setFrequency sets the prescale of the board for the pwm frequency in Hz.
+// It must be called with the controller locked.
+func (c *Controller) setFrequency(frequency float64) error {
+	prescale := math.Round(oscillator/(steps*frequency)) - 1
+	if prescale < 3 || prescale > 255 || math.IsNaN(prescale) {
+		return fmt.Errorf("pca9685: frequency %.1fHz is out of range: %w", frequency, servo.ErrOutOfRange)
+	}
+
+	// The prescale can only be set while the oscillator sleeps.
+	for _, cmd := range [][]byte{
+		{regMode1, mode1AI | mode1Sleep},
+		{regPrescale, byte(prescale)},
+		{regMode1, mode1AI},
+	} {
+		if _, err := c.w.Write(cmd); err != nil {
+			return fmt.Errorf("pca9685: %v", err)
+		}
+	}
+	// The oscillator needs 500µs to stabilize.
+	time.Sleep(500 * time.Microsecond)
+	if _, err := c.w.Write([]byte{regMode1, mode1Restart | mode1AI}); err != nil {
+		return fmt.Errorf("pca9685: %v", err)
+	}
+	c.period = time.Duration(float64(time.Second) * steps * (prescale + 1) / oscillator)
+
+	return nil
+}
+
+// Period returns the actual pwm period of the board.
+func (c *Controller) Period() time.Duration {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return c.period
+}
+
+// Frequency implements the servo.FrequencyBackend interface. It returns the
+// actual pwm frequency of the board, which can differ slightly from the
+// frequency set, since the prescale of the board is an integer.
+func (c *Controller) Frequency() float64 {
+	return float64(time.Second) / float64(c.Period())
+}
+
+// SetFrequency implements the servo.FrequencyBackend interface. It changes
+// the prescale of the board and rewrites the pulses of the channels for the
+// new period. The channels stop for about 500µs while the oscillator
+// restarts.
+func (c *Controller) SetFrequency(hz float64) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if err := c.setFrequency(hz); err != nil {
+		return err
+	}
+	for ch, width := range c.widths {
+		if width == 0 {
+			continue
+		}
+		if err := c.set(regLED0+4*byte(ch), c.off(width)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// off returns the count of the LEDn_OFF registers for the pulse width.
+func (c *Controller) off(width time.Duration) uint16 {
+	if width <= 0 {
+		return fullOff << 8
+	}
+	return uint16(math.Min(math.Round(float64(width)/float64(c.period)*steps), steps-1))
+}
+
+// Write implements the servo.Backend interface. The pin of each pulse is the
+// channel of the board.
+func (c *Controller) Write(pulses []servo.Pulse) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, p := range pulses {
+		if p.Pin < 0 || p.Pin >= Channels {
+			return fmt.Errorf("pca9685: channel %d is out of range: %w", p.Pin, servo.ErrOutOfRange)
+		}
+
+		if err := c.set(regLED0+4*byte(p.Pin), c.off(p.Width)); err != nil {
+			return err
+		}
+		c.widths[p.Pin] = p.Width
+	}
+
+	return nil
+}
+
+// Close implements the servo.Backend interface. It turns all the channels
+// off. If the writer of the board is an io.Closer, it is closed too.
+func (c *Controller) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	err := c.set(regAllLED, fullOff<<8)
+	c.widths = [Channels]time.Duration{}
+	if closer, ok := c.w.(io.Closer); ok {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// set writes the on count 0 and the off count of the channel at register reg.
+func (c *Controller) set(reg byte, off uint16) error {
+	if _, err := c.w.Write([]byte{reg, 0, 0, byte(off), byte(off >> 8)}); err != nil {
+		return fmt.Errorf("pca9685: %v", err)
+	}
+	return nil
+}
+
+// scan returns the addresses of the PCA9685 range for which probe succeeds.
+func scan(probe func(addr int) bool) []int {
+	var found []int
+	for addr := minAddress; addr <= maxAddress; addr++ {
+		if addr == allCall {
+			continue
+		}
+		if probe(addr) {
+			found = append(found, addr)
+		}
+	}
+	return found
+}
+
+// FreeChannels returns the channels of a board that are not used by any
+// connected servo.
+func FreeChannels() []int {
+	used := make(map[int]bool)
+	for _, s := range servo.Servos() {
+		used[s.Pin()] = true
+	}
+
+	var free []int
+	for ch := 0; ch < Channels; ch++ {
+		if !used[ch] {
+			free = append(free, ch)
+		}
+	}
+	return free
+}
@@ -0,0 +1,171 @@
+// +build !live
+
+package pca9685
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+// recorder records each write as a separate I2C transaction.
+type recorder struct {
+	writes [][]byte
+	closed bool
+}
+
+func (r *recorder) Write(p []byte) (int, error) {
+	r.writes = append(r.writes, append([]byte(nil), p...))
+	return len(p), nil
+}
+
+func (r *recorder) Close() error {
+	r.closed = true
+	return nil
+}
+
+func TestNew(t *testing.T) {
+	r := new(recorder)
+	c, err := New(r, Frequency)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]byte{
+		{regMode1, mode1AI | mode1Sleep},
+		{regPrescale, 121},
+		{regMode1, mode1AI},
+		{regMode1, mode1Restart | mode1AI},
+	}
+	if len(r.writes) != len(want) {
+		t.Fatalf("wrong init, got: %v, want: %v", r.writes, want)
+	}
+	for i := range want {
+		if !bytes.Equal(r.writes[i], want[i]) {
+			t.Errorf("wrong init write %d, got: %v, want: %v", i, r.writes[i], want[i])
+		}
+	}
+
+	if p := c.Period(); p < 19*time.Millisecond || p > 21*time.Millisecond {
+		t.Errorf("wrong period, got: %v", p)
+	}
+
+	if _, err := New(r, 5000); err == nil {
+		t.Error("out of range frequency did not fail")
+	}
+}
+
+func TestController_Write(t *testing.T) {
+	r := new(recorder)
+	c, err := New(r, Frequency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.writes = nil
+
+	// 1.5ms of a 20ms period is about 307 steps.
+	err = c.Write([]servo.Pulse{
+		{Pin: 0, Width: c.Period() * 3 / 40},
+		{Pin: 15, Width: 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := [][]byte{
+		{regLED0, 0, 0, 0x33, 0x01},
+		{regLED0 + 4*15, 0, 0, 0, fullOff},
+	}
+	for i := range want {
+		if !bytes.Equal(r.writes[i], want[i]) {
+			t.Errorf("wrong write %d, got: %v, want: %v", i, r.writes[i], want[i])
+		}
+	}
+
+	if err := c.Write([]servo.Pulse{{Pin: 16}}); !errors.Is(err, servo.ErrOutOfRange) {
+		t.Errorf("out of range channel, got: %v, want: %v", err, servo.ErrOutOfRange)
+	}
+
+	r.writes = nil
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !r.closed || !bytes.Equal(r.writes[0], []byte{regAllLED, 0, 0, 0, fullOff}) {
+		t.Errorf("board was not released, got: %v", r.writes)
+	}
+}
+
+func TestScan(t *testing.T) {
+	boards := map[int]bool{0x40: true, 0x41: true, 0x70: true}
+	got := scan(func(addr int) bool {
+		return boards[addr]
+	})
+
+	want := []int{0x40, 0x41}
+	if len(got) != len(want) || got[0] != want[0] || got[1] != want[1] {
+		t.Errorf("wrong boards, got: %x, want: %x", got, want)
+	}
+}
+
+func TestFreeChannels(t *testing.T) {
+	s := servo.New(3)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	free := FreeChannels()
+	if len(free) != Channels-1 {
+		t.Fatalf("wrong free channels, got: %v", free)
+	}
+	for _, ch := range free {
+		if ch == 3 {
+			t.Error("used channel reported as free")
+		}
+	}
+}
+
+func TestController_SetFrequency(t *testing.T) {
+	r := new(recorder)
+	c, err := New(r, Frequency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := c.Write([]servo.Pulse{{Pin: 3, Width: 1500 * time.Microsecond}}); err != nil {
+		t.Fatal(err)
+	}
+	r.writes = nil
+
+	if err := c.SetFrequency(300); err != nil {
+		t.Fatal(err)
+	}
+	// The prescale rounds the frequency to 305.2Hz.
+	if f := c.Frequency(); f < 300 || f > 310 {
+		t.Errorf("wrong frequency, got: %.1fHz, want: about 300.0Hz", f)
+	}
+
+	// The prescale is changed, and the pulse is rewritten for the new
+	// period, 1.5ms of about 3.28ms.
+	want := [][]byte{
+		{regMode1, mode1AI | mode1Sleep},
+		{regPrescale, 19},
+		{regMode1, mode1AI},
+		{regMode1, mode1Restart | mode1AI},
+		{regLED0 + 4*3, 0, 0, 0x53, 0x07},
+	}
+	if len(r.writes) != len(want) {
+		t.Fatalf("wrong writes, got: %v, want: %v", r.writes, want)
+	}
+	for i := range want {
+		if !bytes.Equal(r.writes[i], want[i]) {
+			t.Errorf("wrong write %d, got: %v, want: %v", i, r.writes[i], want[i])
+		}
+	}
+
+	if err := c.SetFrequency(5000); !errors.Is(err, servo.ErrOutOfRange) {
+		t.Errorf("out of range frequency, got: %v, want: %v", err, servo.ErrOutOfRange)
+	}
+}
@@ -0,0 +1,262 @@
+package servo
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+const (
+	// piBlasterMaxLine is the maximum length of a line sent to pi-blaster.
+	// Longer lines risk being truncated by its line buffer.
+	piBlasterMaxLine = 128
+	// piBlasterMaxEntry is the maximum length of a "PIN=PWM" pair sent to
+	// pi-blaster, including its separator, e.g. " 999=1.000000".
+	piBlasterMaxEntry = 13
+	// piBlasterPipe is the default named pipe of pi-blaster.
+	piBlasterPipe = "/dev/pi-blaster"
+	// pipeEnv is the environment variable that overrides the path of the
+	// pipe of pi-blaster.
+	pipeEnv = "PI_BLASTER_PIPE"
+)
+
+// writeTimeout is the timeout in nanoseconds of a write to the pipe of
+// pi-blaster. It is accessed atomically.
+var writeTimeout = int64(defaultWriteTimeout)
+
+// defaultWriteTimeout is the default timeout of a write to pi-blaster.
+const defaultWriteTimeout = 100 * time.Millisecond
+
+// SetWriteTimeout sets how long a write to the pipe of pi-blaster can block
+// when pi-blaster is not reading it (default: 100ms). The frame is dropped
+// after the timeout, so the manager never freezes. See servo.DroppedFrames().
+func SetWriteTimeout(d time.Duration) {
+	atomic.StoreInt64(&writeTimeout, int64(d))
+}
+
+// piBlasterCycle is the pwm period in nanoseconds that pi-blaster runs at. It
+// is accessed atomically.
+var piBlasterCycle = int64(pulsePeriod)
+
+// SetPiBlasterFrequency sets the pwm frequency in Hz that the pi-blaster
+// daemon runs at (default: 100Hz, its cycle time of 10ms), e.g. if it was
+// built with another cycle time. The duty fractions written to pi-blaster are
+// computed from the pulse widths of the servos and this frequency, so the
+// calibrations keep their widths. Frequencies of 0 or less are ignored.
+func SetPiBlasterFrequency(hz float64) {
+	if hz <= 0 {
+		return
+	}
+	atomic.StoreInt64(&piBlasterCycle, int64(float64(time.Second)/hz))
+}
+
+// piBlasterPeriod returns the pwm period that pi-blaster runs at.
+func piBlasterPeriod() time.Duration {
+	return time.Duration(atomic.LoadInt64(&piBlasterCycle))
+}
+
+// reopenPipe is set to 1 when the pipe of pi-blaster is opened on every
+// write. It is accessed atomically.
+var reopenPipe int32
+
+// ReopenPipe makes every write to pi-blaster open and close its pipe, as
+// older versions of this package did, instead of keeping the pipe open
+// between writes (default: false). The pipe is reopened automatically when a
+// write fails either way.
+func ReopenPipe(always bool) {
+	var v int32
+	if always {
+		v = 1
+	}
+	atomic.StoreInt32(&reopenPipe, v)
+}
+
+// piBlaster is the Backend that writes to the pi-blaster daemon.
+type piBlaster struct {
+	// disabled is guarded by the lock once the manager is running.
+	disabled bool
+	// sink receives the data when pi-blaster is disabled (default:
+	// ioutil.Discard).
+	sink io.Writer
+	// maxFrame is the maximum length in bytes of a line sent to pi-blaster.
+	// If 0, lines are not split.
+	maxFrame int
+
+	// path is the named pipe of pi-blaster, and pipe is its open file, or
+	// nil if it must be opened on the next write.
+	path string
+	pipe *os.File
+	// lock guards pipe, since the pins are released by servo.Close() while
+	// the manager may still be writing.
+	lock *sync.Mutex
+}
+
+func newPiBlaster() *piBlaster {
+	path := os.Getenv(pipeEnv)
+	if path == "" {
+		path = piBlasterPipe
+	}
+
+	return &piBlaster{
+		sink:     ioutil.Discard,
+		maxFrame: piBlasterMaxLine,
+		path:     path,
+		lock:     new(sync.Mutex),
+	}
+}
+
+// SetPipePath sets the path of the named pipe of pi-blaster, e.g. where a
+// container mounts it (default: /dev/pi-blaster). The default can also be set
+// with the PI_BLASTER_PIPE environment variable, which is read before the
+// package looks for pi-blaster at startup.
+//
+// SetPipePath returns an error if nothing exists at path. Otherwise, the next
+// write opens the new pipe, even if pi-blaster was disabled because it was not
+// found running.
+func SetPipePath(path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return err
+	}
+
+	p := _blaster.pi
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	p.closePipe()
+	p.path = path
+	p.disabled = false
+
+	return nil
+}
+
+// available checks if pi-blaster can be written to. With the default pipe, it
+// checks that pi-blaster is running. Otherwise, it checks that the pipe
+// exists, since the daemon may run outside of the container.
+func (p *piBlaster) available() bool {
+	p.lock.Lock()
+	path := p.path
+	p.lock.Unlock()
+
+	if path == piBlasterPipe {
+		return hasBlaster()
+	}
+	_, err := os.Stat(path)
+	return err == nil
+}
+
+// isDisabled checks if the data is sent to the sink instead of pi-blaster.
+func (p *piBlaster) isDisabled() bool {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	return p.disabled
+}
+
+// MaxFrame implements the FrameBackend interface. It returns the number of
+// "PIN=PWM" pairs that fit in a line of maxFrame bytes (including the new
+// line), or 0 if the lines are not bounded.
+func (p *piBlaster) MaxFrame() int {
+	if p.maxFrame <= 0 {
+		return 0
+	}
+	if n := (p.maxFrame - 1) / piBlasterMaxEntry; n > 0 {
+		return n
+	}
+	return 1
+}
+
+// Write parses the pulses into "PIN=PWM PIN=PWM" format and writes them as a
+// single line. The manager keeps the line shorter than maxFrame bytes (see
+// MaxFrame).
+func (p *piBlaster) Write(pulses []Pulse) error {
+	s := new(strings.Builder)
+	period := float64(piBlasterPeriod())
+	for _, pulse := range pulses {
+		fmt.Fprintf(s, " %d=%.6f", pulse.Pin, float64(pulse.Width)/period)
+	}
+
+	debugf("flush:%s", s)
+	return p.write(s.String())
+}
+
+// Close releases all the pins and closes the pipe.
+func (p *piBlaster) Close() error {
+	err := p.write("*=0.0")
+
+	p.lock.Lock()
+	p.closePipe()
+	p.lock.Unlock()
+
+	return err
+}
+
+// write sends a string s to the designated io.Writer. The pipe of pi-blaster
+// is kept open between writes, unless ReopenPipe(true) was called. If a write
+// fails, the pipe is reopened and the write is retried once, e.g. after
+// pi-blaster was restarted. A write that timed out is not retried.
+func (p *piBlaster) write(s string) error {
+	line := s + "\n"
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	if p.disabled {
+		_, err := io.WriteString(p.sink, line)
+		return err
+	}
+
+	err := p.writePipe(line)
+	if err != nil && !errors.Is(err, ErrTimeout) {
+		p.closePipe()
+		err = p.writePipe(line)
+	}
+	if err != nil || atomic.LoadInt32(&reopenPipe) != 0 {
+		p.closePipe()
+	}
+
+	return err
+}
+
+// writePipe writes the line to the pipe, opening it if needed. It must be
+// called with the lock held.
+//
+// The pipe is opened in non-blocking mode, so the open fails instead of
+// blocking when pi-blaster is not reading the pipe, and a write blocks at most
+// for the write timeout. A line is shorter than the atomic write size of a
+// pipe, so it is either written whole or not at all.
+func (p *piBlaster) writePipe(line string) error {
+	if p.pipe == nil {
+		f, err := os.OpenFile(p.path, os.O_WRONLY|syscall.O_NONBLOCK, os.ModeNamedPipe)
+		if err != nil {
+			return err
+		}
+		p.pipe = f
+	}
+
+	// Regular files, e.g. in tests, do not support deadlines and never
+	// block.
+	p.pipe.SetWriteDeadline(time.Now().Add(time.Duration(atomic.LoadInt64(&writeTimeout))))
+
+	if _, err := io.WriteString(p.pipe, line); err != nil {
+		if os.IsTimeout(err) {
+			return fmt.Errorf("%w: pi-blaster is not reading %s: %v", ErrTimeout, p.path, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// closePipe closes the pipe, if open. It must be called with the lock held.
+func (p *piBlaster) closePipe() {
+	if p.pipe != nil {
+		p.pipe.Close()
+		p.pipe = nil
+	}
+}
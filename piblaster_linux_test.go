@@ -0,0 +1,89 @@
+// +build linux,!live
+
+package servo
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+// useFIFO points p to a named pipe in a temporary directory.
+func useFIFO(t *testing.T, p *piBlaster) string {
+	path := usePipe(t, p)
+	os.Remove(path)
+	if err := syscall.Mkfifo(path, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestPiBlaster_noReader(t *testing.T) {
+	p := newPiBlaster()
+	useFIFO(t, p)
+
+	done := make(chan error, 1)
+	go func() { done <- p.write("17=0.15") }()
+	select {
+	case err := <-done:
+		if err == nil {
+			t.Error("wrote to a pipe without reader")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("write blocked on a pipe without reader")
+	}
+}
+
+func TestPiBlaster_timeout(t *testing.T) {
+	SetWriteTimeout(10 * time.Millisecond)
+	defer SetWriteTimeout(defaultWriteTimeout)
+
+	p := newPiBlaster()
+	path := useFIFO(t, p)
+	// A reader that never reads.
+	r, err := os.OpenFile(path, os.O_RDONLY|syscall.O_NONBLOCK, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer r.Close()
+
+	line := strings.Repeat("x", piBlasterMaxLine-1)
+	start := time.Now()
+	for i := 0; ; i++ {
+		err := p.write(line)
+		if err == nil {
+			if i > 10000 {
+				t.Fatal("the pipe never filled up")
+			}
+			continue
+		}
+		if !errors.Is(err, ErrTimeout) {
+			t.Fatalf("wrong error, got: %v", err)
+		}
+		break
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("write blocked for %v", elapsed)
+	}
+}
+
+func TestDroppedFrames(t *testing.T) {
+	useBlaster(t)
+	SetBackend(brokenBackend{})
+	defer SetBackend(nil)
+	Rate(time.Millisecond)
+	OnWriteError(func(error) {})
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	s.MoveTo(10).Wait()
+
+	if DroppedFrames() == 0 {
+		t.Error("dropped frames were not counted")
+	}
+}
@@ -0,0 +1,166 @@
+// +build !live
+
+package servo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// usePipe points p to a file in a temporary directory instead of the pipe of
+// pi-blaster, and returns the path of the file.
+func usePipe(t *testing.T, p *piBlaster) string {
+	dir, err := ioutil.TempDir("", "piblaster")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "pi-blaster")
+	if err := ioutil.WriteFile(path, nil, 0644); err != nil {
+		t.Fatal(err)
+	}
+	p.path = path
+	return path
+}
+
+func TestPiBlaster_keepOpen(t *testing.T) {
+	p := newPiBlaster()
+	path := usePipe(t, p)
+
+	if err := p.write("17=0.15"); err != nil {
+		t.Fatal(err)
+	}
+	f := p.pipe
+	if f == nil {
+		t.Fatal("pipe was not kept open")
+	}
+	if err := p.write("17=0.16"); err != nil {
+		t.Fatal(err)
+	}
+	if p.pipe != f {
+		t.Error("pipe was reopened")
+	}
+
+	// A broken pipe is reopened.
+	f.Close()
+	if err := p.write("17=0.17"); err != nil {
+		t.Fatalf("pipe was not reopened: %v", err)
+	}
+
+	if err := p.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if p.pipe != nil {
+		t.Error("pipe was not closed")
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The reopened file is written from its start.
+	if got, want := string(data), "17=0.17\n*=0.0\n"; !strings.HasPrefix(got, want) {
+		t.Errorf("wrong data\ngot:  %q\nwant: %q", got, want)
+	}
+}
+
+func TestReopenPipe(t *testing.T) {
+	ReopenPipe(true)
+	defer ReopenPipe(false)
+
+	p := newPiBlaster()
+	usePipe(t, p)
+
+	if err := p.write("17=0.15"); err != nil {
+		t.Fatal(err)
+	}
+	if p.pipe != nil {
+		t.Error("pipe was kept open")
+	}
+
+	p.path = filepath.Join(filepath.Dir(p.path), "missing")
+	if err := p.write("17=0.15"); err == nil {
+		t.Error("wrote to a missing pipe")
+	}
+}
+
+func TestSetPipePath(t *testing.T) {
+	b := useBlaster(t)
+	path := usePipe(t, newPiBlaster())
+
+	if err := SetPipePath(filepath.Join(filepath.Dir(path), "missing")); err == nil {
+		t.Error("set a missing pipe")
+	}
+	if err := SetPipePath(path); err != nil {
+		t.Fatal(err)
+	}
+	if b.pi.isDisabled() {
+		t.Error("pi-blaster is still disabled")
+	}
+
+	if err := b.pi.write("17=0.15"); err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), "17=0.15\n"; got != want {
+		t.Errorf("wrong data, got: %q, want: %q", got, want)
+	}
+
+	os.Setenv(pipeEnv, path)
+	defer os.Unsetenv(pipeEnv)
+	if p := newPiBlaster(); p.path != path || !p.available() {
+		t.Errorf("%s was not used, got: %q", pipeEnv, p.path)
+	}
+}
+
+func TestSetPipePath_available(t *testing.T) {
+	b := useBlaster(t)
+	path := usePipe(t, newPiBlaster())
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 100; i++ {
+			b.pi.available()
+		}
+	}()
+	for i := 0; i < 100; i++ {
+		if err := SetPipePath(path); err != nil {
+			t.Fatal(err)
+		}
+	}
+	<-done
+	if !b.pi.available() {
+		t.Errorf("%s is not available", path)
+	}
+}
+
+func TestSetPiBlasterFrequency(t *testing.T) {
+	defer SetPiBlasterFrequency(100)
+
+	p := newPiBlaster()
+	path := usePipe(t, p)
+	defer p.Close()
+
+	SetPiBlasterFrequency(50)
+	SetPiBlasterFrequency(0)
+	if err := p.Write([]Pulse{{Pin: 17, Width: 1500 * time.Microsecond}}); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := string(data), " 17=0.075000\n"; got != want {
+		t.Errorf("wrong duty\ngot:  %q\nwant: %q", got, want)
+	}
+}
@@ -0,0 +1,110 @@
+package servo
+
+import "math"
+
+// profile is the planned raw position of a move over time, for the moves that
+// are not interpolated update by update, e.g. S-curves and eased moves.
+type profile interface {
+	// at returns the raw position t seconds after the start of the move.
+	at(t float64) float64
+	// duration returns how long the move takes in seconds.
+	duration() float64
+}
+
+// planned checks if the move m of the servo follows a planned profile. It
+// must be called with the servo locked.
+func (s *Servo) planned(m *Move) bool {
+	return m != nil && (m.planner != nil || m.ease != nil || m.arrive > 0 || s.jerkOf(m) > 0)
+}
+
+// playing checks if the move m follows a profile that has not ended yet, so
+// it does not end when it passes by its target. It must be called by the
+// manager.
+func (m *Move) playing() bool {
+	return m != nil && m.path != nil && m.elapsed < m.path.duration()
+}
+
+// plan returns the profile of the move m of the servo from its position to
+// its target at speed. A path takes precedence over an easing curve, and an
+// easing curve over the jerk limit. The profile is then slowed down to take at
+// least the arrival time of the move, see arriveIn().
+func (s *Servo) plan(m *Move, speed float64) profile {
+	var p profile
+	if m.planner != nil {
+		p = m.planner(s.position, speed)
+	} else {
+		p = s.shape(m, s.position, s.target, speed)
+	}
+	if d := p.duration(); d > 0 && d < m.arrive {
+		return stretched{p, m.arrive / d}
+	}
+	return p
+}
+
+// shape plans the move m of the servo from the raw position from to to at
+// speed, with its easing curve or its jerk limit, ignoring its path and its
+// arrival time. It must be called with the servo locked.
+func (s *Servo) shape(m *Move, from, to, speed float64) profile {
+	if m.ease != nil {
+		return planEasing(from, to, speed, m.ease)
+	}
+	if jerk := s.jerkOf(m); jerk > 0 {
+		return planSCurve(from, to, speed, s.accel, s.decel, jerk)
+	}
+	return s.natural(from, to, speed)
+}
+
+// natural plans the move of the servo from the raw position from to to at
+// speed, as it would move with its acceleration and jerk limits. It must be
+// called with the servo locked.
+func (s *Servo) natural(from, to, speed float64) scurve {
+	jerk := s.jerk
+	if jerk == 0 {
+		jerk = math.Inf(1)
+	}
+	return planSCurve(from, to, speed, s.accel, s.decel, jerk)
+}
+
+// stretched plays a profile slower by scale.
+type stretched struct {
+	profile
+	scale float64
+}
+
+func (st stretched) at(t float64) float64 { return st.profile.at(t / st.scale) }
+
+func (st stretched) duration() float64 { return st.profile.duration() * st.scale }
+
+// arriveIn makes the move take at least d seconds, slowing down its profile
+// proportionally, e.g. its easing curve, so its speed, acceleration and jerk
+// limits still hold.
+func arriveIn(d float64) MoveOption {
+	return func(m *Move) {
+		m.arrive = d
+	}
+}
+
+// follow returns the raw position and the velocity of the servo dt seconds
+// after its last update along the profile of the move m, which is planned on
+// the first update with a speed. The profile is played faster or slower if the
+// speed of the servo changed since. It must be called by the manager, with the
+// servo locked.
+func (s *Servo) follow(m *Move, dt float64) (p, v float64) {
+	speed := s.speed()
+	if m.path == nil {
+		if speed == 0 {
+			return s.position, 0
+		}
+		m.path = s.plan(m, speed)
+		m.speed = speed
+	}
+	if m.speed > 0 {
+		m.elapsed += dt * speed / m.speed
+	}
+
+	p = s.limit(m.path.at(m.elapsed))
+	if dt > 0 {
+		v = (p - s.position) / dt
+	}
+	return p, v
+}
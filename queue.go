@@ -0,0 +1,108 @@
+package servo
+
+import "fmt"
+
+// Enqueue adds the targets to the move queue of the servo. The servo moves to
+// each target in order, at its current speed, once the previous move
+// finished. The pending moves can be inspected and revised with Queue(),
+// ClearQueue(), InsertQueued() and RemoveQueued(), e.g. to drop the remaining
+// steps of a sequence after a sensor event. Stop() drops all the pending
+// moves.
+//
+// The returned Waiter waits until the queue is empty and the servo finished
+// moving.
+func (s *Servo) Enqueue(targets ...float64) (wait Waiter) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	for _, t := range targets {
+		s.queue = append(s.queue, s.raw(t))
+	}
+
+	return s.startQueue()
+}
+
+// startQueue starts the queue runner if it is not running, and returns a
+// Waiter for it. It must be called with the servo locked.
+func (s *Servo) startQueue() closed {
+	if s.queueDone == nil {
+		s.queueDone = make(chan struct{})
+		go s.runQueue(s.queueDone)
+	}
+	return closed(s.queueDone)
+}
+
+// runQueue moves the servo to each target of the queue until it is empty.
+func (s *Servo) runQueue(done chan struct{}) {
+	defer close(done)
+
+	s.Wait()
+	for {
+		s.lock.Lock()
+		if len(s.queue) == 0 || _blaster.isClosed() {
+			s.queue = nil
+			s.queueDone = nil
+			s.lock.Unlock()
+			return
+		}
+		target := s.adjust(s.queue[0])
+		s.queue = s.queue[1:]
+		s.lock.Unlock()
+
+		s.MoveTo(target).Wait()
+	}
+}
+
+// Queue returns the targets of the pending moves of the queue, in order. The
+// current move is not included.
+func (s *Servo) Queue() []float64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	targets := make([]float64, len(s.queue))
+	for i, t := range s.queue {
+		targets[i] = s.adjust(t)
+	}
+	return targets
+}
+
+// ClearQueue drops all the pending moves of the queue. The current move is
+// not affected.
+func (s *Servo) ClearQueue() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.queue = nil
+}
+
+// InsertQueued inserts target in the queue at index i, from 0 to the length
+// of the queue. It returns ErrOutOfRange for any other index.
+func (s *Servo) InsertQueued(i int, target float64) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if i < 0 || i > len(s.queue) {
+		return s.wrap(fmt.Errorf("%w: queue index %d, length %d", ErrOutOfRange, i, len(s.queue)))
+	}
+
+	s.queue = append(s.queue, 0)
+	copy(s.queue[i+1:], s.queue[i:])
+	s.queue[i] = s.raw(target)
+	s.startQueue()
+
+	return nil
+}
+
+// RemoveQueued removes the pending move at index i of the queue. It returns
+// ErrOutOfRange if there is no move at i.
+func (s *Servo) RemoveQueued(i int) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if i < 0 || i >= len(s.queue) {
+		return s.wrap(fmt.Errorf("%w: queue index %d, length %d", ErrOutOfRange, i, len(s.queue)))
+	}
+	s.queue = append(s.queue[:i], s.queue[i+1:]...)
+
+	return nil
+}
@@ -0,0 +1,64 @@
+// +build !live
+
+package servo
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestServo_Queue(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	sub := Subscribe(64, EventFinish, s)
+	defer sub.Close()
+
+	s.MoveTo(10)
+	w := s.Enqueue(20, 30, 40)
+	if got, want := fmt.Sprint(s.Queue()), "[20 30 40]"; got != want {
+		t.Errorf("wrong queue, got: %v, want: %v", got, want)
+	}
+
+	if err := s.RemoveQueued(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.InsertQueued(0, 15); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.RemoveQueued(3); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("removed pending move out of range, got: %v, want: %v", err, ErrOutOfRange)
+	}
+	if got, want := fmt.Sprint(s.Queue()), "[15 20 40]"; got != want {
+		t.Errorf("wrong revised queue, got: %v, want: %v", got, want)
+	}
+
+	w.Wait()
+	sub.Close()
+	var got []float64
+	for e := range sub.C {
+		got = append(got, e.Position)
+	}
+	if got, want := fmt.Sprint(got), "[10 15 20 40]"; got != want {
+		t.Errorf("wrong moves, got: %v, want: %v", got, want)
+	}
+
+	s.MoveTo(100)
+	s.Enqueue(180)
+	s.ClearQueue()
+	if len(s.Queue()) != 0 {
+		t.Errorf("queue was not cleared, got: %v", s.Queue())
+	}
+	s.Wait()
+	if s.Position() != 100 {
+		t.Errorf("current move was affected by ClearQueue, got: %.2f, want: %.2f", s.Position(), 100.0)
+	}
+}
@@ -0,0 +1,81 @@
+// +build !live
+
+package servo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestServo_Remap(t *testing.T) {
+	b := useBlaster(t)
+	w := new(syncBuffer)
+	b.pi.sink = w
+
+	s, other := New(17), New(18)
+	for _, s := range []*Servo{s, other} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+	}
+	s.SetPosition(90)
+	s.Wait()
+
+	if err := s.Remap(18); err == nil {
+		t.Error("remapped to a used pin")
+	}
+	if err := s.Remap(22); err != nil {
+		t.Fatal(err)
+	}
+	if s.Pin() != 22 {
+		t.Errorf("pin was not changed, got: %d, want: %d", s.Pin(), 22)
+	}
+	if s.Position() != 90 {
+		t.Errorf("position was not kept, got: %.2f, want: %.2f", s.Position(), 90.0)
+	}
+
+	time.Sleep(100 * time.Millisecond)
+	if !strings.Contains(w.String(), "17=0.000000 22=0.150000\n") {
+		t.Errorf("pins were not swapped in the same flush:\n%s", w)
+	}
+
+	connected := _blaster.connected()
+	if len(connected) != 2 || connected[1] != s {
+		t.Errorf("servo was not registered on the new pin: %v", connected)
+	}
+}
+
+func TestServo_Remap_notDriven(t *testing.T) {
+	for name, release := range map[string]func(s *Servo){
+		"fault":    func(s *Servo) { s.SetFault(errors.New("stall")) },
+		"detached": func(s *Servo) { s.Detach() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			b := useBlaster(t)
+			w := new(syncBuffer)
+			b.pi.sink = w
+			Rate(time.Millisecond)
+
+			s := New(17)
+			if err := s.Connect(); err != nil {
+				t.Fatal(err)
+			}
+			defer s.Close()
+			s.SetPosition(90)
+			s.Wait()
+			release(s)
+			time.Sleep(20 * time.Millisecond)
+
+			if err := s.Remap(22); err != nil {
+				t.Fatal(err)
+			}
+			time.Sleep(20 * time.Millisecond)
+			if got := w.String(); strings.Contains(got, "22=0.15") {
+				t.Errorf("new pin was driven:\n%s", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,172 @@
+package servo
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+)
+
+// config returns the current configuration of the servo.
+func (s *Servo) config() ServoConfig {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	var flags []string
+	for name, bits := range flagNames {
+		if s.Flags.is(bits) {
+			flags = append(flags, name)
+		}
+	}
+	sort.Strings(flags)
+
+	position := s.adjust(s.position)
+
+	return ServoConfig{
+		Name:              s.Name,
+		Pin:               s.Pin(),
+		Flags:             flags,
+		Tags:              s.Tags(),
+		MinPulse:          s.MinPulse,
+		MaxPulse:          s.MaxPulse,
+		Range:             s.Range,
+		CalibrationPoints: sortedPoints(s.points),
+		AllowUnsafePulse:  s.AllowUnsafePulse,
+		MinAngle:          s.MinAngle,
+		MaxAngle:          s.MaxAngle,
+		MaxErrors:         s.MaxErrors,
+		Speed:             s.speedFraction(),
+		MaxSpeed:          s.maxStep,
+		Acceleration:      s.accel,
+		Deceleration:      s.decel,
+		Jerk:              s.jerk,
+		Expo:              s.expo,
+		Position:          &position,
+	}
+}
+
+// apply sets the configuration to the servo, including its tags, except for
+// its pin. The configuration must be validated first, so the servo is not
+// left half changed.
+func (s *Servo) apply(sc ServoConfig) error {
+	f, err := sc.flags()
+	if err != nil {
+		return err
+	}
+	tags := make(tagSet, len(sc.Tags))
+	for _, t := range sc.Tags {
+		tags[t] = true
+	}
+
+	s.lock.Lock()
+	s.tags.Store(tags)
+	s.Flags = f
+	s.AllowUnsafePulse = sc.AllowUnsafePulse
+	s.MaxErrors = sc.MaxErrors
+	s.Range = sc.Range
+	s.MinAngle, s.MaxAngle = sc.MinAngle, sc.MaxAngle
+	err = s.calibrate(sc.calibration(), sc.CalibrationPoints)
+	s.lock.Unlock()
+	if err != nil {
+		return err
+	}
+	maxSpeed := sc.MaxSpeed
+	if maxSpeed == 0 {
+		maxSpeed = DefaultMaxSpeed
+	}
+	s.SetMaxSpeed(maxSpeed)
+	s.SetSpeed(sc.Speed)
+	s.SetAcceleration(sc.Acceleration, sc.Deceleration)
+	s.SetJerk(sc.Jerk)
+	s.SetExpo(sc.Expo)
+	if sc.Position != nil {
+		s.MoveTo(*sc.Position)
+	}
+
+	return nil
+}
+
+// Export writes the configuration of every connected servo, including its
+// calibration and current position, to w as a JSON document. The document
+// has the same format as the files read by servo.LoadConfig() and can be
+// restored with servo.Import().
+func Export(w io.Writer) error {
+	c := &Config{Version: SchemaVersion}
+	for _, s := range _blaster.connected() {
+		c.Servos = append(c.Servos, s.config())
+	}
+
+	e := json.NewEncoder(w)
+	e.SetIndent("", "  ")
+
+	return e.Encode(c)
+}
+
+// Import reads a JSON document written by servo.Export() from r and restores
+// it, migrating documents of an older servo.SchemaVersion. A connected servo
+// with the same name as a servo in the document is updated and moved to the
+// exported position at its configured speed. The other servos in the document
+// are created and connected. Import returns the restored servos in the order
+// of the document.
+//
+// The whole document is validated before any servo is changed, see
+// servo.ValidateConfig(). It returns the Problems of an invalid document, and
+// an error wrapping ErrPinClaimed if a new servo would take the pin of a
+// connected servo.
+func Import(r io.Reader) ([]*Servo, error) {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = migrate("import", data, configMigrations); err != nil {
+		return nil, err
+	}
+	c := new(Config)
+	if err := json.Unmarshal(data, c); err != nil {
+		return nil, err
+	}
+
+	if ps := validateConfig(c, func() bool { return true }); ps != nil {
+		return nil, fmt.Errorf("import: %w", ps)
+	}
+
+	connected := make(map[string]*Servo)
+	pins := make(map[int]*Servo)
+	for _, s := range _blaster.connected() {
+		connected[s.Name] = s
+		pins[s.Pin()] = s
+	}
+	for _, sc := range c.Servos {
+		if _, ok := connected[sc.Name]; ok {
+			continue
+		}
+		// The pins were validated.
+		pin, _ := sc.pin()
+		if other, ok := pins[pin]; ok {
+			return nil, fmt.Errorf("import %q: %w: gpio(%d) is used by %q", sc.Name, ErrPinClaimed, pin, other.Name)
+		}
+	}
+
+	servos := make([]*Servo, 0, len(c.Servos))
+	for _, sc := range c.Servos {
+		if s, ok := connected[sc.Name]; ok {
+			if err := s.apply(sc); err != nil {
+				return servos, fmt.Errorf("import %q: %w", sc.Name, err)
+			}
+			servos = append(servos, s)
+			continue
+		}
+
+		s, err := sc.New()
+		if err != nil {
+			return servos, fmt.Errorf("import %q: %w", sc.Name, err)
+		}
+		if err := s.Connect(); err != nil {
+			return servos, fmt.Errorf("import %q: %w", sc.Name, err)
+		}
+		servos = append(servos, s)
+	}
+
+	return servos, nil
+}
@@ -0,0 +1,143 @@
+// +build !live
+
+package servo
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestExportImport(t *testing.T) {
+	useBlaster(t)
+
+	jaw := New(17)
+	jaw.Name = "jaw"
+	jaw.Flags = Centered
+	jaw.MinPulse = 0.06
+	jaw.SetSpeed(0.5)
+	jaw.SetPosition(-30)
+	if err := jaw.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	neck := New(18)
+	neck.Name = "neck"
+	if err := neck.Connect(); err != nil {
+		t.Fatal(err)
+	}
+
+	buf := new(bytes.Buffer)
+	if err := Export(buf); err != nil {
+		t.Fatal(err)
+	}
+	doc := buf.String()
+
+	// Simulate a replacement rig with only the neck connected, but detuned.
+	jaw.Close()
+	neck.MinPulse = 0.1
+
+	servos, err := Import(bytes.NewBufferString(doc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(servos) != 2 {
+		t.Fatalf("imported servos, got: %d, want: %d", len(servos), 2)
+	}
+	if servos[1] != neck {
+		t.Error("connected servo was not reused")
+	}
+	if neck.MinPulse != 0.05 {
+		t.Errorf("neck calibration was not restored, got: %.2f, want: %.2f", neck.MinPulse, 0.05)
+	}
+
+	got := servos[0]
+	defer got.Close()
+	want := `servo "jaw" connected to gpio(17) [flags: ( Centered )]`
+	if got.String() != want {
+		t.Errorf("servo does not match\ngot:\n%v\nwant:\n%v", got, want)
+	}
+	if got.MinPulse != 0.06 || got.Position() != -30 || got.config().Speed != 0.5 {
+		t.Errorf("jaw was not restored, got: %+v", got.config())
+	}
+
+	buf.Reset()
+	if err := Export(buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != doc {
+		t.Errorf("documents do not match\ngot:\n%v\nwant:\n%v", buf, doc)
+	}
+}
+
+func TestExport_zeroMaxSpeed(t *testing.T) {
+	useBlaster(t)
+
+	s := New(17)
+	s.SetMaxSpeed(0)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if got := s.config().Speed; got != 0 {
+		t.Errorf("speed, got: %.2f, want: %.2f", got, 0.0)
+	}
+	if err := Export(new(bytes.Buffer)); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestImport_pinClaimed(t *testing.T) {
+	useBlaster(t)
+
+	jaw := New(17)
+	jaw.Name = "jaw"
+	if err := jaw.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	buf := new(bytes.Buffer)
+	if err := Export(buf); err != nil {
+		t.Fatal(err)
+	}
+	jaw.Close()
+
+	neck := New(17)
+	neck.Name = "neck"
+	if err := neck.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer neck.Close()
+
+	if _, err := Import(buf); !errors.Is(err, ErrPinClaimed) {
+		t.Errorf("import error, got: %v, want: %v", err, ErrPinClaimed)
+	}
+}
+
+func TestImport_connected(t *testing.T) {
+	useBlaster(t)
+
+	neck := New(18)
+	neck.Name = "neck"
+	if err := neck.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer neck.Close()
+
+	bad := `{"version": 1, "servos": [{"name": "neck", "pin": 18, "flags": ["centered"], "max_errors": 3, "min_pulse": 0.5, "max_pulse": 0.25, "speed": 1}]}`
+	var ps Problems
+	if _, err := Import(bytes.NewBufferString(bad)); !errors.As(err, &ps) {
+		t.Fatalf("invalid document, got: %v, want: problems", err)
+	}
+	if neck.Flags != 0 || neck.MaxErrors != 0 {
+		t.Errorf("invalid document changed the servo, got flags: %v, max errors: %d", neck.Flags, neck.MaxErrors)
+	}
+
+	good := `{"version": 1, "servos": [{"name": "neck", "pin": 18, "tags": ["head"], "min_pulse": 0.05, "max_pulse": 0.25, "speed": 1}]}`
+	if _, err := Import(bytes.NewBufferString(good)); err != nil {
+		t.Fatal(err)
+	}
+	if !neck.HasTag("head") {
+		t.Errorf("tags were not imported, got: %v", neck.Tags())
+	}
+}
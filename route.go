@@ -0,0 +1,131 @@
+package servo
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+)
+
+// defaultRouteHold is how long the input of a route keeps the servo from the
+// routes with lower priority.
+const defaultRouteHold = time.Second
+
+// RouteConfig routes an input of a control surface (e.g. an MQTT topic, an
+// OSC address, a gamepad axis or a MIDI controller) to a servo.
+type RouteConfig struct {
+	// Source is the name of the input, as given to Router.Input().
+	Source string `json:"source"`
+	// Servo is the name of the target servo.
+	Servo string `json:"servo"`
+	// InMin and InMax are the range of the input values. The values outside
+	// the range are clamped. (default 0.0 to 1.0)
+	InMin float64 `json:"in_min"`
+	InMax float64 `json:"in_max"`
+	// OutMin and OutMax are the angles of the servo at InMin and InMax,
+	// adjusted for its Flags.
+	OutMin float64 `json:"out_min"`
+	OutMax float64 `json:"out_max"`
+	// Curve is the exponent applied to the normalized input, e.g. 2.0 for a
+	// finer control around InMin. If 0, the response is linear.
+	Curve float64 `json:"curve,omitempty"`
+	// Priority arbitrates the routes to the same servo: the input of a
+	// route with a lower priority is ignored while a route with a higher
+	// priority is active.
+	Priority int `json:"priority,omitempty"`
+}
+
+// transform maps the input value to the angle of the servo.
+func (rc RouteConfig) transform(v float64) float64 {
+	inMin, inMax := rc.InMin, rc.InMax
+	if inMin == 0 && inMax == 0 {
+		inMax = 1
+	}
+	f := clamp((v-inMin)/(inMax-inMin), 0, 1)
+	if rc.Curve > 0 {
+		f = math.Pow(f, rc.Curve)
+	}
+	return rc.OutMin + f*(rc.OutMax-rc.OutMin)
+}
+
+// route is a route resolved to its servo.
+type route struct {
+	RouteConfig
+	servo *Servo
+}
+
+// owner is the route that last moved a servo.
+type owner struct {
+	priority int
+	at       time.Time
+}
+
+// Router moves the servos with the inputs of control surfaces, following a
+// routing table, so they can be assembled from the configuration file
+// without glue code for each combination. The input modules only need to
+// call Input() with the name of the source and its value. Use
+// servo.NewRouter() for correct initialization.
+type Router struct {
+	// Hold is how long the input of a route keeps the servo from the routes
+	// with lower priority. (default 1s)
+	Hold time.Duration
+
+	routes map[string][]route
+	owners map[*Servo]owner
+	lock   *sync.Mutex
+}
+
+// NewRouter creates a Router for the routes, e.g. the Routes of a Config. The
+// target servos must be connected.
+func NewRouter(routes []RouteConfig) (*Router, error) {
+	servos := make(map[string]*Servo)
+	for _, s := range _blaster.connected() {
+		servos[s.Name] = s
+	}
+
+	r := &Router{
+		Hold:   defaultRouteHold,
+		routes: make(map[string][]route),
+		owners: make(map[*Servo]owner),
+		lock:   new(sync.Mutex),
+	}
+	for _, rc := range routes {
+		if rc.Source == "" {
+			return nil, fmt.Errorf("route to %q: missing source", rc.Servo)
+		}
+		if rc.InMin == rc.InMax && rc.InMin != 0 {
+			return nil, fmt.Errorf("route %q: in_min and in_max are equal", rc.Source)
+		}
+		s, ok := servos[rc.Servo]
+		if !ok {
+			return nil, fmt.Errorf("route %q: servo %q is not connected", rc.Source, rc.Servo)
+		}
+		r.routes[rc.Source] = append(r.routes[rc.Source], route{rc, s})
+	}
+
+	return r, nil
+}
+
+// Input moves the servos routed from the source to the transformed value. It
+// returns false if the source has no routes.
+func (r *Router) Input(source string, value float64) bool {
+	r.lock.Lock()
+	routes, ok := r.routes[source]
+	now := time.Now()
+	var moves []route
+	for _, rt := range routes {
+		o, ok := r.owners[rt.servo]
+		if ok && o.priority > rt.Priority && now.Sub(o.at) < r.Hold {
+			continue
+		}
+		r.owners[rt.servo] = owner{rt.Priority, now}
+		moves = append(moves, rt)
+	}
+	r.lock.Unlock()
+
+	for _, rt := range moves {
+		rt.servo.MoveTo(rt.transform(value))
+	}
+
+	return ok
+}
@@ -0,0 +1,79 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRouteConfig_transform(t *testing.T) {
+	rc := RouteConfig{InMin: -1, InMax: 1, OutMin: 0, OutMax: 180}
+	// map[input]want
+	tests := map[float64]float64{
+		-1:  0,
+		0:   90,
+		1:   180,
+		5:   180,
+		-10: 0,
+	}
+	for input, want := range tests {
+		if got := rc.transform(input); got != want {
+			t.Errorf("transform(%.2f) -> got: %.2f, want: %.2f", input, got, want)
+		}
+	}
+
+	rc = RouteConfig{OutMin: 0, OutMax: 100, Curve: 2}
+	if got := rc.transform(0.5); got != 25 {
+		t.Errorf("curved transform(0.5) -> got: %.2f, want: %.2f", got, 25.0)
+	}
+}
+
+func TestRouter(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	s.Name = "neck"
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if _, err := NewRouter([]RouteConfig{{Source: "pad/x", Servo: "tail"}}); err == nil {
+		t.Error("route to an unknown servo was accepted")
+	}
+
+	r, err := NewRouter([]RouteConfig{
+		{Source: "pad/x", Servo: "neck", OutMin: 0, OutMax: 180},
+		{Source: "safety", Servo: "neck", OutMin: 90, OutMax: 90, Priority: 10},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Hold = 50 * time.Millisecond
+
+	if r.Input("unknown", 1) {
+		t.Error("input without routes was routed")
+	}
+
+	r.Input("pad/x", 0.25)
+	s.Wait()
+	if got := s.Position(); got != 45 {
+		t.Errorf("routed position, got: %.2f, want: %.2f", got, 45.0)
+	}
+
+	r.Input("safety", 0)
+	r.Input("pad/x", 1)
+	s.Wait()
+	if got := s.Position(); got != 90 {
+		t.Errorf("lower priority input was not ignored, got: %.2f, want: %.2f", got, 90.0)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	r.Input("pad/x", 0)
+	s.Wait()
+	if got := s.Position(); got != 0 {
+		t.Errorf("lower priority did not take over after hold, got: %.2f, want: %.2f", got, 0.0)
+	}
+}
@@ -0,0 +1,76 @@
+package servo
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SchemaVersion is the version of the formats of the configuration files and
+// of the persisted state, such as calibrations. Documents are written with
+// the version in their "version" field, and older documents are migrated
+// automatically when loaded. Documents without version are version 0, written
+// before the formats were versioned.
+const SchemaVersion = 1
+
+// migration upgrades a document from its version to the next one.
+type migration func(doc map[string]json.RawMessage) (map[string]json.RawMessage, error)
+
+// configMigrations upgrade the configuration files. The migration at index i
+// upgrades version i to version i+1.
+var configMigrations = []migration{
+	// 0 to 1: only the version is added.
+	func(doc map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+		return doc, nil
+	},
+}
+
+// calibrationMigrations upgrade the calibrations saved in a Store. The
+// migration at index i upgrades version i to version i+1.
+var calibrationMigrations = []migration{
+	// 0 to 1: the calibrations by name are moved under "calibrations".
+	func(doc map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+		calibrations, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]json.RawMessage{"calibrations": calibrations}, nil
+	},
+}
+
+// migrate upgrades the JSON document data, of the kind what, to SchemaVersion.
+// It returns an error if the document was written by a newer version of this
+// package.
+func migrate(what string, data []byte, migrations []migration) ([]byte, error) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	if doc == nil {
+		doc = make(map[string]json.RawMessage)
+	}
+
+	version := 0
+	if raw, ok := doc["version"]; ok {
+		// A version that is not a number is data of version 0, e.g. a
+		// servo named "version".
+		if err := json.Unmarshal(raw, &version); err == nil {
+			delete(doc, "version")
+		} else {
+			version = 0
+		}
+	}
+	if version < 0 || version > SchemaVersion {
+		return nil, fmt.Errorf("%s: unknown version %d, this package supports up to version %d", what, version, SchemaVersion)
+	}
+
+	for v := version; v < SchemaVersion; v++ {
+		var err error
+		if doc, err = migrations[v](doc); err != nil {
+			return nil, fmt.Errorf("%s: migrating from version %d: %v", what, v, err)
+		}
+		debugf("%s migrated from version %d to %d", what, v, v+1)
+	}
+
+	doc["version"] = json.RawMessage(fmt.Sprint(SchemaVersion))
+	return json.Marshal(doc)
+}
@@ -0,0 +1,85 @@
+// +build !live
+
+package servo
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSchemaVersion(t *testing.T) {
+	if got := len(configMigrations); got != SchemaVersion {
+		t.Errorf("config migrations up to version %d, want: %d", got, SchemaVersion)
+	}
+	if got := len(calibrationMigrations); got != SchemaVersion {
+		t.Errorf("calibration migrations up to version %d, want: %d", got, SchemaVersion)
+	}
+}
+
+func TestMigrate_calibrations(t *testing.T) {
+	st := NewMemoryStore()
+	// Calibrations saved before the formats were versioned, with a servo
+	// named "version".
+	st.Save(calibrationKey, []byte(`{
+		"jaw": {"min_pulse": 0.06, "max_pulse": 0.24},
+		"version": {"min_pulse": 0.07, "max_pulse": 0.23}
+	}`))
+
+	calibrations, err := LoadCalibrations(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := calibrations["jaw"], (Calibration{MinPulse: 0.06, MaxPulse: 0.24}); got != want {
+		t.Errorf("jaw was not migrated, got: %+v, want: %+v", got, want)
+	}
+	if got, want := calibrations["version"], (Calibration{MinPulse: 0.07, MaxPulse: 0.23}); got != want {
+		t.Errorf("version was not migrated, got: %+v, want: %+v", got, want)
+	}
+
+	if err := SaveCalibrations(st, calibrations); err != nil {
+		t.Fatal(err)
+	}
+	saved, _ := st.Load(calibrationKey)
+	if !strings.Contains(string(saved), `"version": 1`) {
+		t.Errorf("version was not saved:\n%s", saved)
+	}
+	again, err := LoadCalibrations(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(again) != 2 || again["jaw"] != calibrations["jaw"] {
+		t.Errorf("calibrations changed after saving, got: %+v", again)
+	}
+}
+
+func TestMigrate_newer(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		"future.json": `{"version": 99, "servos": [{"name": "jaw", "pin": 17}]}`,
+	})
+	if _, err := loadConfig(filepath.Join(dir, "future.json"), "robot"); err == nil {
+		t.Error("loaded a config of a newer version")
+	}
+
+	st := NewMemoryStore()
+	st.Save(calibrationKey, []byte(`{"version": 2, "calibrations": {}}`))
+	if _, err := LoadCalibrations(st); err == nil {
+		t.Error("loaded calibrations of a newer version")
+	}
+}
+
+func TestMigrate_config(t *testing.T) {
+	dir := writeConfigs(t, map[string]string{
+		"old.json": `{"servos": [{"name": "jaw", "pin": 17}]}`,
+	})
+	c, err := loadConfig(filepath.Join(dir, "old.json"), "robot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c.Version != SchemaVersion {
+		t.Errorf("wrong version, got: %d, want: %d", c.Version, SchemaVersion)
+	}
+	if sc, ok := c.Servo("jaw"); !ok || sc.Pin != 17 {
+		t.Errorf("servo was not loaded, got: %+v", sc)
+	}
+}
@@ -0,0 +1,170 @@
+package servo
+
+import "math"
+
+// SetJerk makes the moves of the servo follow S-curves, limiting how fast its
+// acceleration changes to jerk, in degrees per second cubed, e.g. for camera
+// sliders and delicate animatronics. The acceleration is also limited by
+// SetAcceleration(), if set. A jerk of 0 disables S-curves (default), and
+// negative values are set to 0. Use WithJerk() to choose per move.
+//
+// An S-curve is planned from rest when the move starts, so a move replacing
+// another one mid-way starts from rest as well. Changes of the speed while
+// moving, e.g. by SetSpeed() or SoftStop(), slow down or speed up the whole
+// curve.
+func (s *Servo) SetJerk(jerk float64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.jerk = math.Max(jerk, 0)
+}
+
+// Jerk returns the jerk limit of the moves of the servo in degrees per second
+// cubed, or 0 if its moves do not follow S-curves.
+func (s *Servo) Jerk() float64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.jerk
+}
+
+// WithJerk makes the move follow an S-curve with the jerk limit, in degrees
+// per second cubed, overriding the limit set by Servo.SetJerk(). A jerk of 0
+// makes the move ignore the limit of the servo.
+func WithJerk(jerk float64) MoveOption {
+	return func(m *Move) {
+		m.jerk = math.Max(jerk, 0)
+		m.hasJerk = true
+	}
+}
+
+// jerkOf returns the jerk limit of the move m of the servo. It must be called
+// with the servo locked.
+func (s *Servo) jerkOf(m *Move) float64 {
+	if m != nil && m.hasJerk {
+		return m.jerk
+	}
+	return s.jerk
+}
+
+// ramp is a jerk-limited change of velocity from rest to peak.
+type ramp struct {
+	peak, accel, jerk float64
+	// t1 is the end of the rise of the acceleration, t2 the end of the
+	// constant acceleration, and end the end of the ramp, in seconds.
+	t1, t2, end float64
+	// d1 and v1 are the distance and velocity at t1.
+	d1, v1 float64
+	// distance is the distance covered by the ramp.
+	distance float64
+}
+
+// newRamp plans a ramp to peak with the jerk and acceleration limits. An
+// accel of 0 does not limit the acceleration, and an infinite jerk makes a
+// trapezoidal ramp.
+func newRamp(peak, accel, jerk float64) ramp {
+	r := ramp{peak: peak, accel: accel, jerk: jerk}
+	if accel == 0 || peak*jerk < accel*accel {
+		// The acceleration never reaches its limit.
+		r.t1 = math.Sqrt(peak / jerk)
+		r.t2 = r.t1
+		r.end = 2 * r.t1
+		r.accel = jerk * r.t1
+	} else {
+		r.t1 = accel / jerk
+		r.t2 = peak / accel
+		r.end = r.t2 + r.t1
+	}
+	if r.t1 > 0 {
+		r.d1 = jerk * r.t1 * r.t1 * r.t1 / 6
+		r.v1 = jerk * r.t1 * r.t1 / 2
+	}
+	// The velocity is symmetric around the middle of the ramp.
+	r.distance = peak * r.end / 2
+	return r
+}
+
+// at returns the distance covered t seconds after the start of the ramp.
+func (r ramp) at(t float64) float64 {
+	switch {
+	case t <= 0:
+		return 0
+	case t < r.t1:
+		return r.jerk * t * t * t / 6
+	case t < r.t2:
+		u := t - r.t1
+		return r.d1 + r.v1*u + r.accel*u*u/2
+	case t < r.end:
+		u := r.end - t
+		return r.distance - (r.peak*u - r.jerk*u*u*u/6)
+	}
+	return r.distance
+}
+
+// scurve is a jerk-limited move from rest to rest: a ramp up, a cruise at the
+// peak velocity, and a ramp down.
+type scurve struct {
+	from, dir  float64
+	up, down   ramp
+	cruise     float64
+	distance   float64
+	peak, time float64
+}
+
+// planSCurve plans an S-curve from the raw position from to to, at up to
+// speed, with the accel, decel and jerk limits (0 for no accel or decel
+// limit). With an infinite jerk, the move is trapezoidal, or linear without
+// accel and decel limits.
+func planSCurve(from, to, speed, accel, decel, jerk float64) scurve {
+	c := scurve{from: from, dir: 1, distance: math.Abs(to - from)}
+	if to < from {
+		c.dir = -1
+	}
+	if c.distance == 0 || speed == 0 {
+		return c
+	}
+
+	fits := func(peak float64) bool {
+		return newRamp(peak, accel, jerk).distance+newRamp(peak, decel, jerk).distance <= c.distance
+	}
+	c.peak = speed
+	if !fits(speed) {
+		// The servo never reaches its speed, find the highest peak.
+		lo, hi := 0.0, speed
+		for i := 0; i < 60; i++ {
+			mid := (lo + hi) / 2
+			if fits(mid) {
+				lo = mid
+			} else {
+				hi = mid
+			}
+		}
+		c.peak = lo
+	}
+
+	c.up = newRamp(c.peak, accel, jerk)
+	c.down = newRamp(c.peak, decel, jerk)
+	if c.peak > 0 {
+		c.cruise = (c.distance - c.up.distance - c.down.distance) / c.peak
+	}
+	c.time = c.up.end + c.cruise + c.down.end
+	return c
+}
+
+func (c scurve) duration() float64 { return c.time }
+
+// at returns the raw position t seconds after the start of the curve.
+func (c scurve) at(t float64) float64 {
+	var d float64
+	switch {
+	case t >= c.time:
+		d = c.distance
+	case t < c.up.end:
+		d = c.up.at(t)
+	case t < c.up.end+c.cruise:
+		d = c.up.distance + c.peak*(t-c.up.end)
+	default:
+		d = c.distance - c.down.at(c.time-t)
+	}
+	return c.from + c.dir*d
+}
@@ -0,0 +1,144 @@
+// +build !live
+
+package servo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPlanSCurve(t *testing.T) {
+	tests := []struct {
+		name                      string
+		from, to                  float64
+		speed, accel, decel, jerk float64
+	}{
+		{"full", 0, 180, 180, 360, 360, 3600},
+		{"no accel limit", 0, 180, 180, 0, 0, 3600},
+		{"no cruise", 10, 30, 180, 360, 360, 3600},
+		{"asymmetric", 180, 20, 300, 2000, 400, 5000},
+		{"short", 90, 91, 300, 1000, 1000, 20000},
+	}
+	const dt = 0.001
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := planSCurve(tt.from, tt.to, tt.speed, tt.accel, tt.decel, tt.jerk)
+			if got := c.at(0); got != tt.from {
+				t.Errorf("wrong start, got: %.4f, want: %.2f", got, tt.from)
+			}
+			if got := c.at(c.time); got != tt.to {
+				t.Errorf("wrong end, got: %.4f, want: %.2f", got, tt.to)
+			}
+
+			accel := math.Max(tt.accel, tt.decel)
+			if tt.accel == 0 || tt.decel == 0 {
+				accel = math.Inf(1)
+			}
+			var p, v, a [4]float64
+			for i := 0; float64(i)*dt <= c.time+3*dt; i++ {
+				copy(p[:], p[1:])
+				copy(v[:], v[1:])
+				copy(a[:], a[1:])
+				p[3] = c.at(float64(i) * dt)
+				v[3] = (p[3] - p[2]) / dt
+				a[3] = (v[3] - v[2]) / dt
+				if i < 3 {
+					continue
+				}
+				if math.Abs(v[3]) > tt.speed*1.001 {
+					t.Fatalf("speed exceeded at %.3fs, got: %.2f, max: %.2f", float64(i)*dt, v[3], tt.speed)
+				}
+				if math.Abs(a[3]) > accel*1.01 {
+					t.Fatalf("acceleration exceeded at %.3fs, got: %.2f, max: %.2f", float64(i)*dt, a[3], accel)
+				}
+				if j := (a[3] - a[2]) / dt; math.Abs(j) > tt.jerk*1.01 {
+					t.Fatalf("jerk exceeded at %.3fs, got: %.2f, max: %.2f", float64(i)*dt, j, tt.jerk)
+				}
+			}
+		})
+	}
+}
+
+func TestPlanSCurve_time(t *testing.T) {
+	// 0.1s to reach the acceleration, 0.5s to reach the speed, and 0.4s of
+	// cruise between the ramps of 54 degrees each.
+	c := planSCurve(0, 180, 180, 360, 360, 3600)
+	if math.Abs(c.time-1.6) > 1e-9 {
+		t.Errorf("wrong travel time, got: %.3fs, want: 1.600s", c.time)
+	}
+	if got := c.at(0.8); math.Abs(got-90) > 1e-9 {
+		t.Errorf("wrong position at the middle, got: %.4f, want: 90.00", got)
+	}
+}
+
+func TestServo_SetJerk(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetMaxSpeed(180)
+	s.SetAcceleration(360, 360)
+	s.SetJerk(3600)
+
+	start := time.Now()
+	s.MoveTo(180).Wait()
+	elapsed := time.Since(start)
+	if want := 1600 * time.Millisecond; elapsed < want-50*time.Millisecond || elapsed > want+60*time.Millisecond {
+		t.Errorf("wrong travel time, got: %v, want: %v", elapsed, want)
+	}
+	if s.Position() != 180 {
+		t.Errorf("servo did not arrive, got: %.2f", s.Position())
+	}
+	if d := s.travel(0); d != 1600*time.Millisecond {
+		t.Errorf("wrong estimate, got: %v, want: %v", d, 1600*time.Millisecond)
+	}
+
+	s.SetJerk(-1)
+	if j := s.Jerk(); j != 0 {
+		t.Errorf("wrong jerk, got: %.2f, want: 0.00", j)
+	}
+}
+
+func TestWithJerk(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetMaxSpeed(180)
+	s.SetAcceleration(360, 360)
+
+	start := time.Now()
+	s.MoveTo(180, WithJerk(3600)).Wait()
+	if elapsed, want := time.Since(start), 1600*time.Millisecond; elapsed < want-50*time.Millisecond || elapsed > want+60*time.Millisecond {
+		t.Errorf("wrong travel time with jerk, got: %v, want: %v", elapsed, want)
+	}
+
+	// The next move does not follow an S-curve.
+	start = time.Now()
+	s.MoveTo(0).Wait()
+	if elapsed, want := time.Since(start), 1500*time.Millisecond; elapsed < want-50*time.Millisecond || elapsed > want+60*time.Millisecond {
+		t.Errorf("wrong travel time without jerk, got: %v, want: %v", elapsed, want)
+	}
+}
+
+func TestPlanSCurve_trapezoid(t *testing.T) {
+	// An infinite jerk makes the ramps of travelTime().
+	for _, tt := range []struct{ accel, decel float64 }{{360, 360}, {0, 0}, {360, 0}, {60, 60}} {
+		c := planSCurve(0, 180, 180, tt.accel, tt.decel, math.Inf(1))
+		if want := travelTime(180, 180, tt.accel, tt.decel); math.Abs(c.time-want) > 1e-6 {
+			t.Errorf("%v: wrong travel time, got: %.3fs, want: %.3fs", tt, c.time, want)
+		}
+		for x := 0.0; x <= c.time; x += 0.01 {
+			if p := c.at(x); math.IsNaN(p) || p < 0 || p > 180 {
+				t.Fatalf("%v: wrong position at %.2fs, got: %.2f", tt, x, p)
+			}
+		}
+	}
+}
@@ -2,8 +2,10 @@ package servo
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -30,6 +32,12 @@ func (f flag) String() string {
 	if f.is(Normalized) {
 		fmt.Fprintf(s, " Normalized")
 	}
+	if f.is(Reversed) {
+		fmt.Fprintf(s, " Reversed")
+	}
+	if f.is(Radians) {
+		fmt.Fprintf(s, " Radians")
+	}
 
 	fmt.Fprintf(s, " )")
 
@@ -37,12 +45,23 @@ func (f flag) String() string {
 }
 
 const (
-	// Centered sets the range of the servo from -90 to 90 degrees.
+	// Centered sets the range of the servo from -90 to 90 degrees, or
+	// around 0 degrees for a servo with a wider Range, e.g. -135 to 135.
 	// Together with Normalized, the range of the servo is set to -1 to 1.
 	Centered flag = (1 << iota)
 	// Normalized sets the range of the servo from 0 to 2.
 	// Together with Centered, the range of the servo is set to -1 to 1.
 	Normalized
+	// Reversed inverts the direction of the servo at the pwm level, so 0
+	// degrees drives MaxPulse and the other way around, e.g. for mirrored
+	// mechanisms. It combines with Centered and Normalized, and the angles
+	// reported by the servo are not inverted.
+	Reversed
+	// Radians sets the angles of the servo in radians, e.g. from 0 to π, or
+	// from -π/2 to π/2 together with Centered, for kinematics libraries. It
+	// has no effect together with Normalized. Speeds, travel limits and
+	// calibration points are still in degrees.
+	Radians
 )
 
 // Servo is a struct that holds all the information necessary to control a
@@ -54,7 +73,9 @@ type Servo struct {
 	//
 	// CAUTION: Incorrect pin assignment might cause damage to your Raspberry
 	// Pi.
-	pin gpio
+	//
+	// pin is accessed atomically, since it can be changed by Remap().
+	pin int32
 	// Name is an optional value to assign a meaningful name to the servo.
 	Name string
 	// Flags is a bit flag that sets various configuration parameters.
@@ -63,22 +84,116 @@ type Servo struct {
 	//
 	// servo.Normalized sets the range of the servo from 0 to 2.
 	// Together with servo.Centered, the range of the servo is set to -1 to 1.
+	//
+	// servo.Reversed inverts the direction of the servo.
+	//
+	// servo.Radians sets the angles of the servo in radians.
 	Flags flag
 
-	// MinPulse is the minimum pwm pulse of the servo. (default 0.05 s)
-	// MaxPulse is the maximum pwm pulse of the servo. (default 0.25 s)
+	// MinPulse is the minimum pwm pulse of the servo. (default 0.05, 500µs)
+	// MaxPulse is the maximum pwm pulse of the servo. (default 0.25, 2.5ms)
+	// The pulses are fractions of 10ms, the default cycle of pi-blaster. Use
+	// servo.PulseWidths() to set them from pulse widths instead.
 	// These calibration variables should be immutables once the servo is
 	// connected..
 	MinPulse, MaxPulse float64
+	// Range is the travel of the servo in degrees from MinPulse to MaxPulse,
+	// e.g. 270 for wide-range servos or sail winches (default: 0, which is
+	// 180 degrees). Raw angles go from 0 to Range. Like MinPulse and
+	// MaxPulse, it should be immutable once the servo is connected.
+	Range float64
+	// points are the sorted points of the calibration table. They are
+	// guarded by the lock. See Servo.SetCalibrationTable().
+	points []CalibrationPoint
+	// AllowUnsafePulse allows MinPulse and MaxPulse outside the plausible
+	// servo range of 200µs to 3000µs. By default, Connect() and Calibrate()
+	// refuse such pulses, since a typo can burn out a servo or confuse an
+	// ESC.
+	AllowUnsafePulse bool
+	// MinAngle and MaxAngle are the software travel limits of the servo, as
+	// raw angles from 0 to Range regardless of its Flags, e.g. 30 and
+	// 150 for a linkage that cannot travel further. No target, path or layer
+	// drives the servo outside them. If both are 0, the servo travels its
+	// whole range. Like MinPulse and MaxPulse, they should be immutables
+	// once the servo is connected.
+	MinAngle, MaxAngle float64
+	// MaxErrors is the number of consecutive write errors after which the
+	// servo enters the fault state (default: 0, disabled). See Fault().
+	MaxErrors int
+	// LowPriority lets the manager update the servo less often when it cannot
+	// keep up with all the servos. See servo.Degradation().
+	LowPriority bool
 
 	target, position float64
 	deltaT           time.Time
 	lastPWM          pwm
 
-	step, maxStep float64
+	// writtenPWM is the last pwm flushed to pi-blaster at writtenAt.
+	writtenPWM pwm
+	writtenAt  time.Time
+
+	errors   int
+	fault    error
+	counters Counters
+	// span is the current move. See Move.
+	span *Move
+	// meta is the metadata of the current move. See MoveToWith().
+	meta Metadata
+	// queue are the raw targets of the pending moves, and queueDone is
+	// closed when the queue runner ends. See Enqueue().
+	queue     []float64
+	queueDone chan struct{}
+	// release is set to 1 when the manager should release the pin. It is
+	// accessed atomically.
+	release int32
+	// detached is set to 1 by Detach() until the servo is driven again. It
+	// is accessed atomically.
+	detached int32
 
-	idle     bool
+	step, maxStep float64
+	// jerk is the jerk limit of the S-curves of the moves, if not 0. See
+	// Servo.SetJerk().
+	jerk float64
+	// expo is the exponential response of the targets. See Servo.SetExpo().
+	expo float64
+	// velocity is the signed speed of the servo in degrees per second at
+	// its last update, and accel and decel limit how fast it changes, if
+	// not 0. See Servo.SetAcceleration().
+	velocity     float64
+	accel, decel float64
+	// speedCap is the fraction of maxStep that the servo cannot exceed, if
+	// capped. See Servo.SetSpeedCap().
+	speedCap float64
+	capped   bool
+	// tags holds the tagSet of the servo. See Servo.AddTags().
+	tags atomic.Value
+
+	// interval is the update interval of the servo. See
+	// Servo.SetUpdateInterval().
+	interval time.Duration
+	// updated is the time of the last update of the servo by the manager,
+	// which is the only one to access it.
+	updated time.Time
+
+	// master is the servo mirrored by the servo, if any, with ratio and
+	// offset. See Servo.Follow().
+	master        *Servo
+	ratio, offset float64
+
+	// layers are the motion layers added on top of the position, and
+	// idleNoise the layer of SetIdleNoise(), if any.
+	layers    []*Layer
+	idleNoise *Layer
+
+	idle bool
+	// motion holds the motion snapshot of the servo, so reads never block
+	// the manager. See Servo.publish().
+	motion atomic.Value
+	// finished wakes up the waiters when the servo goes idle, and idleDone
+	// is the channel of Done() until then. idleDone is guarded by the lock
+	// of finished.
 	finished *sync.Cond
+	idleDone chan struct{}
 	lock     *sync.RWMutex
 }
 
@@ -93,24 +208,21 @@ type Servo struct {
 // GPIO_PIN is the connection pin of the servo, and FLAGS_SET is the list of
 // flags set (default: NONE).
 func (s *Servo) String() string {
-	return fmt.Sprintf("servo %q connected to gpio(%d) [flags: %v]", s.Name, s.pin, s.Flags)
+	return fmt.Sprintf("servo %q connected to gpio(%d) [flags: %v]", s.Name, s.gpio(), s.Flags)
 }
 
 // New creates a new Servo struct with default values, connected at a GPIO pin
 // of the Raspberry Pi. You should check that the pin is controllable with pi-blaster.
+// Use servo.NewPin() to name the pin on other boards.
 //
 // CAUTION: Incorrect pin assignment might cause damage to your Raspberry
 // Pi.
 func New(GPIO int) (s *Servo) {
-	// maxS is the maximun degrees/s for a tipical servo of speed
-	// 0.19s/60degrees.
-	const maxS = 315.7
-
 	s = &Servo{
-		pin:      gpio(GPIO),
+		pin:      int32(GPIO),
 		Name:     fmt.Sprintf("Servo%d", GPIO),
-		maxStep:  maxS,
-		step:     maxS,
+		maxStep:  DefaultMaxSpeed,
+		step:     DefaultMaxSpeed,
 		MinPulse: 0.05,
 		MaxPulse: 0.25,
 
@@ -118,34 +230,145 @@ func New(GPIO int) (s *Servo) {
 		finished: sync.NewCond(&sync.Mutex{}),
 		lock:     new(sync.RWMutex),
 	}
+	s.publish()
 
 	return s
 }
 
-// Connect connects the servo to the pi-blaster daemon.
+// Connect connects the servo to the pi-blaster daemon. It returns an error if
+// the servo package was closed (ErrClosed), if another servo is connected to
+// the same pin (ErrPinClaimed), or if MinPulse or MaxPulse are outside the
+// safe range or do not fit the calibration table (ErrOutOfRange, see
+// AllowUnsafePulse and SetCalibrationTable).
 func (s *Servo) Connect() error {
-	_blaster.subscribe(s)
+	if err := s.checkPulses(s.Calibration()); err != nil {
+		return err
+	}
+	if err := s.checkTable(s.Calibration(), s.CalibrationTable()); err != nil {
+		return err
+	}
+
+	if err := _blaster.subscribe(s); err != nil {
+		return err
+	}
+
+	s.lock.Lock()
+	s.counters.Connects++
+	s.lock.Unlock()
 
 	return nil
 }
 
+// Pin returns the GPIO pin of the servo.
+func (s *Servo) Pin() int {
+	return int(atomic.LoadInt32(&s.pin))
+}
+
+// gpio returns the GPIO pin of the servo.
+func (s *Servo) gpio() gpio {
+	return gpio(atomic.LoadInt32(&s.pin))
+}
+
+// Remap moves the servo to the GPIO pin newPin, e.g. after a wiring change or
+// to fail over to a spare channel. If the servo is connected, the old pin is
+// released (0 duty) and the new pin is driven in the same flush, keeping the
+// position, calibration and state of the servo. It returns an error if another
+// servo is connected to newPin or if the servo package was closed.
+//
+// CAUTION: Incorrect pin assignment might cause damage to your Raspberry
+// Pi.
+func (s *Servo) Remap(newPin int) error {
+	return _blaster.remap(s, gpio(newPin))
+}
+
 // Close cleans up the state of the servo and deactivates the corresponding
-// GPIO pin.
-func (s *Servo) Close() {
-	_blaster.unsubscribe(s)
+// GPIO pin. It returns the error of the write that releases the pin. It is
+// safe to call Close after servo.Close(), which already released the pin.
+func (s *Servo) Close() error {
+	if err := _blaster.unsubscribe(s); err != nil {
+		return s.wrap(err)
+	}
+	return nil
 }
 
-// Position returns the current angle of the servo, adjusted for its Flags.
+// Position returns the current angle of the servo, adjusted for its Flags. It
+// never blocks the manager.
 func (s *Servo) Position() float64 {
-	s.lock.RLock()
-	defer s.lock.RUnlock()
+	return s.adjust(s.snapshot().position)
+}
 
-	p := s.position
+// motion is a snapshot of the motion state of a servo, which is read without
+// locking.
+type motion struct {
+	position, target float64
+	idle             bool
+}
+
+// publish stores a snapshot of the motion state of the servo. It must be
+// called with the servo locked after changing its position, target or idle
+// state, and before waking up its waiters.
+func (s *Servo) publish() {
+	s.motion.Store(motion{s.position, s.target, s.idle})
+}
+
+// snapshot returns the last published motion state of the servo.
+func (s *Servo) snapshot() motion {
+	m, _ := s.motion.Load().(motion)
+	return m
+}
+
+// fullRange returns the raw angle of MaxPulse. See Range.
+func (s *Servo) fullRange() float64 {
+	if s.Range <= 0 {
+		return 180
+	}
+	return s.Range
+}
+
+// adjust converts a raw angle from 0 to Range to the range set by the servo's
+// Flags.
+func (s *Servo) adjust(p float64) float64 {
+	half := s.fullRange() / 2
 	if s.Flags.is(Centered) {
-		p -= 90
+		p -= half
 	}
 	if s.Flags.is(Normalized) {
-		p /= 90
+		p /= half
+	} else if s.Flags.is(Radians) {
+		p *= math.Pi / 180
+	}
+
+	return p
+}
+
+// limits returns the raw travel limits of the servo. See MinAngle and
+// MaxAngle.
+func (s *Servo) limits() (lo, hi float64) {
+	r := s.fullRange()
+	if s.MinAngle == 0 && s.MaxAngle == 0 {
+		return 0, r
+	}
+	lo = clamp(s.MinAngle, 0, r)
+	return lo, clamp(s.MaxAngle, lo, r)
+}
+
+// limit clamps the raw angle p to the travel limits of the servo.
+func (s *Servo) limit(p float64) float64 {
+	lo, hi := s.limits()
+	return clamp(p, lo, hi)
+}
+
+// raw converts an angle in the range set by the servo's Flags to a raw angle
+// from 0 to Range. It is the inverse of adjust.
+func (s *Servo) raw(p float64) float64 {
+	half := s.fullRange() / 2
+	if s.Flags.is(Normalized) {
+		p *= half
+	} else if s.Flags.is(Radians) {
+		p *= 180 / math.Pi
+	}
+	if s.Flags.is(Centered) {
+		p += half
 	}
 
 	return p
@@ -155,35 +378,97 @@ func (s *Servo) Position() float64 {
 type Waiter interface {
 	// Wait waits for the servo to finish moving.
 	Wait()
+	// Done returns a channel that is closed when the servo finishes moving,
+	// so the wait can be combined with other channels in a select, e.g. a
+	// context or a timer.
+	Done() <-chan struct{}
 }
 
 // MoveTo sets a target angle for the servo to move. The magnitude of the target
 // depends on the servo's Flags. The target is automatically clamped to the set
 // range. If called concurrently, the target position is overridden by the last
 // goroutine (usually non-deterministic).
-func (s *Servo) MoveTo(target float64) (wait Waiter) {
-	s.moveTo(target)
-	return s
+//
+// The returned Move waits for this move only, and tells if it reached its
+// target or was overridden, stopped or canceled. The options apply to this
+// move only, e.g. servo.WithEasing() or servo.WithJerk().
+func (s *Servo) MoveTo(target float64, opts ...MoveOption) *Move {
+	return s.moveToWith(target, nil, opts...)
 }
 
-func (s *Servo) moveTo(target float64) {
-	if s.Flags.is(Normalized) {
-		target *= 90
-	}
-	if s.Flags.is(Centered) {
-		target += 90
+// Metadata is opaque information attached to a move, e.g. the business action
+// that caused it. It must not be changed after it is given to a move.
+type Metadata map[string]string
+
+// MoveToWith is like MoveTo, but attaches the metadata to the move. The
+// metadata is echoed in the events of the servo until the move finishes or
+// stops, so an application can correlate physical motion with its cause.
+func (s *Servo) MoveToWith(target float64, meta Metadata, opts ...MoveOption) *Move {
+	return s.moveToWith(target, meta, opts...)
+}
+
+// MoveBy moves the servo by delta from its current target, or from its
+// position if it is idle, e.g. to nudge it with a joystick. The magnitude of
+// delta depends on the servo's Flags, and the new target is clamped to the set
+// range like with MoveTo().
+func (s *Servo) MoveBy(delta float64) *Move {
+	// The current target already has the expo applied to it.
+	s.lock.RLock()
+	target := s.adjust(s.removeExpo(s.snapshot().target))
+	s.lock.RUnlock()
+
+	return s.moveTo(target + delta)
+}
+
+func (s *Servo) moveTo(target float64) *Move {
+	return s.moveToWith(target, nil)
+}
+
+func (s *Servo) moveToWith(target float64, meta Metadata, opts ...MoveOption) *Move {
+	span := newMove(s, target, opts)
+	target = s.raw(target)
+
+	if _blaster.isClosed() || InMaintenance() {
+		// Nobody would move the servo.
+		span.end(MoveStopped)
+		return span
 	}
 
 	s.lock.Lock()
-	defer s.lock.Unlock()
-
-	if s.step == 0.0 {
+	if s.fault != nil {
+		s.lock.Unlock()
+		span.end(MoveFaulted)
+		return span
+	}
+	if s.master != nil {
+		// The servo is mirroring its master.
+		s.lock.Unlock()
+		span.end(MoveStopped)
+		return span
+	}
+	old := s.span
+	s.span = span
+	target = s.applyExpo(target)
+	if s.speedOf(span) == 0.0 {
 		s.target = s.position
 	} else {
-		s.target = clamp(target, 0, 180)
+		s.target = s.limit(target)
+		if s.target != target {
+			s.counters.Clamps++
+		}
 	}
 	s.deltaT = time.Now()
 	s.idle = false
+	s.publish()
+	s.meta = meta
+	e := s.event(EventMove, s.position)
+	s.lock.Unlock()
+	_blaster.wakeUp()
+
+	endMove(old, MoveReplaced)
+	_blaster.bus.publish(e)
+
+	return span
 }
 
 // SetSpeed changes the speed of the servo from (still) 0.0 to 1.0 (max speed).
@@ -196,92 +481,212 @@ func (s *Servo) SetSpeed(percentage float64) {
 	s.step = s.maxStep * clamp(percentage, 0.0, 1.0)
 }
 
-// Stop stops moving the servo. This effectively sets the target position to
-// the stopped position of the servo.
-func (s *Servo) Stop() {
+// speedFraction returns the speed set by SetSpeed(), or 0 if the max speed is
+// 0. It must be called with the servo locked.
+func (s *Servo) speedFraction() float64 {
+	if s.maxStep == 0 {
+		return 0
+	}
+	return s.step / s.maxStep
+}
+
+// DefaultMaxSpeed is the max speed in degrees per second of a servo created
+// with servo.New(), which is the speed of a typical servo of 0.19s/60degrees.
+const DefaultMaxSpeed = 315.7
+
+// SetMaxSpeed sets the max speed of the servo in raw degrees per second,
+// regardless of its Flags, e.g. as measured on the real
+// hardware (default: servo.DefaultMaxSpeed). The speed set by SetSpeed() is
+// kept as a fraction of the max speed, so SetSpeed(1.0) moves the servo at
+// degreesPerSecond. Negative speeds are set to 0.
+func (s *Servo) SetMaxSpeed(degreesPerSecond float64) {
+	if degreesPerSecond < 0 {
+		degreesPerSecond = 0
+	}
+
 	s.lock.Lock()
 	defer s.lock.Unlock()
 
+	fraction := 1.0
+	if s.maxStep != 0 {
+		fraction = s.step / s.maxStep
+	}
+	s.maxStep = degreesPerSecond
+	s.step = degreesPerSecond * fraction
+}
+
+// MaxSpeed returns the max speed of the servo in degrees per second.
+func (s *Servo) MaxSpeed() float64 {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.maxStep
+}
+
+// Stop stops moving the servo. This effectively sets the target position to
+// the stopped position of the servo. The pending moves of the queue are
+// dropped.
+func (s *Servo) Stop() {
+	s.stop(nil, MoveStopped)
+}
+
+// stop stops moving the servo and ends its current move with the outcome. If
+// only is not nil, the servo is stopped only if only is its current move.
+func (s *Servo) stop(only *Move, outcome MoveOutcome) {
+	s.lock.Lock()
+	if only != nil && s.span != only {
+		s.lock.Unlock()
+		return
+	}
 	s.target = s.position
+	s.velocity = 0
+	s.queue = nil
 	s.idle = true
-	s.finished.L.Lock()
-	s.finished.Broadcast()
-	s.finished.L.Unlock()
+	s.publish()
+	s.counters.Stops++
+	s.finish()
+	e := s.event(EventStop, s.position)
+	s.meta = nil
+	span := s.span
+	s.span = nil
+	s.lock.Unlock()
+
+	endMove(span, outcome)
+	_blaster.bus.publish(e)
 }
 
 // SetPosition immediately sets the angle the servo.
 func (s *Servo) SetPosition(position float64) {
-	if s.Flags.is(Normalized) {
-		position *= 90
-	}
-	if s.Flags.is(Centered) {
-		position += 90
-	}
+	s.setPosition(s.raw(position))
+}
 
+// setPosition immediately sets the raw angle of the servo.
+func (s *Servo) setPosition(position float64) {
 	s.lock.Lock()
-	defer s.lock.Unlock()
+	if s.fault != nil || InMaintenance() {
+		s.lock.Unlock()
+		return
+	}
 
-	s.position = clamp(position, 0, 180)
+	s.position = s.limit(position)
 	s.target = s.position
+	s.velocity = 0
 	s.idle = false
+	s.publish()
+	// The jump overrides the current move.
+	span := s.span
+	s.span = nil
+	s.lock.Unlock()
+
+	endMove(span, MoveReplaced)
+	_blaster.wakeUp()
 }
 
-// pwm linearly interpolates an angle based on the start, finish, and
-// duration of the movement, and returns the gpio pin and adjusted pwm for the
-// current time.
+// pwm interpolates an angle based on the start, finish, and duration of the
+// movement, with the acceleration limits of the servo, and returns the gpio
+// pin and adjusted pwm for the current time.
 func (s *Servo) pwm() (gpio, pwm) {
 	ok := false
 	s.lock.RLock()
 	p := s.position
+	v := s.velocity
 	_pwm := s.lastPWM
 
 	defer func() {
 		if !ok {
 			s.lock.Lock()
 			s.position = p
+			s.velocity = v
 			s.lastPWM = _pwm
 			s.deltaT = time.Now()
 
-			if p == s.target {
+			events := []Event{s.event(EventPosition, p)}
+			var span *Move
+			outcome := MoveFinished
+			finished := p == s.target && !s.span.playing()
+			if finished {
 				s.idle = true
-				s.finished.L.Lock()
-				s.finished.Broadcast()
-				s.finished.L.Unlock()
+			}
+			s.publish()
+			if finished {
+				s.finish()
+				events = append(events, s.event(EventFinish, p))
+				s.meta = nil
+				span = s.span
+				s.span = nil
+				if span != nil && span.stopping {
+					outcome = MoveStopped
+				}
 			}
 			s.lock.Unlock()
+
+			endMove(span, outcome)
+
+			for _, e := range events {
+				_blaster.bus.publish(e)
+			}
 		}
 	}()
 	defer s.lock.RUnlock()
 
 	if s.position == s.target && s.idle {
 		ok = true
-		return s.pin, _pwm
-	}
-
-	delta := time.Since(s.deltaT).Seconds() * s.step
-	if s.target < s.position {
-		p = s.position - delta
-		if p <= s.target {
-			p = s.target
-		}
-	} else {
-		p = s.position + delta
-		if p >= s.target {
-			p = s.target
+		if len(s.layers) > 0 && s.fault == nil {
+			return s.gpio(), s.pulse(s.layered(p, time.Now()))
 		}
+		return s.gpio(), _pwm
 	}
 
-	_pwm = pwm(remap(p, 0, 180, s.MinPulse, s.MaxPulse))
+	p, v = s.next(time.Since(s.deltaT).Seconds())
+
+	_pwm = s.pulse(s.layered(p, time.Now()))
 
-	return s.pin, _pwm
+	return s.gpio(), _pwm
 }
 
-// isIdle checks if the servo is not moving.
-func (s *Servo) isIdle() bool {
+// LastPWM returns the last pwm that was actually written to pi-blaster for the
+// servo and when it was written. Unlike the pwm computed by the manager, it is
+// not updated if the write did not happen (e.g. in canary mode). If nothing
+// was written yet, the time is zero.
+func (s *Servo) LastPWM() (float64, time.Time) {
 	s.lock.RLock()
 	defer s.lock.RUnlock()
 
-	return s.idle
+	return float64(s.writtenPWM), s.writtenAt
+}
+
+// isIdle checks if the servo is not moving. It never blocks the manager.
+func (s *Servo) isIdle() bool {
+	return s.snapshot().idle
+}
+
+// Done returns a channel that is closed when the servo stops moving. If the
+// servo is not moving, the channel is closed right away. The calls to Done
+// until the servo stops share the same channel.
+func (s *Servo) Done() <-chan struct{} {
+	s.finished.L.Lock()
+	defer s.finished.L.Unlock()
+
+	if s.isIdle() {
+		return closedDone
+	}
+	if s.idleDone == nil {
+		s.idleDone = make(chan struct{})
+	}
+	return s.idleDone
+}
+
+// finish wakes up the waiters of the servo, and closes the channel of Done()
+// if the servo is idle. It must be called after publishing the idle state.
+func (s *Servo) finish() {
+	s.finished.L.Lock()
+	defer s.finished.L.Unlock()
+
+	s.finished.Broadcast()
+	if s.idleDone != nil && s.isIdle() {
+		close(s.idleDone)
+		s.idleDone = nil
+	}
 }
 
 // Wait waits for the servo to stop moving. It is concurrent-safe.
@@ -304,6 +709,19 @@ func clamp(value, min, max float64) float64 {
 	return value
 }
 
+// pulse returns the pwm of the raw angle p, following the calibration and the
+// Reversed flag of the servo.
+func (s *Servo) pulse(p float64) pwm {
+	r := s.fullRange()
+	if s.Flags.is(Reversed) {
+		p = r - p
+	}
+	if len(s.points) > 0 {
+		return pwm(interpolate(p, r, s.MinPulse, s.MaxPulse, s.points))
+	}
+	return pwm(remap(p, 0, r, s.MinPulse, s.MaxPulse))
+}
+
 func remap(value, min, max, toMin, toMax float64) float64 {
 	return (value-min)/(max-min)*(toMax-toMin) + toMin
 }
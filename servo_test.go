@@ -4,6 +4,7 @@ package servo
 
 import (
 	"fmt"
+	"math"
 	"sync"
 	"testing"
 	"time"
@@ -58,7 +59,10 @@ func TestServo_Position(t *testing.T) {
 	defer s.Close()
 
 	const want = 59.6
+	s.lock.Lock()
 	s.position = want
+	s.publish()
+	s.lock.Unlock()
 	got := s.Position()
 	if got != want {
 		t.Errorf("positions do not match, got: %.2f, want: %.2f", got, want)
@@ -89,6 +93,25 @@ func TestServo_Position(t *testing.T) {
 	})
 }
 
+func TestServo_Position_locked(t *testing.T) {
+	s := New(99)
+	s.SetPosition(30)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	done := make(chan float64)
+	go func() { done <- s.Position() }()
+	select {
+	case got := <-done:
+		if got != 30 {
+			t.Errorf("positions do not match, got: %.2f, want: 30.00", got)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Position() blocked while the servo was locked")
+	}
+}
+
 func TestServo_MoveTo(t *testing.T) {
 	// map[input]want
 	tests := map[float64]float64{
@@ -360,3 +383,244 @@ func TestClamp(t *testing.T) {
 		}
 	}
 }
+
+func TestServo_LastPWM(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if _, at := s.LastPWM(); !at.IsZero() {
+		t.Errorf("pwm was written before moving, at: %v", at)
+	}
+
+	s.moveTo(180)
+	s.Wait()
+	time.Sleep(20 * time.Millisecond)
+	got, at := s.LastPWM()
+	if got != s.MaxPulse || at.IsZero() {
+		t.Errorf("last written pwm, got: %.6f at %v, want: %.6f", got, at, s.MaxPulse)
+	}
+
+	SetCanary(true)
+	defer SetCanary(false)
+	s.moveTo(0)
+	s.Wait()
+	time.Sleep(20 * time.Millisecond)
+	if got, _ := s.LastPWM(); got != s.MaxPulse {
+		t.Errorf("pwm was recorded in canary mode, got: %.6f, want: %.6f", got, s.MaxPulse)
+	}
+}
+
+func TestServo_MoveBy(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.MoveTo(90).Wait()
+	s.MoveBy(-30).Wait()
+	if got, want := s.Position(), 60.0; got != want {
+		t.Errorf("wrong position, got: %.2f, want: %.2f", got, want)
+	}
+
+	// Nudges add to the target of the current move.
+	s.SetSpeed(0.1)
+	s.MoveBy(10)
+	s.MoveBy(10).Wait()
+	if got, want := s.Position(), 80.0; got != want {
+		t.Errorf("wrong position, got: %.2f, want: %.2f", got, want)
+	}
+	s.SetSpeed(1)
+
+	s.MoveBy(500).Wait()
+	if got, want := s.Position(), 180.0; got != want {
+		t.Errorf("target was not clamped, got: %.2f, want: %.2f", got, want)
+	}
+
+	s.Flags = Centered | Normalized
+	s.MoveBy(-1).Wait()
+	if got, want := s.Position(), 0.0; got != want {
+		t.Errorf("wrong normalized position, got: %.2f, want: %.2f", got, want)
+	}
+}
+
+func TestServo_SetMaxSpeed(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.SetSpeed(0.5)
+	s.SetMaxSpeed(180)
+	if got, want := s.MaxSpeed(), 180.0; got != want {
+		t.Errorf("wrong max speed, got: %.2f, want: %.2f", got, want)
+	}
+	s.lock.RLock()
+	step := s.step
+	s.lock.RUnlock()
+	if step != 90 {
+		t.Errorf("speed fraction was not kept, got: %.2f°/s, want: 90.00°/s", step)
+	}
+
+	start := time.Now()
+	s.MoveTo(45).Wait()
+	elapsed := time.Since(start)
+	if want := 500 * time.Millisecond; elapsed < want-50*time.Millisecond || elapsed > want+50*time.Millisecond {
+		t.Errorf("wrong travel time, got: %v, want: %v", elapsed, want)
+	}
+
+	s.SetMaxSpeed(-1)
+	if got := s.MaxSpeed(); got != 0 {
+		t.Errorf("negative max speed, got: %.2f", got)
+	}
+}
+
+func TestServo_travelLimits(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	s.Flags = Centered
+	s.MinAngle, s.MaxAngle = 30, 150
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.MoveTo(-90).Wait()
+	if p := s.Position(); p != -60 {
+		t.Errorf("servo moved below its limit, got: %.2f, want: -60.00", p)
+	}
+	s.SetPosition(90)
+	s.Wait()
+	if p := s.Position(); p != 60 {
+		t.Errorf("servo jumped above its limit, got: %.2f, want: 60.00", p)
+	}
+
+	// Layers cannot push the servo past its limits either.
+	s.AddLayer(constMotion(20))
+	time.Sleep(100 * time.Millisecond)
+	want := pwm(remap(150, 0, 180, s.MinPulse, s.MaxPulse))
+	if got, _ := s.LastPWM(); pwm(got) != want {
+		t.Errorf("layer pushed the servo past its limit, got: %.4f, want: %.4f", got, want)
+	}
+}
+
+func TestServo_Reversed(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	s.Flags = Centered | Reversed
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.SetPosition(-90)
+	s.Wait()
+	time.Sleep(20 * time.Millisecond)
+	if got, _ := s.LastPWM(); pwm(got) != pwm(s.MaxPulse) {
+		t.Errorf("direction was not reversed, got: %.4f, want: %.4f", got, s.MaxPulse)
+	}
+	if p := s.Position(); p != -90 {
+		t.Errorf("reported angle was reversed, got: %.2f, want: -90.00", p)
+	}
+	if a := s.PulseAngle(2500 * time.Microsecond); a != -90 {
+		t.Errorf("wrong pulse angle, got: %.2f, want: -90.00", a)
+	}
+	if got, want := s.Flags.String(), "( Centered Reversed )"; got != want {
+		t.Errorf("wrong flags, got: %s, want: %s", got, want)
+	}
+
+	f, err := ServoConfig{Flags: []string{"Reversed"}}.flags()
+	if err != nil || f != Reversed {
+		t.Errorf("wrong config flags, got: %v, %v, want: %v", f, err, Reversed)
+	}
+}
+
+func TestServo_Range(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	s.Flags = Centered
+	s.Range = 270
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.SetPosition(135)
+	s.Wait()
+	time.Sleep(20 * time.Millisecond)
+	if got, _ := s.LastPWM(); pwm(got) != pwm(s.MaxPulse) {
+		t.Errorf("servo did not use its whole range, got: %.4f, want: %.4f", got, s.MaxPulse)
+	}
+	s.SetPosition(200)
+	s.Wait()
+	if p := s.Position(); p != 135 {
+		t.Errorf("servo moved past its range, got: %.2f, want: 135.00", p)
+	}
+	s.SetPosition(0)
+	s.Wait()
+	time.Sleep(20 * time.Millisecond)
+	if got, _ := s.LastPWM(); !approx(got, 0.15) {
+		t.Errorf("wrong center pulse, got: %.4f, want: 0.1500", got)
+	}
+	if a := s.PulseAngle(2500 * time.Microsecond); a != 135 {
+		t.Errorf("wrong pulse angle, got: %.2f, want: 135.00", a)
+	}
+
+	s.Flags = Centered | Normalized
+	if got := s.adjust(270); got != 1 {
+		t.Errorf("wrong normalized angle, got: %.2f, want: 1.00", got)
+	}
+}
+
+func TestServo_Radians(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	s.Flags = Centered | Radians
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.SetPosition(math.Pi / 2)
+	s.Wait()
+	time.Sleep(20 * time.Millisecond)
+	if got, _ := s.LastPWM(); !approx(got, s.MaxPulse) {
+		t.Errorf("wrong pulse, got: %.4f, want: %.4f", got, s.MaxPulse)
+	}
+	if p := s.Position(); !approx(p, math.Pi/2) {
+		t.Errorf("wrong position, got: %.4f, want: %.4f", p, math.Pi/2)
+	}
+	if a := s.PulseAngle(1500 * time.Microsecond); !approx(a, 0) {
+		t.Errorf("wrong pulse angle, got: %.4f, want: 0.0000", a)
+	}
+
+	s.Flags = Radians | Normalized
+	if got := s.adjust(180); got != 2 {
+		t.Errorf("Normalized does not take precedence, got: %.2f, want: 2.00", got)
+	}
+	if got, want := (Centered | Radians).String(), "( Centered Radians )"; got != want {
+		t.Errorf("wrong flags, got: %s, want: %s", got, want)
+	}
+	f, err := ServoConfig{Flags: []string{"radians"}}.flags()
+	if err != nil || f != Radians {
+		t.Errorf("wrong config flags, got: %v, %v, want: %v", f, err, Radians)
+	}
+}
@@ -0,0 +1,130 @@
+// Package servoblaster drives servos through the ServoBlaster daemon, for
+// users of that daemon instead of pi-blaster.
+//
+// Set a Controller as the backend of the servo package:
+//
+//	sb, err := servoblaster.Open(servoblaster.Device)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	sb.Header = "P1"
+//	servo.SetBackend(sb)
+//	defer servo.Close()
+//
+//	s := servo.New(11) // pin 11 of the P1 header
+package servoblaster
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+// Device is the default device of the ServoBlaster daemon.
+const Device = "/dev/servoblaster"
+
+// Controller is the ServoBlaster daemon. It implements the servo.Backend
+// interface. Use servoblaster.Open() or servoblaster.New() for correct
+// initialization.
+type Controller struct {
+	// Header selects how the pins of the servos are sent. If empty, the pin
+	// is the servo number of ServoBlaster ("2=1500us"). Otherwise, the pin
+	// is a pin of the header ("P1-11=1500us").
+	Header string
+
+	w io.Writer
+	// pins are the pins written since the last Close.
+	pins map[int]bool
+	lock *sync.Mutex
+}
+
+// Open opens the device of the ServoBlaster daemon at path.
+func Open(path string) (*Controller, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	return New(f), nil
+}
+
+// New creates a Controller that writes the commands of ServoBlaster to w.
+func New(w io.Writer) *Controller {
+	return &Controller{
+		w:    w,
+		pins: make(map[int]bool),
+		lock: new(sync.Mutex),
+	}
+}
+
+// Write implements the servo.Backend interface. All the pulses are sent in a
+// single write, one command per line.
+func (c *Controller) Write(pulses []servo.Pulse) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	s := new(strings.Builder)
+	for _, p := range pulses {
+		c.command(s, p.Pin, p.Width)
+		c.pins[p.Pin] = true
+	}
+
+	return c.write(s.String())
+}
+
+// Close implements the servo.Backend interface. It turns off the pins
+// written so far. If the writer is an io.Closer, it is closed too.
+func (c *Controller) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	pins := make([]int, 0, len(c.pins))
+	for pin := range c.pins {
+		pins = append(pins, pin)
+	}
+	sort.Ints(pins)
+
+	s := new(strings.Builder)
+	for _, pin := range pins {
+		c.command(s, pin, 0)
+	}
+	c.pins = make(map[int]bool)
+
+	var err error
+	if s.Len() > 0 {
+		err = c.write(s.String())
+	}
+	if closer, ok := c.w.(io.Closer); ok {
+		if cerr := closer.Close(); err == nil {
+			err = cerr
+		}
+	}
+
+	return err
+}
+
+// command writes the command that sets the pulse of pin to s. A width of 0
+// turns the pin off.
+func (c *Controller) command(s *strings.Builder, pin int, width time.Duration) {
+	if c.Header != "" {
+		fmt.Fprintf(s, "%s-", c.Header)
+	}
+	if width == 0 {
+		fmt.Fprintf(s, "%d=0\n", pin)
+		return
+	}
+	fmt.Fprintf(s, "%d=%dus\n", pin, width/time.Microsecond)
+}
+
+// write sends the commands to the daemon.
+func (c *Controller) write(s string) error {
+	if _, err := io.WriteString(c.w, s); err != nil {
+		return fmt.Errorf("servoblaster: %v", err)
+	}
+	return nil
+}
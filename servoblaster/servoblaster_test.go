@@ -0,0 +1,45 @@
+// +build !live
+
+package servoblaster
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+func TestController(t *testing.T) {
+	out := new(bytes.Buffer)
+	c := New(out)
+	c.Header = "P1"
+
+	err := c.Write([]servo.Pulse{
+		{Pin: 11, Width: 1500 * time.Microsecond},
+		{Pin: 12, Width: 0},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "P1-11=1500us\nP1-12=0\n"; got != want {
+		t.Errorf("wrong commands, got: %q, want: %q", got, want)
+	}
+
+	out.Reset()
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "P1-11=0\nP1-12=0\n"; got != want {
+		t.Errorf("pins were not released, got: %q, want: %q", got, want)
+	}
+
+	out.Reset()
+	c.Header = ""
+	if err := c.Write([]servo.Pulse{{Pin: 2, Width: time.Millisecond}}); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := out.String(), "2=1000us\n"; got != want {
+		t.Errorf("wrong servo number command, got: %q, want: %q", got, want)
+	}
+}
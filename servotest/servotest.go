@@ -0,0 +1,239 @@
+// Package servotest provides a fake backend for tests of code that uses the
+// servo package. The Recorder records every frame flushed by the manager, so
+// tests can check what would have been written to pi-blaster:
+//
+//	func TestWave(t *testing.T) {
+//		r := servotest.Install(t)
+//		servo.Rate(time.Millisecond)
+//
+//		arm := servo.New(17)
+//		if err := arm.Connect(); err != nil {
+//			t.Fatal(err)
+//		}
+//		defer arm.Close()
+//
+//		wave(arm)
+//		r.AssertPulse(t, 17, 1500*time.Microsecond, time.Second)
+//	}
+package servotest
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+// Period is the pwm period of pi-blaster, of which the pwm values are
+// fractions.
+const Period = 10 * time.Millisecond
+
+// Frame is a single write of the manager to the backend.
+type Frame struct {
+	// Time is when the frame was written.
+	Time time.Time
+	// Pulses are the pulses of the frame, sorted by pin.
+	Pulses []servo.Pulse
+}
+
+// Pulse returns the pulse of the pin in the frame, and whether the pin is in
+// the frame.
+func (f Frame) Pulse(pin int) (time.Duration, bool) {
+	for _, p := range f.Pulses {
+		if p.Pin == pin {
+			return p.Width, true
+		}
+	}
+	return 0, false
+}
+
+// PWM returns the pwm value of the pin in the frame as pi-blaster receives
+// it, from 0.0 to 1.0, and whether the pin is in the frame.
+func (f Frame) PWM(pin int) (float64, bool) {
+	width, ok := f.Pulse(pin)
+	return float64(width) / float64(Period), ok
+}
+
+// Recorder is a servo.Backend that records the frames written to it. Use
+// servotest.New() or servotest.Install() for correct initialization.
+type Recorder struct {
+	frames []Frame
+	closed bool
+	// changed is closed and replaced on every write.
+	changed chan struct{}
+	lock    *sync.Mutex
+}
+
+// New creates an empty Recorder.
+func New() *Recorder {
+	return &Recorder{
+		changed: make(chan struct{}),
+		lock:    new(sync.Mutex),
+	}
+}
+
+// Install creates a Recorder and sets it as the backend of the servo package
+// until the end of the test, when the default backend is restored.
+func Install(t testing.TB) *Recorder {
+	r := New()
+	servo.SetBackend(r)
+	t.Cleanup(func() { servo.SetBackend(nil) })
+	return r
+}
+
+// Write implements the servo.Backend interface.
+func (r *Recorder) Write(pulses []servo.Pulse) error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.frames = append(r.frames, Frame{
+		Time:   time.Now(),
+		Pulses: append([]servo.Pulse(nil), pulses...),
+	})
+	close(r.changed)
+	r.changed = make(chan struct{})
+
+	return nil
+}
+
+// Close implements the servo.Backend interface.
+func (r *Recorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.closed = true
+	return nil
+}
+
+// Closed checks if the backend was closed, e.g. by servo.Close().
+func (r *Recorder) Closed() bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.closed
+}
+
+// Frames returns a copy of the recorded frames, in order.
+func (r *Recorder) Frames() []Frame {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return append([]Frame(nil), r.frames...)
+}
+
+// History returns the frames that include the pin, in order.
+func (r *Recorder) History(pin int) []Frame {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	var frames []Frame
+	for _, f := range r.frames {
+		if _, ok := f.Pulse(pin); ok {
+			frames = append(frames, f)
+		}
+	}
+	return frames
+}
+
+// Last returns the last pulse written to the pin, and whether the pin was
+// written at all.
+func (r *Recorder) Last(pin int) (time.Duration, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	return r.last(pin)
+}
+
+// last returns the last pulse of the pin. It must be called with the lock
+// held.
+func (r *Recorder) last(pin int) (time.Duration, bool) {
+	for i := len(r.frames) - 1; i >= 0; i-- {
+		if width, ok := r.frames[i].Pulse(pin); ok {
+			return width, true
+		}
+	}
+	return 0, false
+}
+
+// Reset drops the recorded frames.
+func (r *Recorder) Reset() {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	r.frames = nil
+	r.closed = false
+}
+
+// WaitFor waits until the last pulse of the pin is within tolerance of want,
+// or until timeout. It returns the last pulse of the pin and whether it was
+// reached.
+func (r *Recorder) WaitFor(pin int, want, tolerance, timeout time.Duration) (time.Duration, bool) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+
+	for {
+		r.lock.Lock()
+		width, ok := r.last(pin)
+		changed := r.changed
+		r.lock.Unlock()
+
+		if ok && within(width, want, tolerance) {
+			return width, true
+		}
+		select {
+		case <-changed:
+		case <-deadline.C:
+			return width, false
+		}
+	}
+}
+
+// AssertPulse fails the test if the pulse of the pin does not reach want
+// within 1µs before timeout.
+func (r *Recorder) AssertPulse(t testing.TB, pin int, want, timeout time.Duration) {
+	t.Helper()
+	if got, ok := r.WaitFor(pin, want, time.Microsecond, timeout); !ok {
+		t.Errorf("pin %d: pulse %v, want: %v", pin, got, want)
+	}
+}
+
+// AssertReleased fails the test if the pin is not released (0 duty) before
+// timeout.
+func (r *Recorder) AssertReleased(t testing.TB, pin int, timeout time.Duration) {
+	t.Helper()
+	if got, ok := r.WaitFor(pin, 0, 0, timeout); !ok {
+		t.Errorf("pin %d: not released, pulse %v", pin, got)
+	}
+}
+
+// AssertNotWritten fails the test if the pin was ever written.
+func (r *Recorder) AssertNotWritten(t testing.TB, pin int) {
+	t.Helper()
+	if frames := r.History(pin); len(frames) > 0 {
+		width, _ := frames[len(frames)-1].Pulse(pin)
+		t.Errorf("pin %d: written %d times, last pulse %v", pin, len(frames), width)
+	}
+}
+
+// String implements the Stringer interface, listing one frame per line.
+func (r *Recorder) String() string {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	s := ""
+	for i, f := range r.frames {
+		s += fmt.Sprintf("%d: %s %v\n", i, f.Time.Format("15:04:05.000"), f.Pulses)
+	}
+	return s
+}
+
+// within checks if got is within tolerance of want.
+func within(got, want, tolerance time.Duration) bool {
+	d := got - want
+	if d < 0 {
+		d = -d
+	}
+	return d <= tolerance
+}
@@ -0,0 +1,62 @@
+// +build !live
+
+package servotest
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+func TestRecorder(t *testing.T) {
+	r := Install(t)
+	servo.Rate(time.Millisecond)
+
+	s := servo.New(21)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	s.SetSpeed(1)
+	s.MoveTo(90).Wait()
+
+	r.AssertPulse(t, 21, 1500*time.Microsecond, time.Second)
+	r.AssertNotWritten(t, 20)
+
+	frames := r.History(21)
+	if len(frames) < 2 {
+		t.Fatalf("the move was not recorded, got: %d frames", len(frames))
+	}
+	for i := 1; i < len(frames); i++ {
+		if frames[i].Time.Before(frames[i-1].Time) {
+			t.Errorf("frames out of order: %v before %v", frames[i].Time, frames[i-1].Time)
+		}
+	}
+	if pwm, ok := frames[len(frames)-1].PWM(21); !ok || math.Abs(pwm-0.15) > 0.001 {
+		t.Errorf("wrong pwm, got: %.6f, want: %.6f", pwm, 0.15)
+	}
+
+	s.Close()
+	r.AssertReleased(t, 21, time.Second)
+}
+
+func TestRecorder_WaitFor(t *testing.T) {
+	r := New()
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		r.Write([]servo.Pulse{{Pin: 1, Width: time.Millisecond}})
+	}()
+
+	if _, ok := r.WaitFor(1, time.Millisecond, 0, time.Second); !ok {
+		t.Error("pulse was not found")
+	}
+	if got, ok := r.WaitFor(1, 2*time.Millisecond, 0, 10*time.Millisecond); ok || got != time.Millisecond {
+		t.Errorf("wrong pulse was found, got: %v", got)
+	}
+
+	r.Reset()
+	if _, ok := r.Last(1); ok {
+		t.Error("frames were not reset")
+	}
+}
@@ -0,0 +1,67 @@
+package servo
+
+import "time"
+
+// Audio is the audio track of a Show. It is implemented by the audio player
+// of the application.
+type Audio interface {
+	// Play starts the playback from the beginning.
+	Play() error
+	// Position returns the current playback position of the audio.
+	Position() time.Duration
+	// Stop stops the playback.
+	Stop() error
+}
+
+// Show plays a timeline together with an audio track, keeping the servos in
+// sync with the audio.
+type Show struct {
+	// Audio is the audio track of the show.
+	Audio Audio
+	// Timeline is played together with the audio.
+	Timeline Timeline
+	// MaxDrift is how far the servo playback can drift from the audio position
+	// before it is resynced (default: 20ms).
+	MaxDrift time.Duration
+}
+
+// defaultMaxDrift is used when Show.MaxDrift is 0.
+const defaultMaxDrift = 20 * time.Millisecond
+
+// Play starts the audio and plays the timeline with it. It blocks until all
+// the cues were played, the servos finished moving, and then stops the audio.
+// Closing stop aborts the show.
+//
+// The timeline follows the playback clock (see servo.Now()), which is
+// smoother than the position reported by most audio players. Whenever the
+// clock drifts from the audio position by more than MaxDrift, the playback is
+// resynced to the audio: skipped cues are played immediately and cues already
+// played are not repeated.
+func (sh *Show) Play(stop <-chan struct{}) error {
+	maxDrift := sh.MaxDrift
+	if maxDrift == 0 {
+		maxDrift = defaultMaxDrift
+	}
+
+	if err := sh.Audio.Play(); err != nil {
+		return err
+	}
+	start := Now()
+
+	err := sh.Timeline.play(func() time.Duration {
+		pos := Now().Sub(start)
+		audio := sh.Audio.Position()
+		if drift := pos - audio; drift > maxDrift || drift < -maxDrift {
+			debugf("show drifted %v from the audio, resyncing", drift)
+			start = Now().Add(-audio)
+			pos = audio
+		}
+		return pos
+	}, stop)
+
+	if stopErr := sh.Audio.Stop(); err == nil {
+		err = stopErr
+	}
+
+	return err
+}
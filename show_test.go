@@ -0,0 +1,71 @@
+// +build !live
+
+package servo
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeAudio is an Audio that reports its position from the wall clock plus a
+// skew, to simulate drift.
+type fakeAudio struct {
+	start   time.Time
+	skew    time.Duration
+	playing bool
+	lock    sync.Mutex
+}
+
+func (a *fakeAudio) Play() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.start = time.Now()
+	a.playing = true
+	return nil
+}
+
+func (a *fakeAudio) Position() time.Duration {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return time.Since(a.start) + a.skew
+}
+
+func (a *fakeAudio) Stop() error {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.playing = false
+	return nil
+}
+
+func TestShow_Play(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	// The audio is one second ahead, so the show must resync and play the
+	// cue right away.
+	audio := &fakeAudio{skew: time.Second}
+	show := &Show{
+		Audio:    audio,
+		Timeline: Timeline{{At: 500 * time.Millisecond, Servo: s, Target: 10}},
+	}
+
+	start := time.Now()
+	if err := show.Play(nil); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Errorf("show did not resync to the audio, took: %v", elapsed)
+	}
+	if s.Position() != 10 {
+		t.Errorf("cue was not played, got: %.2f, want: %.2f", s.Position(), 10.0)
+	}
+	if audio.playing {
+		t.Error("audio was not stopped")
+	}
+}
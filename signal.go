@@ -0,0 +1,50 @@
+// +build !windows,!plan9
+
+package servo
+
+import (
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// HandleSignals makes the servo package handle SIGUSR1 and SIGUSR2 until stop
+// is called. This is useful for debugging installations in the field:
+//
+// SIGUSR1 enables debug logging for the duration d (see servo.Debug()).
+//
+// SIGUSR2 dumps the state of the servo package to the log (see
+// servo.DumpState()).
+//
+// For example, run `kill -USR2 <pid>` to inspect a running program.
+func HandleSignals(d time.Duration) (stop func()) {
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, syscall.SIGUSR1, syscall.SIGUSR2)
+
+	go func() {
+		for {
+			select {
+			case <-done:
+				return
+			case sig := <-c:
+				switch sig {
+				case syscall.SIGUSR1:
+					Debug(d)
+				case syscall.SIGUSR2:
+					s := new(strings.Builder)
+					DumpState(s)
+					log.Print(s)
+				}
+			}
+		}
+	}()
+
+	return func() {
+		signal.Stop(c)
+		close(done)
+	}
+}
@@ -0,0 +1,15 @@
+// +build windows plan9
+
+package servo
+
+import (
+	"log"
+	"time"
+)
+
+// HandleSignals does nothing on this system, since SIGUSR1 and SIGUSR2 are not
+// available.
+func HandleSignals(d time.Duration) (stop func()) {
+	log.Println("WARNING: servo.HandleSignals is not supported on this system")
+	return func() {}
+}
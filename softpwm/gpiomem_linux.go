@@ -0,0 +1,71 @@
+// +build linux
+
+package softpwm
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+	"unsafe"
+)
+
+const (
+	// gpioMemSize is the size of the GPIO registers mapped by /dev/gpiomem.
+	gpioMemSize = 4096
+
+	// Word offsets of the GPIO registers of the BCM2835 family.
+	regFSel = 0
+	regSet  = 7
+	regClr  = 10
+
+	// maxPin is the last GPIO pin of the BCM2835 family.
+	maxPin = 53
+)
+
+// gpioMem sets GPIO pins through the memory-mapped registers of /dev/gpiomem.
+type gpioMem struct {
+	mem  []byte
+	regs []uint32
+}
+
+// OpenGPIOMem maps the GPIO registers of a Raspberry Pi through /dev/gpiomem,
+// which does not require root.
+func OpenGPIOMem() (GPIO, error) {
+	f, err := os.OpenFile("/dev/gpiomem", os.O_RDWR|os.O_SYNC, 0)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	mem, err := syscall.Mmap(int(f.Fd()), 0, gpioMemSize, syscall.PROT_READ|syscall.PROT_WRITE, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, fmt.Errorf("softpwm: %v", err)
+	}
+
+	return &gpioMem{
+		mem:  mem,
+		regs: (*[gpioMemSize / 4]uint32)(unsafe.Pointer(&mem[0]))[:],
+	}, nil
+}
+
+// Output implements the GPIO interface.
+func (g *gpioMem) Output(pin int) error {
+	if pin < 0 || pin > maxPin {
+		return fmt.Errorf("softpwm: gpio(%d) is out of range", pin)
+	}
+
+	reg := regFSel + pin/10
+	shift := uint(pin%10) * 3
+	g.regs[reg] = g.regs[reg]&^(7<<shift) | 1<<shift
+
+	return nil
+}
+
+// Set implements the GPIO interface.
+func (g *gpioMem) Set(pin int, high bool) {
+	reg := regClr
+	if high {
+		reg = regSet
+	}
+	g.regs[reg+pin/32] = 1 << uint(pin%32)
+}
@@ -0,0 +1,10 @@
+// +build !linux
+
+package softpwm
+
+import "fmt"
+
+// OpenGPIOMem is only supported on Linux.
+func OpenGPIOMem() (GPIO, error) {
+	return nil, fmt.Errorf("softpwm: /dev/gpiomem is only supported on linux")
+}
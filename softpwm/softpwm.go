@@ -0,0 +1,197 @@
+// Package softpwm drives servos with software pwm generated by the package
+// itself, so servos can move without the pi-blaster daemon or any other
+// external program.
+//
+// The pulses are timed by a goroutine, so they have more jitter than the
+// pulses of pi-blaster or of hardware pwm. Prefer those for precise motion.
+//
+//	gpio, err := softpwm.OpenGPIOMem()
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	servo.SetBackend(softpwm.New(gpio, softpwm.Period))
+//	defer servo.Close()
+//
+//	s := servo.New(17)
+package softpwm
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+// Period is the default pwm period for servos.
+const Period = 20 * time.Millisecond
+
+// spin is how long before the end of a pulse the generator stops sleeping and
+// busy-waits, since sleeping is not precise enough.
+const spin = 200 * time.Microsecond
+
+// GPIO sets the level of the GPIO pins.
+type GPIO interface {
+	// Output configures the pin as an output.
+	Output(pin int) error
+	// Set sets the pin high or low.
+	Set(pin int, high bool)
+}
+
+// Controller generates software pwm on GPIO pins. It implements the
+// servo.Backend and servo.FrequencyBackend interfaces. Use softpwm.New() for
+// correct initialization.
+type Controller struct {
+	gpio GPIO
+
+	// period is guarded by the lock.
+	period time.Duration
+	pulses map[int]time.Duration
+	// outputs are the pins already configured as outputs.
+	outputs map[int]bool
+	lock    *sync.Mutex
+
+	done chan struct{}
+	ws   *sync.WaitGroup
+	once *sync.Once
+}
+
+// New starts generating software pwm with the given period on gpio.
+func New(gpio GPIO, period time.Duration) *Controller {
+	c := &Controller{
+		gpio:    gpio,
+		period:  period,
+		pulses:  make(map[int]time.Duration),
+		outputs: make(map[int]bool),
+		lock:    new(sync.Mutex),
+		done:    make(chan struct{}),
+		ws:      new(sync.WaitGroup),
+		once:    new(sync.Once),
+	}
+
+	c.ws.Add(1)
+	go c.generate()
+
+	return c
+}
+
+// Write implements the servo.Backend interface. The pulses are generated from
+// the next period.
+func (c *Controller) Write(pulses []servo.Pulse) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, p := range pulses {
+		if p.Width == 0 {
+			delete(c.pulses, p.Pin)
+			continue
+		}
+		if !c.outputs[p.Pin] {
+			if err := c.gpio.Output(p.Pin); err != nil {
+				return err
+			}
+			c.outputs[p.Pin] = true
+		}
+		c.pulses[p.Pin] = p.Width
+	}
+
+	return nil
+}
+
+// Frequency implements the servo.FrequencyBackend interface.
+func (c *Controller) Frequency() float64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return float64(time.Second) / float64(c.period)
+}
+
+// SetFrequency implements the servo.FrequencyBackend interface. The new
+// period starts after the current one. It returns an error wrapping
+// servo.ErrOutOfRange if hz is not positive.
+func (c *Controller) SetFrequency(hz float64) error {
+	if hz <= 0 {
+		return fmt.Errorf("softpwm: frequency %.1fHz is out of range: %w", hz, servo.ErrOutOfRange)
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.period = time.Duration(float64(time.Second) / hz)
+	return nil
+}
+
+// Close implements the servo.Backend interface. It stops the generator and
+// sets all the pins low.
+func (c *Controller) Close() error {
+	c.once.Do(func() {
+		close(c.done)
+		c.ws.Wait()
+
+		c.lock.Lock()
+		defer c.lock.Unlock()
+		for pin := range c.outputs {
+			c.gpio.Set(pin, false)
+		}
+		c.pulses = make(map[int]time.Duration)
+	})
+
+	return nil
+}
+
+// edge is the end of a pulse in a period.
+type edge struct {
+	pin   int
+	width time.Duration
+}
+
+// generate sets the pins with a pulse high at the start of each period and
+// low at the end of their pulses.
+func (c *Controller) generate() {
+	defer c.ws.Done()
+
+	next := time.Now()
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-time.After(time.Until(next)):
+		}
+
+		c.lock.Lock()
+		edges := make([]edge, 0, len(c.pulses))
+		for pin, width := range c.pulses {
+			edges = append(edges, edge{pin, width})
+		}
+		period := c.period
+		c.lock.Unlock()
+		sort.Slice(edges, func(i, j int) bool {
+			return edges[i].width < edges[j].width
+		})
+
+		start := time.Now()
+		for _, e := range edges {
+			c.gpio.Set(e.pin, true)
+		}
+		for _, e := range edges {
+			wait(start.Add(e.width))
+			c.gpio.Set(e.pin, false)
+		}
+
+		next = next.Add(period)
+		if now := time.Now(); next.Before(now) {
+			// The generator fell behind, skip the lost periods.
+			next = now
+		}
+	}
+}
+
+// wait sleeps until shortly before t and then busy-waits until t.
+func wait(t time.Time) {
+	if d := time.Until(t) - spin; d > 0 {
+		time.Sleep(d)
+	}
+	for time.Now().Before(t) {
+	}
+}
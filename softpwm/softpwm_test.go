@@ -0,0 +1,117 @@
+// +build !live
+
+package softpwm
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+// fakeGPIO records how long each pin was high.
+type fakeGPIO struct {
+	outputs map[int]bool
+	high    map[int]time.Time
+	widths  map[int][]time.Duration
+	lock    sync.Mutex
+}
+
+func newFakeGPIO() *fakeGPIO {
+	return &fakeGPIO{
+		outputs: make(map[int]bool),
+		high:    make(map[int]time.Time),
+		widths:  make(map[int][]time.Duration),
+	}
+}
+
+func (g *fakeGPIO) Output(pin int) error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	g.outputs[pin] = true
+	return nil
+}
+
+func (g *fakeGPIO) Set(pin int, high bool) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	if high {
+		g.high[pin] = time.Now()
+		return
+	}
+	if t, ok := g.high[pin]; ok {
+		g.widths[pin] = append(g.widths[pin], time.Since(t))
+		delete(g.high, pin)
+	}
+}
+
+func TestController(t *testing.T) {
+	g := newFakeGPIO()
+	c := New(g, 10*time.Millisecond)
+
+	err := c.Write([]servo.Pulse{
+		{Pin: 17, Width: 2 * time.Millisecond},
+		{Pin: 18, Width: time.Millisecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	c.Write([]servo.Pulse{{Pin: 18, Width: 0}})
+	time.Sleep(30 * time.Millisecond)
+	c.Close()
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	if !g.outputs[17] || !g.outputs[18] {
+		t.Error("pins were not configured as outputs")
+	}
+	for pin, want := range map[int]time.Duration{17: 2 * time.Millisecond, 18: time.Millisecond} {
+		widths := g.widths[pin]
+		if len(widths) < 5 {
+			t.Errorf("gpio(%d) got too few pulses: %d", pin, len(widths))
+			continue
+		}
+		for _, w := range widths {
+			if w < want || w > want+5*time.Millisecond {
+				t.Errorf("gpio(%d) wrong pulse, got: %v, want: %v", pin, w, want)
+			}
+		}
+	}
+	if len(g.widths[17]) <= len(g.widths[18]) {
+		t.Error("released pin kept pulsing")
+	}
+	if len(g.high) != 0 {
+		t.Errorf("pins left high after Close: %v", g.high)
+	}
+}
+
+func TestController_SetFrequency(t *testing.T) {
+	g := newFakeGPIO()
+	c := New(g, Period)
+	defer c.Close()
+
+	if err := c.SetFrequency(200); err != nil {
+		t.Fatal(err)
+	}
+	if f := c.Frequency(); f != 200 {
+		t.Errorf("wrong frequency, got: %.1fHz, want: 200.0Hz", f)
+	}
+	if err := c.SetFrequency(0); err == nil {
+		t.Error("invalid frequency was accepted")
+	}
+
+	// Wait for the current period of 20ms to end.
+	time.Sleep(30 * time.Millisecond)
+	c.Write([]servo.Pulse{{Pin: 17, Width: time.Millisecond}})
+	time.Sleep(100 * time.Millisecond)
+
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	// 20 periods of 5ms, instead of 5 periods of 20ms.
+	if n := len(g.widths[17]); n < 12 {
+		t.Errorf("period was not changed, got %d pulses in 100ms", n)
+	}
+}
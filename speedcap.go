@@ -0,0 +1,54 @@
+package servo
+
+import (
+	"math"
+	"sync/atomic"
+)
+
+// speedCap is the bits of the fraction of the max speed that no servo can
+// exceed. It is accessed atomically.
+var speedCap = math.Float64bits(1)
+
+// SetSpeedCap caps the speed of every servo to the fraction, from 0.0 to 1.0,
+// of its max speed, e.g. as a safe mode during development or when children
+// are near an exhibit. The cap is enforced below the speed set by SetSpeed(),
+// so no call can exceed it, and it also applies to the moves in progress. A
+// cap of 0.0 freezes the moves in progress until the cap is raised, and new
+// moves are ignored. The default cap of 1.0 disables it.
+func SetSpeedCap(fraction float64) {
+	atomic.StoreUint64(&speedCap, math.Float64bits(clamp(fraction, 0, 1)))
+}
+
+// SpeedCap returns the fraction of the max speed that no servo can exceed.
+func SpeedCap() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&speedCap))
+}
+
+// speed returns the effective speed of the current move of the servo in
+// degrees per second. See speedOf(). It must be called with the servo locked.
+func (s *Servo) speed() float64 {
+	return s.speedOf(s.span)
+}
+
+// speedOf returns the effective speed of the move m of the servo in degrees
+// per second, which is its speed limited by the speed of the move (see
+// WithSpeed()), the speed cap and the cap of the servo. It must be called with
+// the servo locked.
+func (s *Servo) speedOf(m *Move) float64 {
+	step := s.step
+	if m != nil && m.hasSpeed {
+		step = math.Min(step, s.maxStep*m.moveSpeed)
+	}
+	return s.capSpeed(math.Min(step, s.maxStep*SpeedCap()))
+}
+
+// WithSpeed limits the speed of the move to the percentage, from 0.0 to 1.0,
+// of the max speed of the servo, without changing the speed of the servo
+// itself, e.g. for a client that shares the servo with its owner. The move is
+// never faster than the speed set by SetSpeed().
+func WithSpeed(percentage float64) MoveOption {
+	return func(m *Move) {
+		m.moveSpeed = clamp(percentage, 0, 1)
+		m.hasSpeed = true
+	}
+}
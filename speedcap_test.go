@@ -0,0 +1,38 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSetSpeedCap(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+	SetSpeedCap(0.5)
+	defer SetSpeedCap(1)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetSpeed(1)
+
+	const degrees = 45.0
+	start := time.Now()
+	s.MoveTo(degrees).Wait()
+	elapsed := time.Since(start)
+
+	want := time.Duration(degrees / (s.maxStep * 0.5) * float64(time.Second))
+	const tolerance = 30 * time.Millisecond
+	if elapsed < want-tolerance || elapsed > want+tolerance {
+		t.Errorf("capped move took %v, want: %v", elapsed, want)
+	}
+
+	SetSpeedCap(2)
+	if got := SpeedCap(); got != 1 {
+		t.Errorf("cap was not clamped, got: %.2f, want: %.2f", got, 1.0)
+	}
+}
@@ -0,0 +1,108 @@
+package servo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Store persists the data of the servo package, such as calibrations, by key.
+// Implement Store to keep the data somewhere else than files, e.g. on a tmpfs
+// journal or a remote key-value store.
+type Store interface {
+	// Load returns the data saved under key. If nothing was saved under key,
+	// Load returns nil data and no error.
+	Load(key string) ([]byte, error)
+	// Save replaces the data saved under key.
+	Save(key string, data []byte) error
+}
+
+// FileStore is a Store that saves each key as a JSON file inside a directory.
+type FileStore struct {
+	// Dir is the directory of the files. It is created on the first Save.
+	Dir string
+}
+
+// path returns the file where key is saved.
+func (f FileStore) path(key string) string {
+	return filepath.Join(f.Dir, key+".json")
+}
+
+// Load implements the Store interface.
+func (f FileStore) Load(key string) ([]byte, error) {
+	data, err := ioutil.ReadFile(f.path(key))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Save implements the Store interface. The file is replaced atomically, so a
+// power loss never leaves it half written.
+func (f FileStore) Save(key string, data []byte) error {
+	if err := os.MkdirAll(f.Dir, 0755); err != nil {
+		return err
+	}
+
+	tmp, err := ioutil.TempFile(f.Dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), f.path(key))
+}
+
+// MemoryStore is a Store that keeps the data in memory. It is useful for
+// tests and for read-only systems that do not need to persist anything. Use
+// servo.NewMemoryStore() for correct initialization.
+type MemoryStore struct {
+	data map[string][]byte
+	lock *sync.RWMutex
+}
+
+// NewMemoryStore creates an empty MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		data: make(map[string][]byte),
+		lock: new(sync.RWMutex),
+	}
+}
+
+// Load implements the Store interface.
+func (m *MemoryStore) Load(key string) ([]byte, error) {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	data, ok := m.data[key]
+	if !ok {
+		return nil, nil
+	}
+	return append([]byte(nil), data...), nil
+}
+
+// Save implements the Store interface.
+func (m *MemoryStore) Save(key string, data []byte) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	m.data[key] = append([]byte(nil), data...)
+
+	return nil
+}
@@ -0,0 +1,53 @@
+// +build !live
+
+package servo
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStore(t *testing.T) {
+	dir, err := ioutil.TempDir("", "servo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	stores := map[string]Store{
+		"FileStore":   FileStore{Dir: filepath.Join(dir, "state")},
+		"MemoryStore": NewMemoryStore(),
+	}
+
+	for name, st := range stores {
+		t.Run(name, func(t *testing.T) {
+			data, err := st.Load("missing")
+			if err != nil || data != nil {
+				t.Errorf("Load(missing) -> got: %q, %v, want: nil, nil", data, err)
+			}
+
+			for _, want := range []string{`{"a":1}`, `{"b":2}`} {
+				if err := st.Save("key", []byte(want)); err != nil {
+					t.Fatal(err)
+				}
+				got, err := st.Load("key")
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != want {
+					t.Errorf("Load(key) -> got: %q, want: %q", got, want)
+				}
+			}
+		})
+	}
+
+	files, err := ioutil.ReadDir(filepath.Join(dir, "state"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 || files[0].Name() != "key.json" {
+		t.Errorf("FileStore left extra files behind: %v", files)
+	}
+}
@@ -0,0 +1,59 @@
+package servo
+
+import (
+	"math"
+	"time"
+)
+
+// Sweep oscillates the servo between the angles from and to, adjusted for its
+// Flags, until it is stopped or another move overrides it, e.g. for scanning
+// sensors. The servo first eases to from, and then sweeps to to and back
+// along a sine every period. If the period is too short for the speed of the
+// servo, the servo sweeps slower. Changes of the speed while sweeping, e.g. by
+// SetSpeed() or SoftStop(), slow down or speed up the sweep as well.
+//
+// The returned Move never finishes by itself, so it ends as stopped, canceled
+// or replaced. Servo.Wait() waits until the sweep ends as well.
+func (s *Servo) Sweep(from, to float64, period time.Duration, opts ...MoveOption) *Move {
+	a, b := s.limit(s.raw(from)), s.limit(s.raw(to))
+	opts = append(opts, func(m *Move) {
+		m.planner = func(start, speed float64) profile {
+			return planSweep(start, a, b, period.Seconds(), speed)
+		}
+	})
+	return s.moveToWith(from, nil, opts...)
+}
+
+// sweep is an endless oscillation between the raw positions from and to, after
+// easing from start to from.
+type sweep struct {
+	start, from, to float64
+	// approach is the time to ease to from, and period the time of a full
+	// oscillation, in seconds.
+	approach, period float64
+}
+
+// planSweep plans a sweep from the raw position start, between the raw
+// positions from and to every period seconds, at up to speed.
+func planSweep(start, from, to, period, speed float64) sweep {
+	sw := sweep{start: start, from: from, to: to, period: period}
+	// The speed of a sine peaks at its middle.
+	if fastest := math.Pi * math.Abs(to-from) / speed; sw.period < fastest {
+		sw.period = fastest
+	}
+	sw.approach = math.Abs(from-start) / speed * slope(EaseSine)
+	return sw
+}
+
+func (sw sweep) duration() float64 { return math.Inf(1) }
+
+func (sw sweep) at(t float64) float64 {
+	if t < sw.approach {
+		return sw.start + (sw.from-sw.start)*EaseSine(t/sw.approach)
+	}
+	if sw.period == 0 {
+		return sw.from
+	}
+	u := (t - sw.approach) / sw.period
+	return sw.from + (sw.to-sw.from)*(1-math.Cos(2*math.Pi*u))/2
+}
@@ -0,0 +1,65 @@
+// +build !live
+
+package servo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestPlanSweep(t *testing.T) {
+	sw := planSweep(90, 0, 180, 1, 180)
+	// Sweeping 180 degrees along a sine peaks at π times 180°/s every second.
+	if math.Abs(sw.period-math.Pi) > 1e-9 {
+		t.Errorf("sweep was not slowed down, got period: %.3fs, want: %.3fs", sw.period, math.Pi)
+	}
+	if want := 0.5 * math.Pi / 2; math.Abs(sw.approach-want) > 0.01 {
+		t.Errorf("wrong approach, got: %.3fs, want: %.3fs", sw.approach, want)
+	}
+
+	tests := []struct{ t, want float64 }{
+		{0, 90},
+		{sw.approach, 0},
+		{sw.approach + sw.period/2, 180},
+		{sw.approach + sw.period, 0},
+		{sw.approach + 10.25*sw.period, 90},
+	}
+	for _, tt := range tests {
+		if got := sw.at(tt.t); math.Abs(got-tt.want) > 1e-6 {
+			t.Errorf("wrong position at %.3fs, got: %.2f, want: %.2f", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestServo_Sweep(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetPosition(0)
+	s.Wait()
+
+	m := s.Sweep(0, 90, time.Second)
+	time.Sleep(500 * time.Millisecond)
+	if p := s.Position(); p < 80 {
+		t.Errorf("servo did not sweep, got: %.2f, want: about 90.00", p)
+	}
+	time.Sleep(500 * time.Millisecond)
+	if p := s.Position(); p > 10 {
+		t.Errorf("servo did not sweep back, got: %.2f, want: about 0.00", p)
+	}
+
+	select {
+	case <-m.Done():
+		t.Fatal("sweep ended by itself")
+	default:
+	}
+	s.Stop()
+	if got := m.Result(); got != MoveStopped {
+		t.Errorf("wrong outcome, got: %v, want: %v", got, MoveStopped)
+	}
+}
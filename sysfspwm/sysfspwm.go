@@ -0,0 +1,237 @@
+// Package sysfspwm drives servos with the hardware pwm of Linux, through the
+// sysfs interface at /sys/class/pwm. With only one or two servos, hardware pwm
+// gives jitter-free pulses and does not need the pi-blaster daemon.
+//
+// On a Raspberry Pi, enable the pwm overlay (e.g. dtoverlay=pwm-2chan in
+// /boot/config.txt), set a Controller as the backend of the servo package and
+// use the pwm channel as the pin of the servo:
+//
+//	pwm, err := sysfspwm.Open(0)
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	servo.SetBackend(pwm)
+//	defer servo.Close()
+//
+//	s := servo.New(0) // pwm0 of pwmchip0, GPIO18 with pwm-2chan
+package sysfspwm
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+// Period is the default pwm period for servos.
+const Period = 20 * time.Millisecond
+
+// exportTimeout is how long to wait for the kernel to create the directory of
+// an exported channel.
+const exportTimeout = time.Second
+
+// Controller is a pwm chip of the sysfs interface. It implements the
+// servo.Backend and servo.FrequencyBackend interfaces. Use sysfspwm.Open() or
+// sysfspwm.New() for correct initialization.
+type Controller struct {
+	dir    string
+	period time.Duration
+	// enabled tracks the channels exported and enabled by the controller,
+	// and widths their pulses.
+	enabled map[int]bool
+	widths  map[int]time.Duration
+	lock    *sync.Mutex
+}
+
+// Open opens /sys/class/pwm/pwmchip<chip> with the default period for
+// servos.
+func Open(chip int) (*Controller, error) {
+	return New(fmt.Sprintf("/sys/class/pwm/pwmchip%d", chip), Period)
+}
+
+// New opens the pwm chip at the sysfs directory dir with the given pwm
+// period.
+func New(dir string, period time.Duration) (*Controller, error) {
+	if _, err := os.Stat(filepath.Join(dir, "export")); err != nil {
+		return nil, fmt.Errorf("sysfspwm: %v", err)
+	}
+
+	return &Controller{
+		dir:     dir,
+		period:  period,
+		enabled: make(map[int]bool),
+		widths:  make(map[int]time.Duration),
+		lock:    new(sync.Mutex),
+	}, nil
+}
+
+// Write implements the servo.Backend interface. The pin of each pulse is the
+// channel of the pwm chip.
+func (c *Controller) Write(pulses []servo.Pulse) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for _, p := range pulses {
+		if p.Width > c.period {
+			return fmt.Errorf("sysfspwm: pulse %v is longer than the period %v: %w", p.Width, c.period, servo.ErrOutOfRange)
+		}
+		if p.Width == 0 {
+			if c.enabled[p.Pin] {
+				if err := c.set(p.Pin, "enable", 0); err != nil {
+					return err
+				}
+				c.enabled[p.Pin] = false
+			}
+			continue
+		}
+
+		if !c.enabled[p.Pin] {
+			if err := c.enable(p.Pin, p.Width); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := c.set(p.Pin, "duty_cycle", int64(p.Width)); err != nil {
+			return err
+		}
+		c.widths[p.Pin] = p.Width
+	}
+
+	return nil
+}
+
+// Frequency implements the servo.FrequencyBackend interface.
+func (c *Controller) Frequency() float64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	return float64(time.Second) / float64(c.period)
+}
+
+// SetFrequency implements the servo.FrequencyBackend interface. It sets the
+// period of the enabled channels, keeping their pulse widths. It returns an
+// error wrapping servo.ErrOutOfRange if a pulse is longer than the new period.
+func (c *Controller) SetFrequency(hz float64) error {
+	if hz <= 0 {
+		return fmt.Errorf("sysfspwm: frequency %.1fHz is out of range: %w", hz, servo.ErrOutOfRange)
+	}
+	period := time.Duration(float64(time.Second) / hz)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	channels := make([]int, 0, len(c.enabled))
+	for ch, enabled := range c.enabled {
+		if !enabled {
+			continue
+		}
+		if w := c.widths[ch]; w > period {
+			return fmt.Errorf("sysfspwm: pulse %v is longer than the period %v: %w", w, period, servo.ErrOutOfRange)
+		}
+		channels = append(channels, ch)
+	}
+	sort.Ints(channels)
+
+	// The duty cycles fit in both periods, so they are kept as they are.
+	c.period = period
+	for _, ch := range channels {
+		if err := c.set(ch, "period", int64(period)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// enable exports the channel if needed, and sets its period and first pulse
+// before enabling it.
+func (c *Controller) enable(ch int, width time.Duration) error {
+	dir := c.channel(ch)
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		if err := c.write(filepath.Join(c.dir, "export"), int64(ch)); err != nil {
+			return err
+		}
+		// The kernel creates the directory of the channel asynchronously.
+		deadline := time.Now().Add(exportTimeout)
+		for {
+			if _, err := os.Stat(dir); err == nil {
+				break
+			}
+			if time.Now().After(deadline) {
+				return fmt.Errorf("sysfspwm: channel %d was not exported", ch)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+
+	// The duty cycle cannot be longer than the period, so the period is set
+	// first.
+	for _, attr := range []struct {
+		name  string
+		value int64
+	}{
+		{"period", int64(c.period)},
+		{"duty_cycle", int64(width)},
+		{"enable", 1},
+	} {
+		if err := c.set(ch, attr.name, attr.value); err != nil {
+			return err
+		}
+	}
+	c.enabled[ch] = true
+	c.widths[ch] = width
+
+	return nil
+}
+
+// Close implements the servo.Backend interface. It disables and unexports
+// the channels enabled by the controller.
+func (c *Controller) Close() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	channels := make([]int, 0, len(c.enabled))
+	for ch := range c.enabled {
+		channels = append(channels, ch)
+	}
+	sort.Ints(channels)
+
+	var first error
+	for _, ch := range channels {
+		err := c.set(ch, "enable", 0)
+		if err == nil {
+			err = c.write(filepath.Join(c.dir, "unexport"), int64(ch))
+		}
+		if err != nil && first == nil {
+			first = err
+		}
+		delete(c.enabled, ch)
+		delete(c.widths, ch)
+	}
+
+	return first
+}
+
+// channel returns the directory of the channel ch.
+func (c *Controller) channel(ch int) string {
+	return filepath.Join(c.dir, fmt.Sprintf("pwm%d", ch))
+}
+
+// set writes the value to the attribute of the channel ch.
+func (c *Controller) set(ch int, attr string, value int64) error {
+	return c.write(filepath.Join(c.channel(ch), attr), value)
+}
+
+// write writes the value to the sysfs file at path.
+func (c *Controller) write(path string, value int64) error {
+	if err := ioutil.WriteFile(path, []byte(strconv.FormatInt(value, 10)), 0644); err != nil {
+		return fmt.Errorf("sysfspwm: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+// +build !live
+
+package sysfspwm
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/cgxeiji/servo"
+)
+
+// fakeChip creates a sysfs pwm chip directory with the channel pwm0 already
+// exported.
+func fakeChip(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "pwmchip")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	for _, f := range []string{"export", "unexport"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, f), nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(dir, "pwm0"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	return dir
+}
+
+// read returns the content of the sysfs file at path.
+func read(t *testing.T, path ...string) string {
+	b, err := ioutil.ReadFile(filepath.Join(path...))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+func TestController(t *testing.T) {
+	dir := fakeChip(t)
+	c, err := New(dir, Period)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Write([]servo.Pulse{{Pin: 0, Width: 1500 * time.Microsecond}}); err != nil {
+		t.Fatal(err)
+	}
+	for attr, want := range map[string]string{
+		"period":     "20000000",
+		"duty_cycle": "1500000",
+		"enable":     "1",
+	} {
+		if got := read(t, dir, "pwm0", attr); got != want {
+			t.Errorf("wrong %s, got: %s, want: %s", attr, got, want)
+		}
+	}
+
+	if err := c.Write([]servo.Pulse{{Pin: 0, Width: time.Millisecond}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(t, dir, "pwm0", "duty_cycle"); got != "1000000" {
+		t.Errorf("duty cycle was not updated, got: %s", got)
+	}
+
+	if err := c.Write([]servo.Pulse{{Pin: 0, Width: time.Second}}); !errors.Is(err, servo.ErrOutOfRange) {
+		t.Errorf("long pulse, got: %v, want: %v", err, servo.ErrOutOfRange)
+	}
+
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(t, dir, "pwm0", "enable"); got != "0" {
+		t.Errorf("channel was not disabled, got: %s", got)
+	}
+	if got := read(t, dir, "unexport"); got != "0" {
+		t.Errorf("channel was not unexported, got: %q", got)
+	}
+}
+
+func TestController_Export(t *testing.T) {
+	dir := fakeChip(t)
+	c, err := New(dir, Period)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Simulate the kernel creating the directory after the export.
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		os.Mkdir(filepath.Join(dir, "pwm1"), 0755)
+	}()
+	if err := c.Write([]servo.Pulse{{Pin: 1, Width: time.Millisecond}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(t, dir, "export"); got != "1" {
+		t.Errorf("channel was not exported, got: %q", got)
+	}
+
+	if _, err := New(filepath.Join(dir, "missing"), Period); err == nil {
+		t.Error("missing chip did not fail")
+	}
+}
+
+func TestController_SetFrequency(t *testing.T) {
+	dir := fakeChip(t)
+	c, err := New(dir, Period)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	if err := c.Write([]servo.Pulse{{Pin: 0, Width: 1500 * time.Microsecond}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SetFrequency(1000); !errors.Is(err, servo.ErrOutOfRange) {
+		t.Errorf("pulse longer than the period, got: %v, want: %v", err, servo.ErrOutOfRange)
+	}
+
+	if err := c.SetFrequency(250); err != nil {
+		t.Fatal(err)
+	}
+	if got := read(t, dir, "pwm0", "period"); got != "4000000" {
+		t.Errorf("period was not updated, got: %s", got)
+	}
+	if got := read(t, dir, "pwm0", "duty_cycle"); got != "1500000" {
+		t.Errorf("duty cycle changed, got: %s", got)
+	}
+	if f := c.Frequency(); f != 250 {
+		t.Errorf("wrong frequency, got: %.1fHz, want: 250.0Hz", f)
+	}
+}
@@ -0,0 +1,160 @@
+package servo
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// Tag identifies an ad-hoc set of servos for bulk operations, e.g.
+// "left-arm" or "critical". A servo can have any number of tags, and the
+// operations of a Tag apply to the connected servos that have it at the time
+// of the call:
+//
+//	servo.Tag("left-arm").Stop()
+type Tag string
+
+// tagSet is the set of tags of a servo. It is never modified once stored.
+type tagSet map[Tag]bool
+
+// AddTags adds the tags to the servo.
+func (s *Servo) AddTags(tags ...Tag) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	set := s.tagSet()
+	next := make(tagSet, len(set)+len(tags))
+	for t := range set {
+		next[t] = true
+	}
+	for _, t := range tags {
+		next[t] = true
+	}
+	s.tags.Store(next)
+}
+
+// RemoveTags removes the tags from the servo.
+func (s *Servo) RemoveTags(tags ...Tag) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	set := s.tagSet()
+	next := make(tagSet, len(set))
+	for t := range set {
+		next[t] = true
+	}
+	for _, t := range tags {
+		delete(next, t)
+	}
+	s.tags.Store(next)
+}
+
+// Tags returns the tags of the servo, sorted.
+func (s *Servo) Tags() []Tag {
+	set := s.tagSet()
+	tags := make([]Tag, 0, len(set))
+	for t := range set {
+		tags = append(tags, t)
+	}
+	sort.Slice(tags, func(i, j int) bool { return tags[i] < tags[j] })
+	return tags
+}
+
+// HasTag checks if the servo has the tag. It does not lock the servo, so it
+// can be called from anywhere, e.g. by the event bus.
+func (s *Servo) HasTag(t Tag) bool {
+	return s.tagSet()[t]
+}
+
+// tagSet returns the current tags of the servo.
+func (s *Servo) tagSet() tagSet {
+	set, _ := s.tags.Load().(tagSet)
+	return set
+}
+
+// SetSpeedCap caps the speed of the servo to the fraction, from 0.0 to 1.0,
+// of its max speed, below the cap of servo.SetSpeedCap(), which still
+// applies. A cap of 1.0 removes the cap of the servo.
+func (s *Servo) SetSpeedCap(fraction float64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	s.speedCap = clamp(fraction, 0, 1)
+	s.capped = s.speedCap < 1
+}
+
+// Servos returns the connected servos with the tag, sorted by pin.
+func (t Tag) Servos() []*Servo {
+	var servos []*Servo
+	for _, s := range _blaster.connected() {
+		if s.HasTag(t) {
+			servos = append(servos, s)
+		}
+	}
+	return servos
+}
+
+// Stop stops the servos with the tag instantly.
+func (t Tag) Stop() {
+	for _, s := range t.Servos() {
+		s.Stop()
+	}
+}
+
+// SoftStop decelerates the servos with the tag to a stop within d. See
+// Servo.SoftStop(). The returned Waiter waits until all of them have
+// stopped.
+func (t Tag) SoftStop(d time.Duration) (wait Waiter) {
+	w := new(waitAll)
+	for _, s := range t.Servos() {
+		w.add(s.SoftStop(d))
+	}
+	return w
+}
+
+// Detach stops the servos with the tag and releases their pins (0 duty),
+// so they can be moved by hand. See Servo.Detach().
+func (t Tag) Detach() {
+	for _, s := range t.Servos() {
+		s.Detach()
+	}
+}
+
+// Attach drives the servos with the tag again after Detach(). See
+// Servo.Attach().
+func (t Tag) Attach() {
+	for _, s := range t.Servos() {
+		s.Attach()
+	}
+}
+
+// SetSpeed sets the speed of the servos with the tag. See Servo.SetSpeed().
+func (t Tag) SetSpeed(percentage float64) {
+	for _, s := range t.Servos() {
+		s.SetSpeed(percentage)
+	}
+}
+
+// SetSpeedCap caps the speed of the servos with the tag. See
+// Servo.SetSpeedCap().
+func (t Tag) SetSpeedCap(fraction float64) {
+	for _, s := range t.Servos() {
+		s.SetSpeedCap(fraction)
+	}
+}
+
+// Subscribe subscribes to the events of the servos with the tag, and to the
+// system events, as servo.Subscribe() does. The tag is checked when each
+// event is sent, so the subscription follows the servos tagged later.
+func (t Tag) Subscribe(size int, types EventType) *Subscription {
+	return _blaster.bus.subscribeTag(size, types, t)
+}
+
+// capSpeed limits the speed to the cap of the servo. It must be called with
+// the servo locked.
+func (s *Servo) capSpeed(speed float64) float64 {
+	if !s.capped {
+		return speed
+	}
+	return math.Min(speed, s.maxStep*s.speedCap)
+}
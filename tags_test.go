@@ -0,0 +1,107 @@
+// +build !live
+
+package servo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestServo_Tags(t *testing.T) {
+	s := New(99)
+	if got := s.Tags(); len(got) != 0 {
+		t.Errorf("new servo has tags: %v", got)
+	}
+
+	s.AddTags("left-arm", "critical")
+	s.RemoveTags("critical", "unknown")
+	s.AddTags("base")
+	if got, want := s.Tags(), []Tag{"base", "left-arm"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("wrong tags, got: %v, want: %v", got, want)
+	}
+	if !s.HasTag("left-arm") || s.HasTag("critical") {
+		t.Error("HasTag does not match the tags")
+	}
+}
+
+func TestTag(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	left, right := New(98), New(99)
+	left.AddTags("left-arm")
+	for _, s := range []*Servo{left, right} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+	}
+
+	if got := Tag("left-arm").Servos(); len(got) != 1 || got[0] != left {
+		t.Errorf("wrong servos, got: %v", got)
+	}
+
+	sub := Tag("left-arm").Subscribe(10, EventMove)
+	defer sub.Close()
+
+	left.MoveTo(180)
+	right.MoveTo(180)
+	select {
+	case e := <-sub.C:
+		if e.Servo != left {
+			t.Errorf("event of an untagged servo: %v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event of the tagged servo was not received")
+	}
+
+	Tag("left-arm").Stop()
+	if !left.isIdle() || right.isIdle() {
+		t.Error("Stop did not stop only the tagged servo")
+	}
+	right.Stop()
+
+	// A servo tagged later is part of the subscription.
+	right.AddTags("left-arm")
+	right.MoveTo(0)
+	select {
+	case e := <-sub.C:
+		if e.Servo != right {
+			t.Errorf("wrong event: %v", e)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("event of the servo tagged later was not received")
+	}
+	right.Stop()
+}
+
+func TestTag_SetSpeedCap(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	s.AddTags("slow")
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	Tag("slow").SetSpeedCap(0.5)
+	const degrees = 45.0
+	start := time.Now()
+	s.MoveTo(degrees).Wait()
+	elapsed := time.Since(start)
+
+	want := time.Duration(degrees / (s.maxStep * 0.5) * float64(time.Second))
+	const tolerance = 30 * time.Millisecond
+	if elapsed < want-tolerance || elapsed > want+tolerance {
+		t.Errorf("capped move took %v, want: %v", elapsed, want)
+	}
+
+	Tag("slow").Detach()
+	time.Sleep(20 * time.Millisecond)
+	if pwm, _ := s.LastPWM(); pwm != 0 {
+		t.Errorf("servo was not detached, pwm: %.6f", pwm)
+	}
+}
@@ -0,0 +1,94 @@
+package servo
+
+import (
+	"sort"
+	"time"
+)
+
+// cueResolution is how often a playing timeline checks for due cues.
+const cueResolution = time.Millisecond
+
+// Cue moves a servo to a target at a given time of a Timeline.
+type Cue struct {
+	// At is the time of the cue from the start of the timeline.
+	At time.Duration
+	// Servo is the servo to move.
+	Servo *Servo
+	// Target is the target angle of the servo, adjusted for its Flags.
+	Target float64
+}
+
+// Timeline is a list of cues played in order of time.
+type Timeline []Cue
+
+// Duration returns the time of the last cue of the timeline.
+func (t Timeline) Duration() time.Duration {
+	var d time.Duration
+	for _, c := range t {
+		if c.At > d {
+			d = c.At
+		}
+	}
+	return d
+}
+
+// sorted returns a copy of the timeline sorted by time. Cues at the same time
+// keep their order.
+func (t Timeline) sorted() Timeline {
+	cues := make(Timeline, len(t))
+	copy(cues, t)
+	sort.SliceStable(cues, func(i, j int) bool {
+		return cues[i].At < cues[j].At
+	})
+	return cues
+}
+
+// Play plays the timeline from now, using the playback clock (see
+// servo.Now()). It blocks until all the cues were played, and then waits for
+// the servos to finish moving. Closing stop aborts the playback.
+func (t Timeline) Play(stop <-chan struct{}) error {
+	start := Now()
+	return t.play(func() time.Duration {
+		return Now().Sub(start)
+	}, stop)
+}
+
+// play plays the cues of the timeline at the time reported by position. If
+// position jumps forward, the skipped cues are played immediately. If it jumps
+// backwards, the cues already played are not repeated.
+func (t Timeline) play(position func() time.Duration, stop <-chan struct{}) error {
+	cues := t.sorted()
+	moved := make(map[*Servo]bool)
+
+	tick := time.NewTicker(cueResolution)
+	defer tick.Stop()
+
+	for len(cues) > 0 {
+		if _blaster.isClosed() {
+			return ErrClosed
+		}
+
+		now := position()
+		for len(cues) > 0 && cues[0].At <= now {
+			c := cues[0]
+			c.Servo.MoveTo(c.Target)
+			moved[c.Servo] = true
+			cues = cues[1:]
+		}
+		if len(cues) == 0 {
+			break
+		}
+
+		select {
+		case <-stop:
+			return nil
+		case <-tick.C:
+		}
+	}
+
+	for s := range moved {
+		s.Wait()
+	}
+
+	return nil
+}
@@ -0,0 +1,72 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeline_Play(t *testing.T) {
+	useBlaster(t)
+
+	a, b := New(98), New(99)
+	for _, s := range []*Servo{a, b} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+	}
+
+	sub := Subscribe(16, EventMove)
+	defer sub.Close()
+
+	tl := Timeline{
+		{At: 60 * time.Millisecond, Servo: a, Target: 20},
+		{At: 0, Servo: b, Target: 10},
+		{At: 30 * time.Millisecond, Servo: a, Target: 10},
+	}
+	if got, want := tl.Duration(), 60*time.Millisecond; got != want {
+		t.Errorf("wrong duration, got: %v, want: %v", got, want)
+	}
+
+	start := time.Now()
+	if err := tl.Play(nil); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 60*time.Millisecond {
+		t.Errorf("timeline played too fast: %v", elapsed)
+	}
+
+	want := []*Servo{b, a, a}
+	for i, s := range want {
+		e := <-sub.C
+		if e.Servo != s {
+			t.Errorf("cue %d moved %v, want: %v", i, e.Servo, s)
+		}
+	}
+	if a.Position() != 20 || b.Position() != 10 {
+		t.Errorf("wrong positions, got: %.2f, %.2f, want: 20.00, 10.00", a.Position(), b.Position())
+	}
+}
+
+func TestTimeline_PlayStop(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	stop := make(chan struct{})
+	close(stop)
+
+	tl := Timeline{{At: time.Hour, Servo: s, Target: 10}}
+	if err := tl.Play(stop); err != nil {
+		t.Fatal(err)
+	}
+	if !s.isIdle() {
+		t.Error("stopped timeline moved the servo")
+	}
+}
@@ -0,0 +1,65 @@
+package servo
+
+import "sync/atomic"
+
+// MoveOutcome is how a traced move ended.
+type MoveOutcome string
+
+const (
+	// MoveFinished is the outcome of a move that reached its target.
+	MoveFinished MoveOutcome = "finished"
+	// MoveStopped is the outcome of a move stopped by Servo.Stop().
+	MoveStopped MoveOutcome = "stopped"
+	// MoveReplaced is the outcome of a move overridden by a new target.
+	MoveReplaced MoveOutcome = "replaced"
+	// MoveFaulted is the outcome of a move interrupted or refused by the fault
+	// state of the servo.
+	MoveFaulted MoveOutcome = "faulted"
+)
+
+// Tracer traces the lifecycle of the moves of the servos, from MoveTo() to
+// their completion. Implement it with a tracing library (e.g. OpenTelemetry,
+// starting a span in StartMove) to follow a user action into physical motion.
+type Tracer interface {
+	// StartMove is called when the servo receives a new target, adjusted for
+	// its Flags.
+	StartMove(s *Servo, target float64) Span
+}
+
+// Span is a single traced move.
+type Span interface {
+	// Flushed is called every time a pwm of the move is written to
+	// pi-blaster.
+	Flushed(pwm float64)
+	// End is called once, when the move ends.
+	End(outcome MoveOutcome)
+}
+
+// tracerBox allows storing a nil Tracer in an atomic.Value.
+type tracerBox struct {
+	Tracer
+}
+
+var tracer atomic.Value
+
+// SetTracer sets the tracer of the moves of all the servos. A nil tracer
+// disables tracing (default).
+func SetTracer(t Tracer) {
+	tracer.Store(tracerBox{t})
+}
+
+// startSpan starts the span of a move, or returns nil if there is no tracer.
+func startSpan(s *Servo, target float64) Span {
+	t, _ := tracer.Load().(tracerBox)
+	if t.Tracer == nil {
+		return nil
+	}
+	return t.StartMove(s, target)
+}
+
+// endSpan ends the span, if any.
+func endSpan(span Span, outcome MoveOutcome) {
+	if span != nil {
+		span.End(outcome)
+	}
+}
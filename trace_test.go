@@ -0,0 +1,86 @@
+// +build !live
+
+package servo
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// recordSpan records the lifecycle of a traced move.
+type recordSpan struct {
+	target  float64
+	flushes int
+	outcome MoveOutcome
+	ended   chan struct{}
+	lock    sync.Mutex
+}
+
+func (s *recordSpan) Flushed(float64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.flushes++
+}
+
+func (s *recordSpan) End(outcome MoveOutcome) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.outcome = outcome
+	close(s.ended)
+}
+
+// recordTracer keeps the spans it starts.
+type recordTracer struct {
+	spans chan *recordSpan
+}
+
+func (t *recordTracer) StartMove(s *Servo, target float64) Span {
+	span := &recordSpan{target: target, ended: make(chan struct{})}
+	t.spans <- span
+	return span
+}
+
+func TestSetTracer(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+	tr := &recordTracer{spans: make(chan *recordSpan, 4)}
+	SetTracer(tr)
+	defer SetTracer(nil)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	s.MoveTo(180)
+	s.MoveTo(10).Wait()
+	s.MoveTo(180)
+	s.Stop()
+
+	want := []struct {
+		target  float64
+		outcome MoveOutcome
+	}{
+		{180, MoveReplaced},
+		{10, MoveFinished},
+		{180, MoveStopped},
+	}
+	for i, w := range want {
+		span := <-tr.spans
+		select {
+		case <-span.ended:
+		case <-time.After(time.Second):
+			t.Fatalf("span %d did not end", i)
+		}
+		span.lock.Lock()
+		if span.target != w.target || span.outcome != w.outcome {
+			t.Errorf("span %d: got: %.0f %s, want: %.0f %s", i, span.target, span.outcome, w.target, w.outcome)
+		}
+		if w.outcome == MoveFinished && span.flushes == 0 {
+			t.Errorf("span %d was never flushed", i)
+		}
+		span.lock.Unlock()
+	}
+}
@@ -0,0 +1,90 @@
+package servo
+
+import "sync"
+
+// Track makes the servo follow the latest value received from values, an
+// angle adjusted for its Flags, e.g. from a vision-tracking or joystick loop.
+// Unlike calling MoveTo() for each value, a new value only changes the target
+// of the current move, so the interpolation is not restarted and the speed
+// and acceleration limits of the servo apply smoothly. The values arriving
+// faster than the servo reads them are skipped, since only the latest one
+// counts. S-curves are not used while tracking.
+//
+// Another move overrides the tracking until the next value. Tracking ends
+// when values is closed or stop is called. stop waits until the tracking has
+// ended, and is safe to call more than once.
+func (s *Servo) Track(values <-chan float64) (stop func()) {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		var current *Move
+		for {
+			var v float64
+			var ok bool
+			select {
+			case <-done:
+				return
+			case v, ok = <-values:
+			}
+			if !ok {
+				return
+			}
+			// Skip to the latest value.
+		drain:
+			for {
+				select {
+				case next, more := <-values:
+					if !more {
+						break drain
+					}
+					v = next
+				default:
+					break drain
+				}
+			}
+
+			if !s.retarget(current, v) {
+				current = s.moveToWith(v, nil, WithJerk(0))
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+		<-stopped
+	}
+}
+
+// retarget changes the target of the move m to target, adjusted for the Flags
+// of the servo, if m is still the current move of the servo. The servo keeps
+// moving from where it is. It returns false if m is not the current move.
+func (s *Servo) retarget(m *Move, target float64) bool {
+	raw := s.raw(target)
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if m == nil || s.span != m {
+		return false
+	}
+	if s.fault != nil {
+		return true
+	}
+	raw = s.applyExpo(raw)
+	if s.speed() == 0 {
+		s.target = s.position
+	} else {
+		s.target = s.limit(raw)
+		if s.target != raw {
+			s.counters.Clamps++
+		}
+	}
+	s.publish()
+	_blaster.wakeUp()
+
+	return true
+}
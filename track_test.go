@@ -0,0 +1,86 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServo_Track(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetMaxSpeed(180)
+	s.SetPosition(0)
+	s.Wait()
+
+	sub := Subscribe(8, EventMove, s)
+	defer sub.Close()
+
+	values := make(chan float64)
+	stop := s.Track(values)
+	defer stop()
+
+	values <- 90
+	time.Sleep(250 * time.Millisecond)
+	if p := s.Position(); p < 35 || p > 55 {
+		t.Errorf("servo did not track, got: %.2f, want: about 45.00", p)
+	}
+	values <- 60
+	values <- 0
+	time.Sleep(100 * time.Millisecond)
+	if p := s.Position(); p > 40 {
+		t.Errorf("servo did not track the latest value, got: %.2f", p)
+	}
+
+	close(values)
+	stop()
+	s.Wait()
+	if s.Position() != 0 {
+		t.Errorf("servo did not arrive, got: %.2f", s.Position())
+	}
+
+	// The values only changed the target of the first move.
+	moves := 0
+	for len(sub.C) > 0 {
+		<-sub.C
+		moves++
+	}
+	if moves != 1 {
+		t.Errorf("wrong number of moves, got: %d, want: 1", moves)
+	}
+}
+
+func TestServo_Track_override(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	values := make(chan float64)
+	stop := s.Track(values)
+	values <- 180
+	time.Sleep(20 * time.Millisecond)
+
+	// Another move overrides the tracking until the next value.
+	s.MoveTo(10).Wait()
+	if s.Position() != 10 {
+		t.Errorf("move did not override the tracking, got: %.2f", s.Position())
+	}
+	values <- 20
+	time.Sleep(20 * time.Millisecond)
+	stop()
+	stop()
+	s.Wait()
+	if s.Position() != 20 {
+		t.Errorf("servo did not track after the move, got: %.2f", s.Position())
+	}
+}
@@ -0,0 +1,128 @@
+package servo
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// ChannelTransform changes the pulse of a channel on its way to the backend,
+// e.g. for inverting level shifters or opto-isolators, without changing the
+// calibration of the servo. The pulse is inverted, scaled, offset and then
+// clamped, in that order.
+type ChannelTransform struct {
+	// Invert inverts the duty of the pulse within Period, so a pulse of w
+	// is written as Period-w. A released channel (0 duty) is written at
+	// full duty, so its line stays low after the inverter.
+	Invert bool
+	// Period is the pwm period used by Invert (default: the period of
+	// pi-blaster, see servo.SetPiBlasterFrequency()).
+	Period time.Duration
+	// Scale multiplies the pulse, e.g. to write counts instead of
+	// durations to a controller. If 0, the pulse is not scaled.
+	Scale float64
+	// Offset is added to the pulse.
+	Offset time.Duration
+	// Min and Max clamp the pulse. If 0, they are not checked.
+	Min, Max time.Duration
+}
+
+// apply returns the transformed pulse width.
+func (t ChannelTransform) apply(width time.Duration) time.Duration {
+	if t.Invert {
+		period := t.Period
+		if period == 0 {
+			period = piBlasterPeriod()
+		}
+		width = period - width
+	}
+	if t.Scale != 0 {
+		width = time.Duration(float64(width) * t.Scale)
+	}
+	width += t.Offset
+	if t.Min != 0 && width < t.Min {
+		width = t.Min
+	}
+	if t.Max != 0 && width > t.Max {
+		width = t.Max
+	}
+	return width
+}
+
+// transformBackend applies the transforms of the channels to the pulses of a
+// backend.
+type transformBackend struct {
+	b          Backend
+	transforms map[int]ChannelTransform
+	// written are the transformed channels written since the last Close.
+	written map[int]bool
+	lock    *sync.Mutex
+}
+
+// TransformBackend returns a Backend that applies the transforms, by pin, to
+// the pulses before writing them to b. The pins without a transform are
+// written unchanged. Use it with servo.SetBackend():
+//
+//	servo.SetBackend(servo.TransformBackend(pca, map[int]servo.ChannelTransform{
+//		3: {Invert: true, Period: pca.Period()},
+//	}))
+//
+// On Close, the released transformed channels are written before b is
+// closed. Backends that release every channel when closed, like pi-blaster,
+// still end with the inverted channels at 0 duty.
+func TransformBackend(b Backend, transforms map[int]ChannelTransform) Backend {
+	t := make(map[int]ChannelTransform, len(transforms))
+	for pin, tr := range transforms {
+		t[pin] = tr
+	}
+	return &transformBackend{
+		b:          b,
+		transforms: t,
+		written:    make(map[int]bool),
+		lock:       new(sync.Mutex),
+	}
+}
+
+// Write implements the Backend interface.
+func (t *transformBackend) Write(pulses []Pulse) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	out := make([]Pulse, len(pulses))
+	for i, p := range pulses {
+		out[i] = p
+		if tr, ok := t.transforms[p.Pin]; ok {
+			out[i].Width = tr.apply(p.Width)
+			t.written[p.Pin] = true
+		}
+	}
+
+	return t.b.Write(out)
+}
+
+// Close implements the Backend interface.
+func (t *transformBackend) Close() error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	pins := make([]int, 0, len(t.written))
+	for pin := range t.written {
+		pins = append(pins, pin)
+	}
+	sort.Ints(pins)
+
+	var err error
+	if len(pins) > 0 {
+		released := make([]Pulse, len(pins))
+		for i, pin := range pins {
+			released[i] = Pulse{Pin: pin, Width: t.transforms[pin].apply(0)}
+		}
+		err = t.b.Write(released)
+	}
+	t.written = make(map[int]bool)
+
+	if cerr := t.b.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
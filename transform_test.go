@@ -0,0 +1,63 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestChannelTransform(t *testing.T) {
+	us := time.Microsecond
+	tests := []struct {
+		name  string
+		tr    ChannelTransform
+		width time.Duration
+		want  time.Duration
+	}{
+		{"none", ChannelTransform{}, 1500 * us, 1500 * us},
+		{"invert", ChannelTransform{Invert: true}, 1500 * us, 8500 * us},
+		{"invert release", ChannelTransform{Invert: true, Period: 20 * time.Millisecond}, 0, 20 * time.Millisecond},
+		{"scale", ChannelTransform{Scale: 2}, 1500 * us, 3000 * us},
+		{"offset", ChannelTransform{Offset: -100 * us}, 1500 * us, 1400 * us},
+		{"min", ChannelTransform{Min: 1000 * us}, 500 * us, 1000 * us},
+		{"max", ChannelTransform{Max: 2000 * us}, 2500 * us, 2000 * us},
+		{"order", ChannelTransform{Invert: true, Scale: 0.5, Offset: 100 * us, Max: 4000 * us}, 1000 * us, 4000 * us},
+	}
+	for _, tt := range tests {
+		if got := tt.tr.apply(tt.width); got != tt.want {
+			t.Errorf("%s: got: %v, want: %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTransformBackend(t *testing.T) {
+	rb := &recordBackend{pulses: make(map[int]time.Duration)}
+	tb := TransformBackend(rb, map[int]ChannelTransform{
+		3: {Invert: true},
+	})
+
+	err := tb.Write([]Pulse{
+		{Pin: 2, Width: 1500 * time.Microsecond},
+		{Pin: 3, Width: 1500 * time.Microsecond},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rb.pulses[2], 1500*time.Microsecond; got != want {
+		t.Errorf("untransformed pin changed, got: %v, want: %v", got, want)
+	}
+	if got, want := rb.pulses[3], 8500*time.Microsecond; got != want {
+		t.Errorf("pin was not inverted, got: %v, want: %v", got, want)
+	}
+
+	if err := tb.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if got, want := rb.pulses[3], pulsePeriod; got != want {
+		t.Errorf("inverted pin was not released, got: %v, want: %v", got, want)
+	}
+	if !rb.closed {
+		t.Error("backend was not closed")
+	}
+}
@@ -0,0 +1,102 @@
+package servo
+
+import (
+	"math"
+	"time"
+)
+
+// minUpdateInterval is the fastest update interval of a servo.
+const minUpdateInterval = time.Millisecond
+
+// SetUpdateInterval sets how often the manager computes the position of the
+// servo while it moves, e.g. fast for a camera gimbal and slow for a flag
+// waver. By default (0), the servo is updated on every tick of the manager,
+// which is 3ms and grows with the number of moving servos. A servo with an
+// interval faster than the tick makes the manager tick faster, so it is not
+// slowed down by the size of the fleet. Intervals below 1ms are set to 1ms.
+//
+// The interval does not change the flush rate. See servo.Rate().
+func (s *Servo) SetUpdateInterval(d time.Duration) {
+	if d < 0 {
+		d = 0
+	} else if d > 0 && d < minUpdateInterval {
+		d = minUpdateInterval
+	}
+	s.lock.Lock()
+	s.interval = d
+	s.lock.Unlock()
+
+	if _blaster.isConnected(s) {
+		select {
+		case _blaster.retick <- struct{}{}:
+		case <-_blaster.done:
+		}
+	}
+}
+
+// UpdateInterval returns the update interval of the servo, or 0 if it is
+// updated on every tick of the manager.
+func (s *Servo) UpdateInterval() time.Duration {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+
+	return s.interval
+}
+
+// due checks if the manager should update the servo at now, with ticks of the
+// manager every tick. A servo is updated on the tick closest to its interval,
+// so the jitter of the ticker does not postpone it a whole tick. It must be
+// called by the manager.
+func (s *Servo) due(now time.Time, tick time.Duration) bool {
+	d := s.UpdateInterval()
+	if d == 0 {
+		return true
+	}
+	if now.Sub(s.updated) < d-tick/2 {
+		return false
+	}
+	s.updated = now
+	return true
+}
+
+// tickInterval returns the update interval of the manager, which grows with
+// the number of moving servos, so a large fleet of mostly idle servos keeps
+// fine-grained updates for the few that move. It is never slower than
+// fastest, the fastest update interval of the moving servos, if not 0.
+func tickInterval(moving int, fastest time.Duration) time.Duration {
+	factor := math.Log10(float64(moving+1))*3 + 1
+	interval := time.Duration(factor) * 3 * time.Millisecond
+	if fastest > 0 && fastest < interval {
+		interval = fastest
+	}
+	return interval
+}
+
+// faster returns the fastest of the update intervals a and b, where 0 means
+// no interval.
+func faster(a, b time.Duration) time.Duration {
+	if a == 0 || (b > 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// isMoving checks if the manager must update the servo on its ticks, because
+// it moves or has motion layers.
+func (s *Servo) isMoving() bool {
+	return !s.isIdle() || s.hasLayers()
+}
+
+// tickInterval returns the update interval of the manager for the servos
+// moving now. It must be called by the manager.
+func (b *blaster) tickInterval() time.Duration {
+	moving := 0
+	var fastest time.Duration
+	for _, s := range b._servos {
+		if s.isMoving() {
+			moving++
+			fastest = faster(fastest, s.UpdateInterval())
+		}
+	}
+	return tickInterval(moving, fastest)
+}
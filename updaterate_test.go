@@ -0,0 +1,113 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServo_due(t *testing.T) {
+	s := New(99)
+	start := time.Now()
+	if !s.due(start, 3*time.Millisecond) {
+		t.Error("servo without interval was not updated")
+	}
+
+	s.SetUpdateInterval(10 * time.Millisecond)
+	if !s.due(start, 3*time.Millisecond) {
+		t.Error("first update was skipped")
+	}
+	tests := []struct {
+		after time.Duration
+		want  bool
+	}{
+		{3 * time.Millisecond, false},
+		{6 * time.Millisecond, false},
+		// Closer to the interval than the next tick.
+		{9 * time.Millisecond, true},
+		{12 * time.Millisecond, false},
+		{20 * time.Millisecond, true},
+	}
+	for _, tt := range tests {
+		if got := s.due(start.Add(tt.after), 3*time.Millisecond); got != tt.want {
+			t.Errorf("due after %v, got: %v, want: %v", tt.after, got, tt.want)
+		}
+	}
+}
+
+func TestServo_SetUpdateInterval(t *testing.T) {
+	s := New(99)
+	for _, tt := range []struct{ set, want time.Duration }{
+		{20 * time.Millisecond, 20 * time.Millisecond},
+		{time.Microsecond, time.Millisecond},
+		{-time.Second, 0},
+	} {
+		s.SetUpdateInterval(tt.set)
+		if got := s.UpdateInterval(); got != tt.want {
+			t.Errorf("SetUpdateInterval(%v), got: %v, want: %v", tt.set, got, tt.want)
+		}
+	}
+}
+
+func TestBlaster_tickInterval(t *testing.T) {
+	b := newBlaster()
+	for i := 0; i < 1000; i++ {
+		b._servos[gpio(i)] = New(i)
+	}
+	if got, want := b.tickInterval(), 3*time.Millisecond; got != want {
+		t.Errorf("idle servos slowed down the tick, got: %v, want: %v", got, want)
+	}
+
+	for i := 0; i < 100; i++ {
+		s := b._servos[gpio(i)]
+		s.idle = false
+		s.publish()
+	}
+	if got := b.tickInterval(); got <= 3*time.Millisecond {
+		t.Fatalf("tick did not grow with the moving servos, got: %v", got)
+	}
+
+	fast := New(1000)
+	fast.SetUpdateInterval(2 * time.Millisecond)
+	b._servos[1000] = fast
+	if got := b.tickInterval(); got <= 3*time.Millisecond {
+		t.Errorf("idle servo set the tick, got: %v", got)
+	}
+	fast.idle = false
+	fast.publish()
+	if got, want := b.tickInterval(), 2*time.Millisecond; got != want {
+		t.Errorf("wrong tick, got: %v, want: %v", got, want)
+	}
+}
+
+func TestUpdateInterval(t *testing.T) {
+	useBlaster(t)
+
+	fast, slow := New(98), New(99)
+	for _, s := range []*Servo{fast, slow} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+		s.SetSpeed(0.3)
+	}
+	slow.SetUpdateInterval(30 * time.Millisecond)
+
+	sub := Subscribe(1000, EventPosition)
+	defer sub.Close()
+
+	Batch(func(tx *Tx) {
+		tx.MoveTo(fast, 90)
+		tx.MoveTo(slow, 90)
+	}).Wait()
+	sub.Close()
+
+	updates := make(map[*Servo]int)
+	for e := range sub.C {
+		updates[e.Servo]++
+	}
+	if updates[slow] == 0 || updates[slow]*3 > updates[fast] {
+		t.Errorf("slow servo was updated %d times, fast servo %d times", updates[slow], updates[fast])
+	}
+}
@@ -0,0 +1,158 @@
+package servo
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Problem is an error found in a configuration by servo.ValidateConfig().
+type Problem struct {
+	// Servo is the name of the servo with the problem. It is empty for
+	// problems that affect the whole configuration.
+	Servo string
+	// Field is the configuration field with the problem.
+	Field string
+	// Message describes the problem.
+	Message string
+}
+
+// Error implements the error interface.
+func (p Problem) Error() string {
+	if p.Servo == "" {
+		return fmt.Sprintf("%s: %s", p.Field, p.Message)
+	}
+	return fmt.Sprintf("servo %q: %s: %s", p.Servo, p.Field, p.Message)
+}
+
+// Problems is a list of problems found by servo.ValidateConfig().
+type Problems []Problem
+
+// Error implements the error interface, listing one problem per line.
+func (ps Problems) Error() string {
+	s := make([]string, len(ps))
+	for i, p := range ps {
+		s[i] = p.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// ValidateConfig checks the configuration without connecting anything, so
+// configuration errors can be caught at deploy time. It checks for missing or
+// duplicated names, pin conflicts, unknown flags, unsafe or invalid
+// calibrations, speeds out of range, initial positions outside the range of
+// the servo, invalid routes, and that the backend is available. It returns nil
+// if no problem was found.
+func ValidateConfig(c *Config) Problems {
+	return validateConfig(c, _blaster.available)
+}
+
+// validateConfig checks the configuration, using hasBackend to check that the
+// output is available.
+func validateConfig(c *Config, hasBackend func() bool) Problems {
+	var ps Problems
+	add := func(servo, field, format string, v ...interface{}) {
+		ps = append(ps, Problem{servo, field, fmt.Sprintf(format, v...)})
+	}
+
+	names := make(map[string]bool)
+	pins := make(map[int]string)
+
+	for i, sc := range c.Servos {
+		name := sc.Name
+		if name == "" {
+			name = fmt.Sprintf("#%d", i)
+			add(name, "name", "missing name")
+		} else if names[name] {
+			add(name, "name", "duplicated name")
+		}
+		names[name] = true
+
+		pin, err := sc.pin()
+		switch {
+		case err != nil:
+			add(name, "pin", "%v", err)
+		case pin < 0:
+			add(name, "pin", "invalid pin %d", pin)
+		default:
+			if other, ok := pins[pin]; ok {
+				add(name, "pin", "gpio(%d) is already used by %q", pin, other)
+			} else {
+				pins[pin] = name
+			}
+		}
+
+		f, err := sc.flags()
+		if err != nil {
+			add(name, "flags", "%v", err)
+		}
+
+		s := &Servo{
+			pin:              int32(pin),
+			Name:             name,
+			Flags:            f,
+			AllowUnsafePulse: sc.AllowUnsafePulse,
+			Range:            sc.Range,
+			MinAngle:         sc.MinAngle,
+			MaxAngle:         sc.MaxAngle,
+		}
+		c := sc.calibration()
+		if err := s.checkPulses(c); err != nil {
+			add(name, "pulse", "%v", err)
+		}
+		if err := s.checkTable(c, sc.CalibrationPoints); err != nil {
+			add(name, "calibration_points", "%v", err)
+		}
+		if c.MinPulse == c.MaxPulse {
+			add(name, "pulse", "min_pulse and max_pulse are equal, the servo cannot move")
+		}
+
+		if sc.Speed < 0 || sc.Speed > 1 {
+			add(name, "speed", "%.2f is outside the range 0.0 to 1.0", sc.Speed)
+		}
+		if sc.MaxSpeed < 0 {
+			add(name, "max_speed", "%.2f is negative", sc.MaxSpeed)
+		}
+		if sc.Acceleration < 0 || sc.Deceleration < 0 {
+			add(name, "acceleration", "limits %.2f and %.2f cannot be negative", sc.Acceleration, sc.Deceleration)
+		}
+		if sc.Jerk < 0 {
+			add(name, "jerk", "%.2f is negative", sc.Jerk)
+		}
+		if sc.Expo < 0 || sc.Expo > 1 {
+			add(name, "expo", "%.2f is outside the range 0.0 to 1.0", sc.Expo)
+		}
+		if sc.Range < 0 {
+			add(name, "range", "%.2f is negative", sc.Range)
+		}
+		if r := s.fullRange(); sc.MinAngle != 0 || sc.MaxAngle != 0 {
+			if sc.MinAngle < 0 || sc.MaxAngle > r || sc.MinAngle >= sc.MaxAngle {
+				add(name, "angle", "travel limits %.2f to %.2f are not within 0.00 to %.2f", sc.MinAngle, sc.MaxAngle, r)
+			}
+		}
+
+		if sc.Position != nil {
+			lo, hi := s.limits()
+			if raw := s.raw(*sc.Position); raw < lo || raw > hi {
+				add(name, "position", "%.2f is outside the range %.2f to %.2f",
+					*sc.Position, s.adjust(lo), s.adjust(hi))
+			}
+		}
+	}
+
+	for _, rc := range c.Routes {
+		switch {
+		case !names[rc.Servo]:
+			add(rc.Servo, "route", "route %q targets an unknown servo", rc.Source)
+		case rc.Source == "":
+			add(rc.Servo, "route", "missing source")
+		case rc.InMin == rc.InMax && rc.InMin != 0:
+			add(rc.Servo, "route", "route %q: in_min and in_max are equal", rc.Source)
+		}
+	}
+
+	if !hasBackend() {
+		add("", "backend", "%v", errPiBlasterNotFound)
+	}
+
+	return ps
+}
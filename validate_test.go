@@ -0,0 +1,83 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+)
+
+func TestValidateConfig_backend(t *testing.T) {
+	if hasBlaster() {
+		t.Skip("pi-blaster is running")
+	}
+	// Like init(), pi-blaster is disabled since it is not running.
+	useBlaster(t)
+
+	c := &Config{Servos: []ServoConfig{{Name: "jaw", Pin: 17, MinPulse: 0.05, MaxPulse: 0.25, Speed: 1}}}
+	if ps := ValidateConfig(c); len(ps) != 1 || ps[0].Field != "backend" {
+		t.Errorf("missing pi-blaster, got: %v, want: backend problem", ps)
+	}
+
+	SetBackend(&recordBackend{})
+	defer SetBackend(nil)
+	if ps := ValidateConfig(c); ps != nil {
+		t.Errorf("pi-blaster is not the backend, got:\n%v", ps)
+	}
+}
+
+func TestValidateConfig(t *testing.T) {
+	position := func(p float64) *float64 { return &p }
+
+	c := &Config{
+		Servos: []ServoConfig{
+			{Name: "jaw", Pin: 17, MinPulse: 0.05, MaxPulse: 0.25, Speed: 1},
+			{Name: "neck", Pin: 18, MinPulse: 0.05, MaxPulse: 0.25, Speed: 1, Flags: []string{"centered"}, Position: position(-90)},
+		},
+	}
+	if ps := validateConfig(c, func() bool { return true }); ps != nil {
+		t.Errorf("valid config has problems:\n%v", ps)
+	}
+
+	c.Servos = append(c.Servos,
+		ServoConfig{Name: "jaw", Pin: 17, MinPulse: 0.5, MaxPulse: 0.5, Speed: 2, Flags: []string{"upside-down"}},
+		ServoConfig{Pin: -1, MinPulse: 0.05, MaxPulse: 0.25, Speed: 1, Flags: []string{"centered"}, Position: position(120)},
+		ServoConfig{Name: "arm", Pin: 19, MinPulse: 0.05, MaxPulse: 0.25, Speed: 1, MinAngle: 150, MaxAngle: 30},
+		ServoConfig{Name: "leg", Pin: 20, MinPulse: 0.05, MaxPulse: 0.25, Speed: 1, MinAngle: 30, MaxAngle: 150, Position: position(10)},
+		ServoConfig{Name: "eye", Pin: 21, MinPulse: 0.05, MaxPulse: 0.25, Speed: 1, CalibrationPoints: []CalibrationPoint{{Angle: 90, Pulse: 0.3}}},
+		ServoConfig{Name: "winch", Pin: 22, MinPulse: 0.05, MaxPulse: 0.25, Speed: 1, Range: -1},
+		ServoConfig{Name: "wide", Pin: 23, MinPulse: 0.05, MaxPulse: 0.25, Speed: 1, Range: 270, MaxAngle: 250, Position: position(260)},
+	)
+	c.Routes = []RouteConfig{
+		{Source: "pad/x", Servo: "tail"},
+		{Source: "pad/y", Servo: "neck", InMin: 1, InMax: 1},
+	}
+	ps := validateConfig(c, func() bool { return false })
+
+	want := []struct{ servo, field string }{
+		{"jaw", "name"},
+		{"jaw", "pin"},
+		{"jaw", "flags"},
+		{"jaw", "pulse"},
+		{"jaw", "pulse"},
+		{"jaw", "speed"},
+		{"#3", "name"},
+		{"#3", "pin"},
+		{"#3", "position"},
+		{"arm", "angle"},
+		{"leg", "position"},
+		{"eye", "calibration_points"},
+		{"winch", "range"},
+		{"wide", "position"},
+		{"tail", "route"},
+		{"neck", "route"},
+		{"", "backend"},
+	}
+	if len(ps) != len(want) {
+		t.Fatalf("problems, got: %d, want: %d\n%v", len(ps), len(want), ps)
+	}
+	for i, w := range want {
+		if ps[i].Servo != w.servo || ps[i].Field != w.field {
+			t.Errorf("problem %d, got: %v, want: %s %s", i, ps[i], w.servo, w.field)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package servo
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ViolationKind is the kind of a Violation found by Timeline.Verify().
+type ViolationKind string
+
+const (
+	// ViolationRange is a target outside the range of the servo, which would
+	// be clamped.
+	ViolationRange ViolationKind = "range"
+	// ViolationSpeed is a move that cannot reach its target at the speed of
+	// the servo before the next cue of the servo replaces it.
+	ViolationSpeed ViolationKind = "speed"
+	// ViolationSync is a set of servos cued at the same time that arrive at
+	// their targets further apart than the sync tolerance.
+	ViolationSync ViolationKind = "sync"
+	// ViolationPower is more servos moving at the same time than the power
+	// budget allows.
+	ViolationPower ViolationKind = "power"
+)
+
+// Violation is a breach of the motion envelope found by Timeline.Verify().
+type Violation struct {
+	// At is the time of the timeline where the violation happens.
+	At time.Duration `json:"at"`
+	// Servo is the name of the servo with the violation. It is empty for
+	// violations that affect several servos.
+	Servo string `json:"servo,omitempty"`
+	// Kind is the kind of the violation.
+	Kind ViolationKind `json:"kind"`
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+// Error implements the error interface.
+func (v Violation) Error() string {
+	if v.Servo == "" {
+		return fmt.Sprintf("%v: %s: %s", v.At, v.Kind, v.Message)
+	}
+	return fmt.Sprintf("%v: servo %q: %s: %s", v.At, v.Servo, v.Kind, v.Message)
+}
+
+// Report is the list of violations found by Timeline.Verify(), in order of
+// time.
+type Report []Violation
+
+// Error implements the error interface, listing one violation per line.
+func (r Report) Error() string {
+	s := make([]string, len(r))
+	for i, v := range r {
+		s[i] = v.Error()
+	}
+	return strings.Join(s, "\n")
+}
+
+// Envelope holds the limits checked by Timeline.Verify(), besides the range
+// and speed of each servo.
+type Envelope struct {
+	// MaxMoving is the maximum number of servos moving at the same time,
+	// e.g. for the power budget of the supply. If 0, it is not checked.
+	MaxMoving int
+	// SyncTolerance is the maximum time between the arrivals of the servos
+	// cued at the same time. If 0, it is not checked.
+	SyncTolerance time.Duration
+}
+
+// interval is the time a servo is moving in a simulation. The end is -1 if
+// the servo never arrives.
+type interval struct {
+	start, end time.Duration
+}
+
+// simulated is the simulated state of a servo.
+type simulated struct {
+	position, target float64
+	speed            float64
+	start            time.Duration
+	// arrival is when the servo reaches its target, or -1 if never.
+	arrival time.Duration
+	// move is the index of the current move in the simulated moves.
+	move int
+}
+
+// at returns the simulated position of the servo at t.
+func (st *simulated) at(t time.Duration) float64 {
+	moved := st.speed * (t - st.start).Seconds()
+	if d := st.target - st.position; math.Abs(d) <= moved {
+		return st.target
+	} else if d > 0 {
+		return st.position + moved
+	}
+	return st.position - moved
+}
+
+// Verify simulates the timeline offline, from the current position and at the
+// current speed of each servo, and reports the targets outside the range of a
+// servo, the moves replaced before they reach their targets, and the breaches
+// of the envelope. The servos are not moved, so plans can be checked before
+// they ever run on hardware. It returns nil if no violation was found.
+//
+// The servos move at constant speed, so Verify does not check accelerations,
+// and the layers of the servos are not simulated.
+func (t Timeline) Verify(e Envelope) Report {
+	var r Report
+	add := func(at time.Duration, s *Servo, kind ViolationKind, format string, v ...interface{}) {
+		name := ""
+		if s != nil {
+			name = s.Name
+		}
+		r = append(r, Violation{at, name, kind, fmt.Sprintf(format, v...)})
+	}
+
+	states := make(map[*Servo]*simulated)
+	var moves []interval
+
+	cues := t.sorted()
+	for i := 0; i < len(cues); {
+		// Cues at the same time are checked together for sync.
+		at := cues[i].At
+		var arrivals []time.Duration
+		for ; i < len(cues) && cues[i].At == at; i++ {
+			c := cues[i]
+			s := c.Servo
+
+			replaced := false
+			st, ok := states[s]
+			if !ok {
+				s.lock.RLock()
+				st = &simulated{position: s.position, target: s.position, speed: s.speed(), move: -1}
+				s.lock.RUnlock()
+				states[s] = st
+			} else if st.arrival < 0 || st.arrival > at {
+				add(at, s, ViolationSpeed, "replaced at %.2f while moving to %.2f",
+					s.adjust(st.at(at)), s.adjust(st.target))
+				replaced = true
+			}
+
+			raw := s.raw(c.Target)
+			target := s.limit(raw)
+			if target != raw {
+				lo, hi := s.limits()
+				add(at, s, ViolationRange, "target %.2f is outside the range %.2f to %.2f",
+					c.Target, s.adjust(lo), s.adjust(hi))
+			}
+
+			st.position = st.at(at)
+			st.target = target
+			st.start = at
+			distance := math.Abs(target - st.position)
+			switch {
+			case distance == 0:
+				st.arrival = at
+			case st.speed == 0:
+				add(at, s, ViolationSpeed, "cannot move to %.2f at speed 0", c.Target)
+				st.arrival = -1
+			default:
+				st.arrival = at + time.Duration(distance/st.speed*float64(time.Second))
+			}
+
+			if replaced {
+				// The servo keeps moving without stopping.
+				moves[st.move].end = st.arrival
+			} else {
+				st.move = len(moves)
+				moves = append(moves, interval{at, st.arrival})
+			}
+			if st.arrival >= 0 {
+				arrivals = append(arrivals, st.arrival)
+			}
+		}
+
+		if e.SyncTolerance > 0 && len(arrivals) > 1 {
+			sort.Slice(arrivals, func(i, j int) bool { return arrivals[i] < arrivals[j] })
+			if d := arrivals[len(arrivals)-1] - arrivals[0]; d > e.SyncTolerance {
+				add(at, nil, ViolationSync, "servos cued together arrive %v apart, tolerance %v", d, e.SyncTolerance)
+			}
+		}
+	}
+
+	if e.MaxMoving > 0 {
+		r = append(r, verifyPower(moves, e.MaxMoving)...)
+	}
+
+	sort.SliceStable(r, func(i, j int) bool { return r[i].At < r[j].At })
+	if len(r) == 0 {
+		return nil
+	}
+	return r
+}
+
+// verifyPower reports when more than max servos move at the same time.
+func verifyPower(moves []interval, max int) Report {
+	type edge struct {
+		at    time.Duration
+		delta int
+	}
+	var edges []edge
+	for _, m := range moves {
+		if m.end == m.start {
+			continue
+		}
+		edges = append(edges, edge{m.start, 1})
+		if m.end > m.start {
+			edges = append(edges, edge{m.end, -1})
+		}
+	}
+	// Servos that stop make room for the servos that start at the same
+	// time.
+	sort.Slice(edges, func(i, j int) bool {
+		if edges[i].at == edges[j].at {
+			return edges[i].delta < edges[j].delta
+		}
+		return edges[i].at < edges[j].at
+	})
+
+	var r Report
+	moving := 0
+	for _, e := range edges {
+		moving += e.delta
+		if e.delta > 0 && moving == max+1 {
+			r = append(r, Violation{
+				At:      e.at,
+				Kind:    ViolationPower,
+				Message: fmt.Sprintf("%d servos moving, budget %d", moving, max),
+			})
+		}
+	}
+	return r
+}
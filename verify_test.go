@@ -0,0 +1,51 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimeline_Verify(t *testing.T) {
+	a, b := New(98), New(99)
+	a.Name, b.Name = "a", "b"
+
+	// a takes 570ms from 0 to 180, b takes twice as long.
+	b.SetSpeed(0.5)
+	full := time.Duration(180 / a.maxStep * float64(time.Second))
+
+	ok := Timeline{
+		{At: 0, Servo: a, Target: 180},
+		{At: full + 10*time.Millisecond, Servo: a, Target: 0},
+	}
+	if r := ok.Verify(Envelope{MaxMoving: 1}); r != nil {
+		t.Errorf("valid timeline has violations:\n%v", r)
+	}
+
+	bad := Timeline{
+		{At: 0, Servo: a, Target: 180},
+		{At: 0, Servo: b, Target: 180},
+		{At: 100 * time.Millisecond, Servo: a, Target: 200},
+	}
+	r := bad.Verify(Envelope{MaxMoving: 1, SyncTolerance: 50 * time.Millisecond})
+
+	want := []struct {
+		at    time.Duration
+		servo string
+		kind  ViolationKind
+	}{
+		{0, "", ViolationSync},
+		{0, "", ViolationPower},
+		{100 * time.Millisecond, "a", ViolationSpeed},
+		{100 * time.Millisecond, "a", ViolationRange},
+	}
+	if len(r) != len(want) {
+		t.Fatalf("violations, got: %d, want: %d\n%v", len(r), len(want), r)
+	}
+	for i, w := range want {
+		if r[i].At != w.at || r[i].Servo != w.servo || r[i].Kind != w.kind {
+			t.Errorf("violation %d, got: %v, want: %v %q %s", i, r[i], w.at, w.servo, w.kind)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package servo
+
+import (
+	"context"
+	"reflect"
+	"sync"
+)
+
+// closedDone is a closed channel, returned by Done() when there is nothing to
+// wait for.
+var closedDone = func() chan struct{} {
+	c := make(chan struct{})
+	close(c)
+	return c
+}()
+
+// closed is a Waiter that waits for the channel to be closed.
+type closed chan struct{}
+
+// Wait implements the Waiter interface.
+func (w closed) Wait() {
+	<-w
+}
+
+// Done implements the Waiter interface.
+func (w closed) Done() <-chan struct{} {
+	return w
+}
+
+// doneCache shares the channel of done() between the calls to Done() of a
+// Waiter made of other waiters, so they start a single goroutine until the
+// channel is closed, instead of one goroutine per call.
+type doneCache struct {
+	c    <-chan struct{}
+	lock sync.Mutex
+}
+
+// get returns the channel of done(wait), starting a new wait only if the
+// previous channel was closed.
+func (d *doneCache) get(wait func()) <-chan struct{} {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	if d.c != nil {
+		select {
+		case <-d.c:
+		default:
+			return d.c
+		}
+	}
+	d.c = done(wait)
+	return d.c
+}
+
+// done returns a channel that is closed when wait returns. The wait runs in
+// its own goroutine, which ends when wait returns, even if nobody receives
+// from the channel.
+func done(wait func()) <-chan struct{} {
+	c := make(chan struct{})
+	go func() {
+		wait()
+		close(c)
+	}()
+	return c
+}
+
+// WaitAny waits for the first of the waiters to finish and returns its index,
+// e.g. to find out which gripper closed first. If ctx is done before, it
+// returns -1 and the error of ctx, which allows racing moves against a timeout
+// or a sensor event. With no waiters, WaitAny waits for ctx.
+func WaitAny(ctx context.Context, waiters ...Waiter) (index int, err error) {
+	// The first case is ctx, followed by the Done channel of each waiter.
+	cases := make([]reflect.SelectCase, 0, len(waiters)+1)
+	cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())})
+	for _, w := range waiters {
+		cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(w.Done())})
+	}
+
+	if i, _, _ := reflect.Select(cases); i > 0 {
+		return i - 1, nil
+	}
+	return -1, ctx.Err()
+}
+
+// WaitAll waits for all the waiters to finish. If ctx is done before, it
+// returns the error of ctx.
+func WaitAll(ctx context.Context, waiters ...Waiter) error {
+	for _, w := range waiters {
+		select {
+		case <-w.Done():
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	return nil
+}
+
+// WaitContext waits for the servo to stop moving. If ctx is done before, it
+// returns the error of ctx and the servo keeps moving. Use
+// Servo.MoveToContext() to stop the servo as well.
+func (s *Servo) WaitContext(ctx context.Context) error {
+	select {
+	case <-s.Done():
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// WaitContext waits for the move to end. If ctx is done before, it returns
+// the error of ctx and the move goes on.
+func (m *Move) WaitContext(ctx context.Context) error {
+	select {
+	case <-m.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// MoveToContext moves the servo to target, like Servo.MoveTo(), and waits for
+// the move to end. If ctx is done before, e.g. when the client of an HTTP
+// handler disconnects, the move is canceled, stopping the servo, and it returns
+// the error of ctx. If another goroutine overrides the move, MoveToContext
+// returns nil without stopping the servo. Check Move.Result() of
+// Servo.MoveTo() to tell how a move ended.
+func (s *Servo) MoveToContext(ctx context.Context, target float64) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	m := s.MoveTo(target)
+	if err := m.WaitContext(ctx); err != nil {
+		m.Cancel()
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+// +build !live
+
+package servo
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestWaitAny(t *testing.T) {
+	useBlaster(t)
+
+	slow, fast := New(98), New(99)
+	for _, s := range []*Servo{slow, fast} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+	}
+	slow.SetSpeed(0.5)
+
+	i, err := WaitAny(context.Background(), slow.MoveTo(90), fast.MoveTo(90))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if i != 1 {
+		t.Errorf("wrong first waiter, got: %d, want: %d", i, 1)
+	}
+
+	if err := WaitAll(context.Background(), slow, fast); err != nil {
+		t.Fatal(err)
+	}
+	if slow.Position() != 90 || fast.Position() != 90 {
+		t.Errorf("servos did not finish, got: %.2f, %.2f", slow.Position(), fast.Position())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	slow.SetSpeed(0.1)
+	if i, err := WaitAny(ctx, slow.MoveTo(0)); i != -1 || err != context.DeadlineExceeded {
+		t.Errorf("timeout was ignored, got: %d, %v", i, err)
+	}
+	if err := WaitAll(ctx, slow); err != context.DeadlineExceeded {
+		t.Errorf("timeout was ignored, got: %v", err)
+	}
+	slow.Stop()
+}
+
+func TestWaitAny_sweep(t *testing.T) {
+	useBlaster(t)
+
+	a, b := New(98), New(99)
+	for _, s := range []*Servo{a, b} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+	}
+
+	before := runtime.NumGoroutine()
+	sweep := a.Sweep(0, 90, time.Second)
+	b.Sweep(0, 90, time.Second)
+	for i := 0; i < 20; i++ {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+		if _, err := WaitAny(ctx, sweep, b); err != context.DeadlineExceeded {
+			t.Errorf("sweep ended, got: %v", err)
+		}
+		if err := WaitAll(ctx, a, b); err != context.DeadlineExceeded {
+			t.Errorf("sweep ended, got: %v", err)
+		}
+		cancel()
+	}
+	if n := runtime.NumGoroutine(); n > before {
+		t.Errorf("goroutines leaked, got: %d, want: at most %d", n, before)
+	}
+	a.Stop()
+	b.Stop()
+}
+
+func TestServo_Done(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetSpeed(0.5)
+
+	select {
+	case <-s.MoveTo(90).Done():
+		t.Fatal("done before the servo finished moving")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	select {
+	case <-s.Done():
+	case <-time.After(time.Second):
+		t.Fatal("not done after the servo finished moving")
+	}
+	if s.Position() != 90 {
+		t.Errorf("servo did not finish, got: %.2f", s.Position())
+	}
+
+	select {
+	case <-s.Done():
+	case <-time.After(50 * time.Millisecond):
+		t.Error("not done while the servo was idle")
+	}
+}
+
+func TestDone_sweep(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	g := NewGroup(s)
+
+	before := runtime.NumGoroutine()
+	s.Sweep(0, 90, time.Second)
+	for i := 0; i < 50; i++ {
+		for _, w := range []Waiter{s, g} {
+			select {
+			case <-w.Done():
+				t.Fatal("done while sweeping")
+			case <-time.After(time.Millisecond):
+			}
+		}
+	}
+	// Only the group shares a goroutine between its calls to Done.
+	if n := runtime.NumGoroutine(); n > before+1 {
+		t.Errorf("goroutines leaked, got: %d, want: at most %d", n, before+1)
+	}
+
+	s.Stop()
+	for _, w := range []Waiter{s, g} {
+		select {
+		case <-w.Done():
+		case <-time.After(time.Second):
+			t.Fatal("not done after the servo stopped")
+		}
+	}
+}
+
+func TestWaiter_Done(t *testing.T) {
+	useBlaster(t)
+
+	a, b := New(98), New(99)
+	for _, s := range []*Servo{a, b} {
+		if err := s.Connect(); err != nil {
+			t.Fatal(err)
+		}
+		defer s.Close()
+	}
+
+	waiters := map[string]Waiter{
+		"batch": Batch(func(tx *Tx) {
+			tx.MoveTo(a, 45)
+			tx.MoveTo(b, 45)
+		}),
+		"scheduled": a.MoveAt(Now().Add(10*time.Millisecond), 90),
+		"closed":    SoftStopAll(0),
+	}
+	for name, w := range waiters {
+		select {
+		case <-w.Done():
+		case <-time.After(time.Second):
+			t.Errorf("%s: not done", name)
+		}
+	}
+}
+
+func TestServo_MoveToContext(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetSpeed(0.5)
+
+	if err := s.MoveToContext(context.Background(), 30); err != nil {
+		t.Fatal(err)
+	}
+	if s.Position() != 30 {
+		t.Errorf("servo did not finish, got: %.2f, want: 30.00", s.Position())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := s.MoveToContext(ctx, 180); err != context.DeadlineExceeded {
+		t.Errorf("wrong error, got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+	if !s.isIdle() {
+		t.Error("servo kept moving after the context was done")
+	}
+	if p := s.Position(); p <= 30 || p >= 180 {
+		t.Errorf("servo did not stop halfway, got: %.2f", p)
+	}
+
+	// A done context does not move the servo.
+	from := s.Position()
+	if err := s.MoveToContext(ctx, 0); err != context.DeadlineExceeded {
+		t.Errorf("wrong error, got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+	if s.Position() != from {
+		t.Errorf("servo moved with a done context, got: %.2f, want: %.2f", s.Position(), from)
+	}
+}
+
+func TestServo_WaitContext(t *testing.T) {
+	useBlaster(t)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetSpeed(0.5)
+
+	s.MoveTo(180)
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := s.WaitContext(ctx); err != context.DeadlineExceeded {
+		t.Errorf("wrong error, got: %v, want: %v", err, context.DeadlineExceeded)
+	}
+	if s.isIdle() {
+		t.Error("WaitContext stopped the servo")
+	}
+
+	if err := s.WaitContext(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if s.Position() != 180 {
+		t.Errorf("servo did not finish, got: %.2f, want: 180.00", s.Position())
+	}
+}
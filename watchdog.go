@@ -0,0 +1,103 @@
+package servo
+
+import (
+	"sync"
+	"time"
+)
+
+// WatchdogAction is what a Watchdog does with its servo when it trips.
+type WatchdogAction int
+
+const (
+	// WatchdogHold stops the servo where it is, e.g. for a gripper that must
+	// not drop what it holds.
+	WatchdogHold WatchdogAction = iota
+	// WatchdogPose moves the servo to the safe pose at the safe speed, e.g.
+	// for a lifting arm that should lower slowly.
+	WatchdogPose
+	// WatchdogRelease stops the servo and releases its pin (0 duty), e.g. for
+	// a prop that can simply go limp. The servo is driven again on its next
+	// move.
+	WatchdogRelease
+)
+
+// WatchdogConfig is the configuration of a Watchdog.
+type WatchdogConfig struct {
+	// Timeout is the time without Feed() after which the watchdog trips.
+	Timeout time.Duration
+	// Action is what the watchdog does when it trips.
+	Action WatchdogAction
+	// Pose is the safe angle of WatchdogPose, adjusted for the Flags of the
+	// servo.
+	Pose float64
+	// Speed is the safe speed of WatchdogPose, from 0.0 to 1.0.
+	Speed float64
+}
+
+// Watchdog puts a servo in a safe state when it is not fed in time, e.g. when
+// the client controlling it goes away. Each servo can have its own watchdog
+// with its own timeout and action. Use Servo.Watchdog() for correct
+// initialization.
+type Watchdog struct {
+	servo  *Servo
+	config WatchdogConfig
+	timer  *time.Timer
+	lock   *sync.Mutex
+}
+
+// Watchdog starts a watchdog for the servo. Call Feed() before the timeout to
+// keep the servo under control, and Stop() to disarm the watchdog.
+func (s *Servo) Watchdog(config WatchdogConfig) *Watchdog {
+	w := &Watchdog{
+		servo:  s,
+		config: config,
+		lock:   new(sync.Mutex),
+	}
+	w.timer = time.AfterFunc(config.Timeout, w.trip)
+
+	return w
+}
+
+// Feed restarts the timeout of the watchdog. It also rearms a watchdog that
+// already tripped.
+func (w *Watchdog) Feed() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.timer == nil {
+		return
+	}
+	w.timer.Stop()
+	w.timer.Reset(w.config.Timeout)
+}
+
+// Stop disarms the watchdog.
+func (w *Watchdog) Stop() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.timer != nil {
+		w.timer.Stop()
+		w.timer = nil
+	}
+}
+
+// trip puts the servo in the safe state of the watchdog.
+func (w *Watchdog) trip() {
+	s := w.servo
+
+	s.lock.Lock()
+	s.counters.WatchdogTrips++
+	s.lock.Unlock()
+	debugf("%v watchdog tripped", s)
+
+	switch w.config.Action {
+	case WatchdogHold:
+		s.Stop()
+	case WatchdogPose:
+		s.SetSpeed(w.config.Speed)
+		s.MoveTo(w.config.Pose)
+	case WatchdogRelease:
+		s.detach(false)
+	}
+}
@@ -0,0 +1,74 @@
+// +build !live
+
+package servo
+
+import (
+	"testing"
+	"time"
+)
+
+func TestServo_Watchdog(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetPosition(90)
+
+	w := s.Watchdog(WatchdogConfig{
+		Timeout: 100 * time.Millisecond,
+		Action:  WatchdogPose,
+		Pose:    80,
+		Speed:   1,
+	})
+	defer w.Stop()
+
+	for i := 0; i < 4; i++ {
+		time.Sleep(50 * time.Millisecond)
+		w.Feed()
+	}
+	if s.Counters().WatchdogTrips != 0 || s.Position() != 90 {
+		t.Fatal("fed watchdog tripped")
+	}
+
+	time.Sleep(150 * time.Millisecond)
+	s.Wait()
+	if s.Counters().WatchdogTrips != 1 {
+		t.Errorf("watchdog did not trip")
+	}
+	if s.Position() != 80 {
+		t.Errorf("servo did not move to the safe pose, got: %.2f, want: %.2f", s.Position(), 80.0)
+	}
+}
+
+func TestServo_WatchdogRelease(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetPosition(90)
+
+	w := s.Watchdog(WatchdogConfig{
+		Timeout: 50 * time.Millisecond,
+		Action:  WatchdogRelease,
+	})
+	defer w.Stop()
+
+	time.Sleep(150 * time.Millisecond)
+	if got, _ := s.LastPWM(); got != 0 {
+		t.Errorf("pin was not released, got: %.4f, want: %.4f", got, 0.0)
+	}
+
+	s.MoveTo(100).Wait()
+	time.Sleep(50 * time.Millisecond)
+	if got, _ := s.LastPWM(); got == 0 {
+		t.Error("released servo was not driven again")
+	}
+}
@@ -0,0 +1,80 @@
+package servo
+
+import (
+	"math"
+	"time"
+)
+
+// MotionFunc adapts a function of the time since the layer was added to a
+// Motion, e.g. a custom waveform:
+//
+//	s.AddLayer(servo.MotionFunc(func(t time.Duration) float64 {
+//		return 10 * math.Sin(t.Seconds())
+//	}))
+type MotionFunc func(t time.Duration) float64
+
+// Offset implements the Motion interface.
+func (f MotionFunc) Offset(t time.Duration) float64 {
+	return f(t)
+}
+
+// Waveform is the shape of a Wave.
+type Waveform int
+
+const (
+	// SineWave oscillates smoothly.
+	SineWave Waveform = iota
+	// TriangleWave moves at a constant speed and turns back at the peaks.
+	TriangleWave
+	// SquareWave jumps between the peaks every half period. The jumps are
+	// limited by the speed of the servo.
+	SquareWave
+	// SawtoothWave moves at a constant speed from the low peak to the high
+	// peak, and jumps back.
+	SawtoothWave
+)
+
+// Wave is a periodic Motion, e.g. for breathing or tail-wagging effects. Layer
+// it on a servo with Servo.AddLayer() to drive the servo continuously by the
+// manager, without a goroutine.
+type Wave struct {
+	// Shape is the waveform of the wave (default: SineWave).
+	Shape Waveform
+	// Amplitude is the maximum offset in degrees, on both sides of the
+	// position of the servo.
+	Amplitude float64
+	// Period is the time of a full cycle of the wave. If 0, the offset is
+	// always 0.
+	Period time.Duration
+	// Phase shifts the wave forward in time.
+	Phase time.Duration
+}
+
+// Offset implements the Motion interface.
+func (w Wave) Offset(t time.Duration) float64 {
+	if w.Period <= 0 {
+		return 0
+	}
+	// u is the fraction of the current cycle, from 0 to 1.
+	u := math.Mod((t+w.Phase).Seconds()/w.Period.Seconds(), 1)
+	if u < 0 {
+		u++
+	}
+
+	var y float64
+	switch w.Shape {
+	case TriangleWave:
+		// Start at 0 and rise, like the sine.
+		y = math.Abs(4*math.Mod(u+0.75, 1)-2) - 1
+	case SquareWave:
+		y = 1
+		if u >= 0.5 {
+			y = -1
+		}
+	case SawtoothWave:
+		y = 2*math.Mod(u+0.5, 1) - 1
+	default:
+		y = math.Sin(2 * math.Pi * u)
+	}
+	return w.Amplitude * y
+}
@@ -0,0 +1,63 @@
+// +build !live
+
+package servo
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestWave(t *testing.T) {
+	const period = 4 * time.Second
+	tests := []struct {
+		shape Waveform
+		want  []float64
+	}{
+		// Offsets every quarter of the period, from 0 to 1¼ periods.
+		{SineWave, []float64{0, 10, 0, -10, 0, 10}},
+		{TriangleWave, []float64{0, 10, 0, -10, 0, 10}},
+		{SquareWave, []float64{10, 10, -10, -10, 10, 10}},
+		{SawtoothWave, []float64{0, 5, -10, -5, 0, 5}},
+	}
+	for _, tt := range tests {
+		w := Wave{Shape: tt.shape, Amplitude: 10, Period: period}
+		for i, want := range tt.want {
+			at := time.Duration(i) * period / 4
+			if got := w.Offset(at); math.Abs(got-want) > 1e-9 {
+				t.Errorf("shape %d: wrong offset at %v, got: %.2f, want: %.2f", tt.shape, at, got, want)
+			}
+		}
+	}
+
+	shifted := Wave{Amplitude: 10, Period: period, Phase: time.Second}
+	if got := shifted.Offset(0); math.Abs(got-10) > 1e-9 {
+		t.Errorf("wrong offset with phase, got: %.2f, want: 10.00", got)
+	}
+	if got := (Wave{Amplitude: 10}).Offset(time.Second); got != 0 {
+		t.Errorf("wrong offset without period, got: %.2f, want: 0.00", got)
+	}
+}
+
+func TestMotionFunc(t *testing.T) {
+	useBlaster(t)
+	Rate(time.Millisecond)
+
+	s := New(99)
+	if err := s.Connect(); err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+	s.SetPosition(90)
+	s.Wait()
+
+	s.AddLayer(MotionFunc(func(t time.Duration) float64 {
+		return -10
+	}))
+	time.Sleep(200 * time.Millisecond)
+
+	want := pwm(remap(80, 0, 180, s.MinPulse, s.MaxPulse))
+	if got, _ := s.LastPWM(); pwm(got) != want {
+		t.Errorf("function was not applied, got: %.4f, want: %.4f", got, want)
+	}
+}